@@ -0,0 +1,134 @@
+// Package analysis prépare les mots pour la recherche : découpage d'un texte,
+// lemmes des formes irrégulières, mots vides et racinisation à la manière de
+// Snowball pour le français et l'anglais. Un mot et ses formes fléchies
+// (« langages », « chevaux », « children ») donnent le même terme.
+package analysis
+
+import (
+	"sort"
+	"strings"
+	"tp2/interfaces"
+	"unicode"
+)
+
+// stemmers sont les raciniseurs disponibles ; les autres langues ne sont pas racinisées.
+var stemmers = map[string]func(string) string{
+	"fr": stemFrench,
+	"en": stemEnglish,
+}
+
+// Supported indique si la langue a un raciniseur.
+func Supported(lang string) bool {
+	_, ok := stemmers[lang]
+	return ok
+}
+
+// Tokens découpe un texte en mots en minuscules, séparés par tout ce qui n'est
+// ni une lettre ni un chiffre : « l'arc-en-ciel » donne l, arc, en et ciel.
+func Tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(interfaces.NormalizeWord(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+// Stem renvoie la racine d'un mot en minuscules dans la langue lang.
+func Stem(lang, word string) string {
+	stem, ok := stemmers[lang]
+	if !ok {
+		return word
+	}
+	return stem(strings.NewReplacer("œ", "oe", "æ", "ae").Replace(word))
+}
+
+// Term renvoie le terme d'index d'un mot en minuscules : la racine de son
+// lemme, sans accents ni casse (voir interfaces.WordKey).
+func Term(lang, token string) string {
+	if lemma, ok := Lemma(lang, token); ok {
+		token = lemma
+	}
+	return interfaces.WordKey(Stem(lang, token))
+}
+
+// Terms renvoie les termes d'un texte, sans ses mots vides ni doublons.
+// Chaque mot n'est analysé qu'une fois, même dans une longue définition.
+func Terms(lang, text string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	analyzed := make(map[string]bool)
+	for _, token := range Tokens(text) {
+		if analyzed[token] {
+			continue
+		}
+		analyzed[token] = true
+		if IsStopWord(lang, token) {
+			continue
+		}
+		if term := Term(lang, token); !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// IsStopWord indique si le mot est trop courant pour être indexé dans une définition.
+func IsStopWord(lang, token string) bool {
+	return stopWords[lang][interfaces.WordKey(token)]
+}
+
+var stopWords = map[string]map[string]bool{
+	"fr": set("le", "la", "les", "l", "un", "une", "des", "du", "de", "d", "et", "ou", "a", "au", "aux",
+		"en", "dans", "par", "pour", "sur", "avec", "sans", "que", "qui", "qu", "ne", "pas", "ce", "cet",
+		"cette", "ces", "son", "sa", "ses", "leur", "leurs", "se", "s", "est", "il", "elle", "on", "y"),
+	"en": set("the", "a", "an", "and", "or", "of", "to", "in", "on", "for", "with", "by", "at", "from",
+		"as", "is", "are", "be", "that", "this", "it", "its", "s"),
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+// suffixRule remplace suffix par replacement.
+type suffixRule struct {
+	suffix, replacement string
+}
+
+// sortedSuffixes range les règles du suffixe le plus long au plus court.
+func sortedSuffixes(rules map[string]string) []suffixRule {
+	sorted := make([]suffixRule, 0, len(rules))
+	for suffix, replacement := range rules {
+		sorted = append(sorted, suffixRule{suffix, replacement})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].suffix) != len(sorted[j].suffix) {
+			return len(sorted[i].suffix) > len(sorted[j].suffix)
+		}
+		return sorted[i].suffix < sorted[j].suffix
+	})
+	return sorted
+}
+
+// findSuffix renvoie la règle du plus long suffixe de word.
+func findSuffix(word string, rules []suffixRule) (string, string, bool) {
+	for _, rule := range rules {
+		if strings.HasSuffix(word, rule.suffix) {
+			return rule.suffix, rule.replacement, true
+		}
+	}
+	return "", "", false
+}
+
+// longestSuffix renvoie le plus long des suffixes de word, ou "".
+func longestSuffix(word string, suffixes []string) string {
+	longest := ""
+	for _, suffix := range suffixes {
+		if len(suffix) > len(longest) && strings.HasSuffix(word, suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
@@ -0,0 +1,201 @@
+package analysis
+
+import "strings"
+
+// Raciniseur anglais : algorithme Porter2 de Snowball
+// (https://snowballstem.org/algorithms/english/stemmer.html), sans l'étape 0
+// puisque les apostrophes séparent déjà les mots.
+
+// englishExceptions sont les mots dont la racine ne suit pas l'algorithme.
+var englishExceptions = map[string]string{
+	"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie", "tying": "tie",
+	"idly": "idl", "gently": "gentl", "ugly": "ugli", "early": "earli", "only": "onli", "singly": "singl",
+	"sky": "sky", "news": "news", "howe": "howe", "atlas": "atlas", "cosmos": "cosmos", "bias": "bias", "andes": "andes",
+}
+
+// englishInvariants ne sont plus modifiés après l'étape 1a.
+var englishInvariants = map[string]bool{
+	"inning": true, "outing": true, "canning": true, "herring": true,
+	"earring": true, "proceed": true, "exceed": true, "succeed": true,
+}
+
+var (
+	englishStep1b = []string{"eedly", "ingly", "edly", "eed", "ing", "ed"}
+	englishStep2  = sortedSuffixes(map[string]string{
+		"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able", "entli": "ent",
+		"izer": "ize", "ization": "ize", "ational": "ate", "ation": "ate", "ator": "ate",
+		"alism": "al", "aliti": "al", "alli": "al", "fulness": "ful", "ousli": "ous", "ousness": "ous",
+		"iveness": "ive", "iviti": "ive", "biliti": "ble", "bli": "ble", "ogi": "og",
+		"fulli": "ful", "lessli": "less", "li": "",
+	})
+	englishStep3 = sortedSuffixes(map[string]string{
+		"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic", "iciti": "ic",
+		"ical": "ic", "ful": "", "ness": "", "ative": "",
+	})
+	englishStep4 = []string{
+		"ement", "ance", "ence", "able", "ible", "ment",
+		"ant", "ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+		"al", "er", "ic",
+	}
+)
+
+func isEnglishVowel(c byte) bool {
+	return strings.IndexByte("aeiouy", c) >= 0
+}
+
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	if stem, ok := englishExceptions[word]; ok {
+		return stem
+	}
+
+	// Un y initial ou qui suit une voyelle est une consonne, notée Y.
+	b := []byte(word)
+	for i := range b {
+		if b[i] == 'y' && (i == 0 || isEnglishVowel(b[i-1])) {
+			b[i] = 'Y'
+		}
+	}
+	r1, r2 := englishRegions(b)
+
+	b = englishStep1a(b)
+	if englishInvariants[string(b)] {
+		return strings.ReplaceAll(string(b), "Y", "y")
+	}
+	b = englishStep1bc(b, r1)
+
+	if suffix, replacement, ok := findSuffix(string(b), englishStep2); ok && len(b)-len(suffix) >= r1 {
+		stem := b[:len(b)-len(suffix)]
+		switch {
+		case suffix == "ogi" && !hasSuffix(stem, "l"):
+		case suffix == "li" && (len(stem) == 0 || strings.IndexByte("cdeghkmnrt", stem[len(stem)-1]) < 0):
+		default:
+			b = append(stem, replacement...)
+		}
+	}
+
+	if suffix, replacement, ok := findSuffix(string(b), englishStep3); ok && len(b)-len(suffix) >= r1 {
+		if suffix != "ative" || len(b)-len(suffix) >= r2 {
+			b = append(b[:len(b)-len(suffix)], replacement...)
+		}
+	}
+
+	if suffix := longestSuffix(string(b), englishStep4); suffix != "" && len(b)-len(suffix) >= r2 {
+		stem := b[:len(b)-len(suffix)]
+		if suffix != "ion" || hasSuffix(stem, "s") || hasSuffix(stem, "t") {
+			b = stem
+		}
+	}
+
+	switch n := len(b); {
+	case hasSuffix(b, "e") && (n-1 >= r2 || (n-1 >= r1 && !endsShortSyllable(b[:n-1]))):
+		b = b[:n-1]
+	case hasSuffix(b, "ll") && n-1 >= r2:
+		b = b[:n-1]
+	}
+	return strings.ReplaceAll(string(b), "Y", "y")
+}
+
+// englishRegions renvoie le début de R1 et R2 : R1 suit la première consonne
+// précédée d'une voyelle, R2 fait de même à partir de R1.
+func englishRegions(b []byte) (int, int) {
+	r1 := -1
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(b), prefix) {
+			r1 = len(prefix)
+		}
+	}
+	if r1 < 0 {
+		r1 = englishRegionAfter(b, 0)
+	}
+	return r1, englishRegionAfter(b, r1)
+}
+
+func englishRegionAfter(b []byte, start int) int {
+	for i := start + 1; i < len(b); i++ {
+		if !isEnglishVowel(b[i]) && isEnglishVowel(b[i-1]) {
+			return i + 1
+		}
+	}
+	return len(b)
+}
+
+func englishStep1a(b []byte) []byte {
+	n := len(b)
+	switch {
+	case hasSuffix(b, "sses"):
+		return b[:n-2]
+	case hasSuffix(b, "ied"), hasSuffix(b, "ies"):
+		if n > 4 {
+			return b[:n-2]
+		}
+		return b[:n-1]
+	case hasSuffix(b, "us"), hasSuffix(b, "ss"):
+		return b
+	case hasSuffix(b, "s") && containsEnglishVowel(b[:n-2]):
+		return b[:n-1]
+	}
+	return b
+}
+
+func englishStep1bc(b []byte, r1 int) []byte {
+	switch suffix := longestSuffix(string(b), englishStep1b); suffix {
+	case "":
+	case "eed", "eedly":
+		if len(b)-len(suffix) >= r1 {
+			b = append(b[:len(b)-len(suffix)], "ee"...)
+		}
+	default:
+		stem := b[:len(b)-len(suffix)]
+		if !containsEnglishVowel(stem) {
+			break
+		}
+		b = stem
+		switch {
+		case hasSuffix(b, "at"), hasSuffix(b, "bl"), hasSuffix(b, "iz"):
+			b = append(b, 'e')
+		case isEnglishDouble(b):
+			b = b[:len(b)-1]
+		case len(b) <= r1 && endsShortSyllable(b):
+			b = append(b, 'e')
+		}
+	}
+
+	// Étape 1c : y final précédé d'une consonne qui n'ouvre pas le mot.
+	if n := len(b); n > 2 && (b[n-1] == 'y' || b[n-1] == 'Y') && !isEnglishVowel(b[n-2]) {
+		b[n-1] = 'i'
+	}
+	return b
+}
+
+func containsEnglishVowel(b []byte) bool {
+	for _, c := range b {
+		if isEnglishVowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEnglishDouble(b []byte) bool {
+	n := len(b)
+	return n >= 2 && b[n-1] == b[n-2] && strings.IndexByte("bdfgmnprt", b[n-1]) >= 0
+}
+
+// endsShortSyllable indique si le mot finit par une syllabe courte : consonne,
+// voyelle, consonne autre que w, x ou Y, ou voyelle initiale suivie d'une consonne.
+func endsShortSyllable(b []byte) bool {
+	switch n := len(b); {
+	case n == 2:
+		return isEnglishVowel(b[0]) && !isEnglishVowel(b[1])
+	case n >= 3:
+		return !isEnglishVowel(b[n-3]) && isEnglishVowel(b[n-2]) && !isEnglishVowel(b[n-1]) && strings.IndexByte("wxY", b[n-1]) < 0
+	}
+	return false
+}
+
+func hasSuffix(b []byte, suffix string) bool {
+	return strings.HasSuffix(string(b), suffix)
+}
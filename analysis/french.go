@@ -0,0 +1,382 @@
+package analysis
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Raciniseur français : algorithme de Snowball
+// (https://snowballstem.org/algorithms/french/stemmer.html). Les voyelles
+// employées comme consonnes sont notées en majuscules (I, U, Y) le temps du calcul.
+
+const frenchVowels = "aeiouyâàëéêèïîôûù"
+
+var (
+	frenchStep1 = []string{
+		"ance", "iqUe", "isme", "able", "iste", "eux", "ances", "iqUes", "ismes", "ables", "istes",
+		"atrice", "ateur", "ation", "atrices", "ateurs", "ations",
+		"logie", "logies", "usion", "ution", "usions", "utions", "ence", "ences",
+		"ement", "ements", "ité", "ités", "if", "ive", "ifs", "ives",
+		"eaux", "aux", "euse", "euses", "issement", "issements",
+		"amment", "emment", "ment", "ments",
+	}
+	frenchStep2a = []string{
+		"îmes", "ît", "îtes", "i", "ie", "ies", "ir", "ira", "irai", "iraIent", "irais", "irait",
+		"iras", "irent", "irez", "iriez", "irions", "irons", "iront", "is", "issaIent", "issais",
+		"issait", "issant", "issante", "issantes", "issants", "isse", "issent", "isses", "issez",
+		"issiez", "issions", "issons", "it",
+	}
+	frenchStep2b = []string{
+		"ions",
+		"é", "ée", "ées", "és", "èrent", "er", "era", "erai", "eraIent", "erais", "erait",
+		"eras", "erez", "eriez", "erions", "erons", "eront", "ez", "iez",
+		"âmes", "ât", "âtes", "a", "ai", "aIent", "ais", "ait", "ant", "ante", "antes", "ants",
+		"as", "asse", "assent", "asses", "assiez", "assions",
+	}
+	frenchStep4 = []string{"ion", "ier", "ière", "Ier", "Ière", "e", "ë"}
+)
+
+func isFrenchVowel(r rune) bool {
+	return strings.ContainsRune(frenchVowels, r)
+}
+
+// frenchStemmer garde le mot en cours de traitement et le début, en octets,
+// de ses régions RV, R1 et R2.
+type frenchStemmer struct {
+	word       string
+	rv, r1, r2 int
+}
+
+func stemFrench(word string) string {
+	s := &frenchStemmer{word: markFrenchConsonants(word)}
+	s.markRegions()
+
+	altered := false
+	step1, continueStep2 := s.step1()
+	if !step1 || continueStep2 {
+		altered = s.step2a() || s.step2b()
+	}
+	if step1 && !continueStep2 {
+		altered = true
+	}
+
+	if altered {
+		// Étape 3.
+		switch {
+		case strings.HasSuffix(s.word, "Y"):
+			s.replace("Y", "i")
+		case strings.HasSuffix(s.word, "ç"):
+			s.replace("ç", "c")
+		}
+	} else {
+		s.step4()
+	}
+	s.undouble()
+	s.unaccent()
+
+	return strings.NewReplacer("I", "i", "U", "u", "Y", "y").Replace(s.word)
+}
+
+// markFrenchConsonants note en majuscules les u et i entre deux voyelles, les
+// y voisins d'une voyelle et les u qui suivent un q.
+func markFrenchConsonants(word string) string {
+	runes := []rune(word)
+	for i, r := range runes {
+		previousVowel := i > 0 && isFrenchVowel(runes[i-1])
+		nextVowel := i+1 < len(runes) && isFrenchVowel(runes[i+1])
+		switch {
+		case (r == 'u' || r == 'i') && previousVowel && nextVowel:
+			runes[i] = r - 'a' + 'A'
+		case r == 'y' && (previousVowel || nextVowel):
+			runes[i] = 'Y'
+		case r == 'u' && i > 0 && runes[i-1] == 'q':
+			runes[i] = 'U'
+		}
+	}
+	return string(runes)
+}
+
+func (s *frenchStemmer) markRegions() {
+	w := s.word
+	s.rv = len(w)
+	first, size := utf8.DecodeRuneInString(w)
+	second, size2 := utf8.DecodeRuneInString(w[size:])
+	switch {
+	case strings.HasPrefix(w, "par"), strings.HasPrefix(w, "col"), strings.HasPrefix(w, "tap"):
+		s.rv = 3
+	case isFrenchVowel(first) && isFrenchVowel(second):
+		_, size3 := utf8.DecodeRuneInString(w[size+size2:])
+		s.rv = size + size2 + size3
+	default:
+		for i, r := range w[size:] {
+			if isFrenchVowel(r) {
+				s.rv = size + i + utf8.RuneLen(r)
+				break
+			}
+		}
+	}
+	s.r1 = frenchRegionAfter(w, 0)
+	s.r2 = frenchRegionAfter(w, s.r1)
+}
+
+// frenchRegionAfter renvoie la position qui suit la première consonne
+// précédée d'une voyelle à partir de start.
+func frenchRegionAfter(w string, start int) int {
+	previousVowel := false
+	for i, r := range w[start:] {
+		if !isFrenchVowel(r) && previousVowel {
+			return start + i + utf8.RuneLen(r)
+		}
+		previousVowel = isFrenchVowel(r)
+	}
+	return len(w)
+}
+
+// from renvoie la position du suffixe dans le mot.
+func (s *frenchStemmer) from(suffix string) int {
+	return len(s.word) - len(suffix)
+}
+
+func (s *frenchStemmer) ends(suffix string) bool {
+	return strings.HasSuffix(s.word, suffix)
+}
+
+func (s *frenchStemmer) replace(suffix, replacement string) {
+	s.word = s.word[:s.from(suffix)] + replacement
+}
+
+// precededBy indique si la lettre qui précède suffix commence après limit et
+// est une voyelle (vowel vrai) ou une consonne.
+func (s *frenchStemmer) precededBy(suffix string, limit int, vowel bool) bool {
+	stem := s.word[:s.from(suffix)]
+	r, size := utf8.DecodeLastRuneInString(stem)
+	return size > 0 && len(stem)-size >= limit && isFrenchVowel(r) == vowel
+}
+
+// step1 retire les suffixes courants. Il renvoie vrai s'il a modifié le mot, et
+// continueStep2 si le suffixe trouvé appelle tout de même l'étape 2.
+func (s *frenchStemmer) step1() (altered, continueStep2 bool) {
+	suffix := longestSuffix(s.word, frenchStep1)
+	if suffix == "" {
+		return false, false
+	}
+	at := s.from(suffix)
+	inR2, inR1, inRV := at >= s.r2, at >= s.r1, at >= s.rv
+
+	switch suffix {
+	case "ance", "iqUe", "isme", "able", "iste", "eux", "ances", "iqUes", "ismes", "ables", "istes":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "")
+	case "atrice", "ateur", "ation", "atrices", "ateurs", "ations":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "")
+		if s.ends("ic") {
+			s.deleteOr("ic", s.r2, "iqU")
+		}
+	case "logie", "logies":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "log")
+	case "usion", "ution", "usions", "utions":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "u")
+	case "ence", "ences":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "ent")
+	case "ement", "ements":
+		if !inRV {
+			return false, false
+		}
+		s.replace(suffix, "")
+		switch {
+		case s.ends("iv") && s.from("iv") >= s.r2:
+			s.replace("iv", "")
+			if s.ends("at") && s.from("at") >= s.r2 {
+				s.replace("at", "")
+			}
+		case s.ends("eus"):
+			if s.from("eus") >= s.r2 {
+				s.replace("eus", "")
+			} else if s.from("eus") >= s.r1 {
+				s.replace("eus", "eux")
+			}
+		case s.ends("abl") && s.from("abl") >= s.r2:
+			s.replace("abl", "")
+		case s.ends("iqU") && s.from("iqU") >= s.r2:
+			s.replace("iqU", "")
+		case s.ends("ièr") && s.from("ièr") >= s.rv:
+			s.replace("ièr", "i")
+		case s.ends("Ièr") && s.from("Ièr") >= s.rv:
+			s.replace("Ièr", "i")
+		}
+	case "ité", "ités":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "")
+		switch {
+		case s.ends("abil"):
+			s.deleteOr("abil", s.r2, "abl")
+		case s.ends("ic"):
+			s.deleteOr("ic", s.r2, "iqU")
+		case s.ends("iv") && s.from("iv") >= s.r2:
+			s.replace("iv", "")
+		}
+	case "if", "ive", "ifs", "ives":
+		if !inR2 {
+			return false, false
+		}
+		s.replace(suffix, "")
+		if s.ends("at") && s.from("at") >= s.r2 {
+			s.replace("at", "")
+			if s.ends("ic") {
+				s.deleteOr("ic", s.r2, "iqU")
+			}
+		}
+	case "eaux":
+		s.replace(suffix, "eau")
+	case "aux":
+		if !inR1 {
+			return false, false
+		}
+		s.replace(suffix, "al")
+	case "euse", "euses":
+		switch {
+		case inR2:
+			s.replace(suffix, "")
+		case inR1:
+			s.replace(suffix, "eux")
+		default:
+			return false, false
+		}
+	case "issement", "issements":
+		if !inR1 || !s.precededBy(suffix, 0, false) {
+			return false, false
+		}
+		s.replace(suffix, "")
+	case "amment":
+		if !inRV {
+			return false, false
+		}
+		s.replace(suffix, "ant")
+		return true, true
+	case "emment":
+		if !inRV {
+			return false, false
+		}
+		s.replace(suffix, "ent")
+		return true, true
+	case "ment", "ments":
+		if !inRV || !s.precededBy(suffix, s.rv, true) {
+			return false, false
+		}
+		s.replace(suffix, "")
+		return true, true
+	}
+	return true, false
+}
+
+// deleteOr supprime suffix s'il commence après limit, sinon le remplace par replacement.
+func (s *frenchStemmer) deleteOr(suffix string, limit int, replacement string) {
+	if s.from(suffix) >= limit {
+		s.replace(suffix, "")
+	} else {
+		s.replace(suffix, replacement)
+	}
+}
+
+// rvSuffix renvoie le plus long des suffixes contenu dans RV, vide si le mot
+// raccourci s'arrête avant RV.
+func (s *frenchStemmer) rvSuffix(suffixes []string) string {
+	return longestSuffix(s.word[min(s.rv, len(s.word)):], suffixes)
+}
+
+// step2a retire les terminaisons verbales en i précédées d'une consonne.
+func (s *frenchStemmer) step2a() bool {
+	suffix := s.rvSuffix(frenchStep2a)
+	if suffix == "" || !s.precededBy(suffix, s.rv, false) {
+		return false
+	}
+	s.replace(suffix, "")
+	return true
+}
+
+// step2b retire les autres terminaisons verbales.
+func (s *frenchStemmer) step2b() bool {
+	suffix := s.rvSuffix(frenchStep2b)
+	switch suffix {
+	case "":
+		return false
+	case "ions":
+		if s.from(suffix) < s.r2 {
+			return false
+		}
+		s.replace(suffix, "")
+	case "âmes", "ât", "âtes", "a", "ai", "aIent", "ais", "ait", "ant", "ante", "antes", "ants",
+		"as", "asse", "assent", "asses", "assiez", "assions":
+		s.replace(suffix, "")
+		if s.ends("e") && s.from("e") >= s.rv {
+			s.replace("e", "")
+		}
+	default:
+		s.replace(suffix, "")
+	}
+	return true
+}
+
+// step4 retire un s final puis les terminaisons résiduelles de RV.
+func (s *frenchStemmer) step4() {
+	if s.ends("s") {
+		if r, _ := utf8.DecodeLastRuneInString(s.word[:s.from("s")]); r != utf8.RuneError && !strings.ContainsRune("aiouès", r) {
+			s.replace("s", "")
+		}
+	}
+	switch suffix := s.rvSuffix(frenchStep4); suffix {
+	case "ion":
+		if s.from(suffix) >= s.r2 && s.from(suffix) > s.rv && (s.ends("sion") || s.ends("tion")) {
+			s.replace(suffix, "")
+		}
+	case "ier", "ière", "Ier", "Ière":
+		s.replace(suffix, "i")
+	case "e":
+		s.replace(suffix, "")
+	case "ë":
+		if s.ends("guë") {
+			s.replace(suffix, "")
+		}
+	}
+}
+
+// undouble retire la dernière lettre des finales enn, onn, ett, ell et eill.
+func (s *frenchStemmer) undouble() {
+	for _, ending := range []string{"enn", "onn", "ett", "ell", "eill"} {
+		if s.ends(ending) {
+			s.word = s.word[:len(s.word)-1]
+			return
+		}
+	}
+}
+
+// unaccent retire l'accent d'un é ou d'un è suivi d'au moins une consonne finale.
+func (s *frenchStemmer) unaccent() {
+	end := len(s.word)
+	for end > 0 {
+		r, size := utf8.DecodeLastRuneInString(s.word[:end])
+		if isFrenchVowel(r) {
+			if end < len(s.word) && (r == 'é' || r == 'è') {
+				s.word = s.word[:end-size] + "e" + s.word[end:]
+			}
+			return
+		}
+		end -= size
+	}
+}
@@ -0,0 +1,105 @@
+package analysis
+
+import "tp2/interfaces"
+
+// lemmaForms associe à chaque lemme ses formes irrégulières, que la
+// racinisation ne sait pas ramener au lemme.
+var lemmaForms = map[string]map[string][]string{
+	"fr": {
+		"œil":          {"yeux"},
+		"ciel":         {"cieux"},
+		"aïeul":        {"aïeux"},
+		"travail":      {"travaux"},
+		"vitrail":      {"vitraux"},
+		"corail":       {"coraux"},
+		"émail":        {"émaux"},
+		"bail":         {"baux"},
+		"bijou":        {"bijoux"},
+		"caillou":      {"cailloux"},
+		"chou":         {"choux"},
+		"genou":        {"genoux"},
+		"hibou":        {"hiboux"},
+		"joujou":       {"joujoux"},
+		"pou":          {"poux"},
+		"monsieur":     {"messieurs"},
+		"madame":       {"mesdames"},
+		"mademoiselle": {"mesdemoiselles"},
+		"beau":         {"bel", "belle", "belles"},
+		"nouveau":      {"nouvel", "nouvelle", "nouvelles"},
+		"vieux":        {"vieil", "vieille", "vieilles"},
+		"fou":          {"fol", "folle", "folles"},
+		"être":         {"suis", "es", "est", "sommes", "êtes", "sont", "été", "étais", "était", "étaient", "sera", "seront", "fut", "soit"},
+		"avoir":        {"ai", "as", "avons", "avez", "ont", "eu", "eue", "eus", "avait", "avaient", "aura", "auront", "eut"},
+		"aller":        {"vais", "vas", "va", "vont", "irai", "ira", "iront", "aille"},
+		"faire":        {"fais", "fait", "faits", "faite", "faites", "font", "fera", "feront", "fit"},
+		"dire":         {"dis", "dit", "dits", "dite", "dites", "disent"},
+		"voir":         {"vois", "voit", "voient", "vu", "vus", "vue", "vues", "verra", "vit"},
+		"pouvoir":      {"peux", "peut", "pouvons", "pouvez", "peuvent", "pu", "pourra", "put"},
+		"vouloir":      {"veux", "veut", "voulons", "voulez", "veulent", "voulu", "voudra"},
+		"savoir":       {"sais", "sait", "savons", "savez", "savent", "su", "saura", "sut"},
+		"prendre":      {"prends", "prend", "prenons", "prenez", "prennent", "pris", "prise", "prises"},
+		"mettre":       {"mets", "met", "mettons", "mettez", "mettent", "mis", "mise", "mises"},
+		"venir":        {"viens", "vient", "venons", "venez", "viennent", "venu", "venue", "venus", "viendra"},
+	},
+	"en": {
+		"man":        {"men"},
+		"woman":      {"women"},
+		"child":      {"children"},
+		"person":     {"people"},
+		"mouse":      {"mice"},
+		"goose":      {"geese"},
+		"foot":       {"feet"},
+		"tooth":      {"teeth"},
+		"ox":         {"oxen"},
+		"leaf":       {"leaves"},
+		"knife":      {"knives"},
+		"wife":       {"wives"},
+		"life":       {"lives"},
+		"wolf":       {"wolves"},
+		"index":      {"indices"},
+		"criterion":  {"criteria"},
+		"phenomenon": {"phenomena"},
+		"analysis":   {"analyses"},
+		"be":         {"am", "is", "are", "was", "were", "been", "being"},
+		"have":       {"has", "had", "having"},
+		"do":         {"does", "did", "done"},
+		"go":         {"goes", "went", "gone"},
+		"good":       {"better", "best"},
+		"bad":        {"worse", "worst"},
+		"run":        {"ran"},
+		"see":        {"saw", "seen"},
+		"take":       {"took", "taken"},
+		"write":      {"wrote", "written"},
+		"buy":        {"bought"},
+		"think":      {"thought"},
+		"find":       {"found"},
+		"know":       {"knew", "known"},
+		"make":       {"made"},
+		"say":        {"said"},
+		"come":       {"came"},
+		"give":       {"gave", "given"},
+		"get":        {"got", "gotten"},
+		"speak":      {"spoke", "spoken"},
+	},
+}
+
+// lemmas associe, par langue, la clé de chaque forme irrégulière à son lemme.
+var lemmas = func() map[string]map[string]string {
+	result := make(map[string]map[string]string, len(lemmaForms))
+	for lang, table := range lemmaForms {
+		result[lang] = make(map[string]string)
+		for lemma, forms := range table {
+			for _, form := range forms {
+				result[lang][interfaces.WordKey(form)] = lemma
+			}
+		}
+	}
+	return result
+}()
+
+// Lemma renvoie le lemme d'une forme irrégulière connue, sans tenir compte
+// de la casse ni des accents.
+func Lemma(lang, word string) (string, bool) {
+	lemma, ok := lemmas[lang][interfaces.WordKey(word)]
+	return lemma, ok
+}
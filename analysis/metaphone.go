@@ -0,0 +1,449 @@
+package analysis
+
+import (
+	"strings"
+	"tp2/interfaces"
+)
+
+// Double Metaphone (Lawrence Philips, 2000) : deux codes de prononciation pour
+// un mot anglais, le second couvrant une origine étrangère (slave, germanique,
+// italienne, espagnole…). Les codes font au plus metaphoneLength caractères,
+// comme dans l'implémentation de référence.
+
+const metaphoneLength = 4
+
+type metaphone struct {
+	w                  string // Mot en majuscules ASCII, les mots séparés par une espace
+	last               int
+	primary, secondary strings.Builder
+	slavoGermanic      bool
+}
+
+// DoubleMetaphone renvoie les codes principal et secondaire de word ; le
+// second est vide s'il est égal au premier.
+func DoubleMetaphone(word string) (string, string) {
+	w := strings.ToUpper(strings.Join(Tokens(interfaces.WordKey(word)), " "))
+	if w == "" {
+		return "", ""
+	}
+	m := &metaphone{
+		w:    w,
+		last: len(w) - 1,
+		slavoGermanic: strings.Contains(w, "W") || strings.Contains(w, "K") ||
+			strings.Contains(w, "CZ") || strings.Contains(w, "WITZ"),
+	}
+	m.encode()
+
+	primary, secondary := m.primary.String(), m.secondary.String()
+	if len(primary) > metaphoneLength {
+		primary = primary[:metaphoneLength]
+	}
+	if len(secondary) > metaphoneLength {
+		secondary = secondary[:metaphoneLength]
+	}
+	if secondary == primary {
+		secondary = ""
+	}
+	return primary, secondary
+}
+
+func (m *metaphone) at(i int) byte {
+	if i < 0 || i > m.last {
+		return 0
+	}
+	return m.w[i]
+}
+
+// stringAt indique si l'une des chaînes, toutes de même longueur, se trouve à la position start.
+func (m *metaphone) stringAt(start int, candidates ...string) bool {
+	if start < 0 {
+		return false
+	}
+	for _, c := range candidates {
+		if strings.HasPrefix(m.w[min(start, len(m.w)):], c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) isVowel(i int) bool {
+	return strings.IndexByte("AEIOUY", m.at(i)) >= 0
+}
+
+// add ajoute main au code principal et alt, s'il est donné, au code secondaire.
+func (m *metaphone) add(main string, alt ...string) {
+	m.primary.WriteString(main)
+	if len(alt) > 0 {
+		m.secondary.WriteString(alt[0])
+	} else {
+		m.secondary.WriteString(main)
+	}
+}
+
+// skip renvoie 2 si la lettre suivante double la lettre courante, 1 sinon.
+func (m *metaphone) skip(current int, doubles ...string) int {
+	if m.stringAt(current+1, doubles...) {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encode() {
+	current := 0
+	if m.stringAt(0, "GN", "KN", "PN", "WR", "PS") {
+		current++
+	}
+	if m.at(0) == 'X' {
+		m.add("S")
+		current++
+	}
+
+	for current <= m.last && (m.primary.Len() < metaphoneLength || m.secondary.Len() < metaphoneLength) {
+		switch c := m.at(current); c {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if current == 0 {
+				m.add("A")
+			}
+			current++
+		case 'B':
+			m.add("P")
+			current += m.skip(current, "B")
+		case 'C':
+			current = m.encodeC(current)
+		case 'D':
+			switch {
+			case m.stringAt(current, "DG") && m.stringAt(current+2, "I", "E", "Y"):
+				m.add("J")
+				current += 3
+			case m.stringAt(current, "DG"):
+				m.add("TK")
+				current += 2
+			case m.stringAt(current, "DT", "DD"):
+				m.add("T")
+				current += 2
+			default:
+				m.add("T")
+				current++
+			}
+		case 'F', 'K', 'N':
+			m.add(string(c))
+			current += m.skip(current, string(c))
+		case 'G':
+			current = m.encodeG(current)
+		case 'H':
+			if (current == 0 || m.isVowel(current-1)) && m.isVowel(current+1) {
+				m.add("H")
+				current += 2
+			} else {
+				current++
+			}
+		case 'J':
+			current = m.encodeJ(current)
+		case 'L':
+			if m.at(current+1) == 'L' {
+				if (current == m.last-2 && m.stringAt(current-1, "ILLO", "ILLA", "ALLE")) ||
+					((m.stringAt(m.last-1, "AS", "OS") || m.stringAt(m.last, "A", "O")) && m.stringAt(current-1, "ALLE")) {
+					m.add("L", "")
+				} else {
+					m.add("L")
+				}
+				current += 2
+			} else {
+				m.add("L")
+				current++
+			}
+		case 'M':
+			if (m.stringAt(current-1, "UMB") && (current+1 == m.last || m.stringAt(current+2, "ER"))) || m.at(current+1) == 'M' {
+				current += 2
+			} else {
+				current++
+			}
+			m.add("M")
+		case 'P':
+			if m.at(current+1) == 'H' {
+				m.add("F")
+				current += 2
+			} else {
+				m.add("P")
+				current += m.skip(current, "P", "B")
+			}
+		case 'Q':
+			m.add("K")
+			current += m.skip(current, "Q")
+		case 'R':
+			if current == m.last && !m.slavoGermanic && m.stringAt(current-2, "IE") && !m.stringAt(current-4, "ME", "MA") {
+				m.add("", "R")
+			} else {
+				m.add("R")
+			}
+			current += m.skip(current, "R")
+		case 'S':
+			current = m.encodeS(current)
+		case 'T':
+			switch {
+			case m.stringAt(current, "TION"), m.stringAt(current, "TIA", "TCH"):
+				m.add("X")
+				current += 3
+			case m.stringAt(current, "TH"), m.stringAt(current, "TTH"):
+				if m.stringAt(current+2, "OM", "AM") || m.stringAt(0, "VAN ", "VON ", "SCH") {
+					m.add("T")
+				} else {
+					m.add("0", "T")
+				}
+				current += 2
+			default:
+				m.add("T")
+				current += m.skip(current, "T", "D")
+			}
+		case 'V':
+			m.add("F")
+			current += m.skip(current, "V")
+		case 'W':
+			current = m.encodeW(current)
+		case 'X':
+			if !(current == m.last && (m.stringAt(current-3, "IAU", "EAU") || m.stringAt(current-2, "AU", "OU"))) {
+				m.add("KS")
+			}
+			current += m.skip(current, "C", "X")
+		case 'Z':
+			if m.at(current+1) == 'H' {
+				m.add("J")
+				current += 2
+				break
+			}
+			if m.stringAt(current+1, "ZO", "ZI", "ZA") || (m.slavoGermanic && current > 0 && m.at(current-1) != 'T') {
+				m.add("S", "TS")
+			} else {
+				m.add("S")
+			}
+			current += m.skip(current, "Z")
+		default:
+			current++
+		}
+	}
+}
+
+func (m *metaphone) encodeC(current int) int {
+	switch {
+	// « mac », « bacher » : germanique
+	case current > 1 && !m.isVowel(current-2) && m.stringAt(current-1, "ACH") && m.at(current+2) != 'I' &&
+		(m.at(current+2) != 'E' || m.stringAt(current-2, "BACHER", "MACHER")):
+		m.add("K")
+		return current + 2
+	case current == 0 && m.stringAt(current, "CAESAR"):
+		m.add("S")
+		return current + 2
+	case m.stringAt(current, "CHIA"):
+		m.add("K")
+		return current + 2
+	case m.stringAt(current, "CH"):
+		switch {
+		case current > 0 && m.stringAt(current, "CHAE"):
+			m.add("K", "X")
+		case current == 0 && (m.stringAt(current+1, "HARAC", "HARIS") || m.stringAt(current+1, "HOR", "HYM", "HIA", "HEM")) && !m.stringAt(0, "CHORE"):
+			m.add("K")
+		case m.stringAt(0, "VAN ", "VON ", "SCH") || m.stringAt(current-2, "ORCHES", "ARCHIT", "ORCHID") || m.stringAt(current+2, "T", "S") ||
+			((m.stringAt(current-1, "A", "O", "U", "E") || current == 0) && (current+2 > m.last || m.stringAt(current+2, "L", "R", "N", "M", "B", "H", "F", "V", "W", " "))):
+			m.add("K")
+		case current == 0:
+			m.add("X")
+		case m.stringAt(0, "MC"):
+			m.add("K")
+		default:
+			m.add("X", "K")
+		}
+		return current + 2
+	case m.stringAt(current, "CZ") && !m.stringAt(current-2, "WICZ"):
+		m.add("S", "X")
+		return current + 2
+	case m.stringAt(current+1, "CIA"):
+		m.add("X")
+		return current + 3
+	case m.stringAt(current, "CC") && !(current == 1 && m.at(0) == 'M'):
+		if m.stringAt(current+2, "I", "E", "H") && !m.stringAt(current+2, "HU") {
+			if (current == 1 && m.at(0) == 'A') || m.stringAt(current-1, "UCCEE", "UCCES") {
+				m.add("KS")
+			} else {
+				m.add("X")
+			}
+			return current + 3
+		}
+		m.add("K")
+		return current + 2
+	case m.stringAt(current, "CK", "CG", "CQ"):
+		m.add("K")
+		return current + 2
+	case m.stringAt(current, "CI", "CE", "CY"):
+		if m.stringAt(current, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add("S")
+		}
+		return current + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.stringAt(current+1, " C", " Q", " G"):
+		return current + 3
+	case m.stringAt(current+1, "C", "K", "Q") && !m.stringAt(current+1, "CE", "CI"):
+		return current + 2
+	}
+	return current + 1
+}
+
+func (m *metaphone) encodeG(current int) int {
+	switch {
+	case m.at(current+1) == 'H':
+		switch {
+		case current > 0 && !m.isVowel(current-1):
+			m.add("K")
+		case current == 0:
+			if m.at(current+2) == 'I' {
+				m.add("J")
+			} else {
+				m.add("K")
+			}
+		// Règle de Parker : « hugh », « bough », « broughton »
+		case (current > 1 && m.stringAt(current-2, "B", "H", "D")) ||
+			(current > 2 && m.stringAt(current-3, "B", "H", "D")) ||
+			(current > 3 && m.stringAt(current-4, "B", "H")):
+		// « laugh », « cough », « rough », « tough »
+		case current > 2 && m.at(current-1) == 'U' && m.stringAt(current-3, "C", "G", "L", "R", "T"):
+			m.add("F")
+		case current > 0 && m.at(current-1) != 'I':
+			m.add("K")
+		}
+		return current + 2
+	case m.at(current+1) == 'N':
+		switch {
+		case current == 1 && m.isVowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.stringAt(current+2, "EY") && m.at(current+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return current + 2
+	case m.stringAt(current+1, "LI") && !m.slavoGermanic:
+		m.add("KL", "L")
+		return current + 2
+	case current == 0 && (m.at(current+1) == 'Y' || m.stringAt(current+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.add("K", "J")
+		return current + 2
+	case (m.stringAt(current+1, "ER") || m.at(current+1) == 'Y') && !m.stringAt(0, "DANGER", "RANGER", "MANGER") &&
+		!m.stringAt(current-1, "E", "I") && !m.stringAt(current-1, "RGY", "OGY"):
+		m.add("K", "J")
+		return current + 2
+	case m.stringAt(current+1, "E", "I", "Y") || m.stringAt(current-1, "AGGI", "OGGI"):
+		switch {
+		case m.stringAt(0, "VAN ", "VON ", "SCH") || m.stringAt(current+1, "ET"):
+			m.add("K")
+		case m.stringAt(current+1, "IER"):
+			m.add("J")
+		default:
+			m.add("J", "K")
+		}
+		return current + 2
+	}
+	m.add("K")
+	return current + m.skip(current, "G")
+}
+
+func (m *metaphone) encodeJ(current int) int {
+	if m.stringAt(current, "JOSE") || m.stringAt(0, "SAN ") {
+		if (current == 0 && m.at(current+4) == ' ') || m.stringAt(0, "SAN ") {
+			m.add("H")
+		} else {
+			m.add("J", "H")
+		}
+		return current + 1
+	}
+	switch {
+	case current == 0:
+		m.add("J", "A")
+	case m.isVowel(current-1) && !m.slavoGermanic && (m.at(current+1) == 'A' || m.at(current+1) == 'O'):
+		m.add("J", "H")
+	case current == m.last:
+		m.add("J", "")
+	case !m.stringAt(current+1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.stringAt(current-1, "S", "K", "L"):
+		m.add("J")
+	}
+	return current + m.skip(current, "J")
+}
+
+func (m *metaphone) encodeS(current int) int {
+	switch {
+	// « island », « carlysle »
+	case m.stringAt(current-1, "ISL", "YSL"):
+		return current + 1
+	case current == 0 && m.stringAt(current, "SUGAR"):
+		m.add("X", "S")
+		return current + 1
+	case m.stringAt(current, "SH"):
+		if m.stringAt(current+1, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return current + 2
+	case m.stringAt(current, "SIO", "SIA"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.add("S", "X")
+		}
+		return current + 3
+	case (current == 0 && m.stringAt(current+1, "M", "N", "L", "W")) || m.stringAt(current+1, "Z"):
+		m.add("S", "X")
+		return current + m.skip(current, "Z")
+	case m.stringAt(current, "SC"):
+		switch {
+		case m.at(current+2) == 'H' && m.stringAt(current+3, "ER", "EN"):
+			m.add("X", "SK")
+		case m.at(current+2) == 'H' && m.stringAt(current+3, "OO", "UY", "ED", "EM"):
+			m.add("SK")
+		case m.at(current+2) == 'H' && current == 0 && !m.isVowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		case m.at(current+2) == 'H':
+			m.add("X")
+		case m.stringAt(current+2, "I", "E", "Y"):
+			m.add("S")
+		default:
+			m.add("SK")
+		}
+		return current + 3
+	}
+	// « resnais », « artois » : s final muet en français
+	if current == m.last && m.stringAt(current-2, "AI", "OI") {
+		m.add("", "S")
+	} else {
+		m.add("S")
+	}
+	return current + m.skip(current, "S", "Z")
+}
+
+func (m *metaphone) encodeW(current int) int {
+	if m.stringAt(current, "WR") {
+		m.add("R")
+		return current + 2
+	}
+	if current == 0 && (m.isVowel(current+1) || m.stringAt(current, "WH")) {
+		if m.isVowel(current + 1) {
+			m.add("A", "F")
+		} else {
+			m.add("A")
+		}
+	}
+	// « arnow », « filipowicz » : w muet ou prononcé v
+	if (current == m.last && m.isVowel(current-1)) || m.stringAt(current-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.stringAt(0, "SCH") {
+		m.add("", "F")
+		return current + 1
+	}
+	if m.stringAt(current, "WICZ", "WITZ") {
+		m.add("TS", "FX")
+		return current + 4
+	}
+	return current + 1
+}
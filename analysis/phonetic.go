@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"regexp"
+	"strings"
+	"tp2/interfaces"
+)
+
+// PhoneticKeys renvoie les clés de prononciation d'un mot dans la langue
+// lang : Phonex en français, les deux codes Double Metaphone en anglais et
+// Soundex dans les autres langues. Deux mots qui se prononcent de la même
+// façon partagent une clé ; alt est vide si le mot n'a qu'une clé.
+func PhoneticKeys(lang, word string) (key, alt string) {
+	switch lang {
+	case "fr":
+		return Phonex(word), ""
+	case "en":
+		return DoubleMetaphone(word)
+	default:
+		return Soundex(word), ""
+	}
+}
+
+// soundexCodes donne le chiffre Soundex de chaque lettre ; les voyelles valent
+// 0 et séparent deux consonnes de même chiffre, h et w sont ignorés.
+var soundexCodes = [26]byte{
+	'0', '1', '2', '3', '0', '1', '2', // a à g
+	0, '0', '2', '2', '4', '5', '5', // h à n
+	'0', '1', '2', '6', '2', '3', // o à t
+	'0', '1', 0, '2', '0', '2', // u à z
+}
+
+// Soundex renvoie le code Soundex américain de word : sa première lettre suivie
+// de trois chiffres, par exemple R163 pour « Robert » et « Rupert ».
+func Soundex(word string) string {
+	var code []byte
+	var previous byte
+	for _, c := range []byte(interfaces.WordKey(word)) {
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		digit := soundexCodes[c-'a']
+		if len(code) == 0 {
+			code = append(code, c-'a'+'A')
+			previous = digit
+			continue
+		}
+		if digit == 0 {
+			continue
+		}
+		if digit != '0' && digit != previous {
+			code = append(code, digit)
+			if len(code) == 4 {
+				break
+			}
+		}
+		previous = digit
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	return string(code) + strings.Repeat("0", 4-len(code))
+}
+
+// phonexRules sont les substitutions de Phonex (Frédéric Brouard, 1997), dans
+// l'ordre de l'algorithme. Les chiffres notent les sons an (1), oua (2), ou
+// (3), ein (4) et ch (5).
+var phonexRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`y`), "i"},
+	{regexp.MustCompile(`([^csp]|^)h`), "$1"},
+	{regexp.MustCompile(`ph`), "f"},
+	{regexp.MustCompile(`g(ai?[nm])`), "k$1"},
+	{regexp.MustCompile(`[ae]i[nm]([aeiou])`), "yn$1"},
+	{regexp.MustCompile(`eau`), "o"},
+	{regexp.MustCompile(`oua`), "2"},
+	{regexp.MustCompile(`[ae]i[nm]`), "4"},
+	{regexp.MustCompile(`é|è|ê|ë|ai|ei`), "y"},
+	{regexp.MustCompile(`er`), "yr"},
+	{regexp.MustCompile(`ess`), "yss"},
+	{regexp.MustCompile(`et`), "yt"},
+	{regexp.MustCompile(`[ae][nm]([^aeiouy1234]|$)`), "1$1"},
+	{regexp.MustCompile(`in([^aeiouy1234]|$)`), "4$1"},
+	{regexp.MustCompile(`([aeiouy1234])s([aeiouy1234])`), "${1}z$2"},
+	{regexp.MustCompile(`oe|eu`), "e"},
+	{regexp.MustCompile(`au`), "o"},
+	{regexp.MustCompile(`o[iy]`), "2"},
+	{regexp.MustCompile(`ou`), "3"},
+	{regexp.MustCompile(`s?ch|sh`), "5"},
+	{regexp.MustCompile(`s[sc]`), "s"},
+	{regexp.MustCompile(`c([eiy])`), "s$1"},
+	{regexp.MustCompile(`qu|gu|[cq]`), "k"},
+	{regexp.MustCompile(`g([aoy])`), "k$1"},
+	{regexp.MustCompile(`a`), "o"},
+	{regexp.MustCompile(`[dp]`), "t"},
+	{regexp.MustCompile(`j`), "g"},
+	{regexp.MustCompile(`[bv]`), "f"},
+	{regexp.MustCompile(`m`), "n"},
+}
+
+// Phonex renvoie la clé Phonex d'un mot français, adaptée aux sons du
+// français : « photographie » et « fotografi » ont la même clé.
+func Phonex(word string) string {
+	w := strings.NewReplacer("œ", "oe", "æ", "ae", "ç", "s").Replace(strings.Join(Tokens(word), ""))
+	for _, rule := range phonexRules {
+		w = rule.pattern.ReplaceAllString(w, rule.replacement)
+	}
+	w = interfaces.WordKey(w)
+
+	// Lettres répétées et t ou x final muets.
+	var b []byte
+	for i := 0; i < len(w); i++ {
+		if len(b) == 0 || b[len(b)-1] != w[i] {
+			b = append(b, w[i])
+		}
+	}
+	for len(b) > 1 && (b[len(b)-1] == 't' || b[len(b)-1] == 'x') {
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
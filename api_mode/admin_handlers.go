@@ -0,0 +1,47 @@
+package api_mode
+
+import (
+	"net/http"
+	"os"
+	"time"
+	"tp2/db"
+	"tp2/interfaces"
+)
+
+type backupResponse struct {
+	File string `json:"file"`
+	Size int64  `json:"size"`
+}
+
+// ApiBackupHandler prend une sauvegarde de la base dans backup.dir puis
+// applique la rétention. Réservé aux administrateurs.
+func (s *Server) ApiBackupHandler(repo interfaces.BackupRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateAdmin(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			respondWrongMethod(w, r, http.MethodPost)
+			return
+		}
+
+		dest := db.BackupFileName(s.backup.Dir, time.Now())
+		if err := repo.Backup(r.Context(), dest); err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.backup_failed", err)
+			return
+		}
+		if err := db.PruneBackups(s.backup.Dir, s.backup.Retention); err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.backup_prune_failed", dest, err)
+			return
+		}
+
+		info, err := os.Stat(dest)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.backup_stat_failed", err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, backupResponse{File: dest, Size: info.Size()})
+	}
+}
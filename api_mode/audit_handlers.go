@@ -0,0 +1,66 @@
+package api_mode
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"tp2/interfaces"
+)
+
+const defaultAuditLimit = 100
+
+// ApiAuditHandler renvoie le journal d'audit, filtrable par user, action, word,
+// lang, since et until (RFC 3339) et limit. Réservé aux administrateurs.
+func (s *Server) ApiAuditHandler(repo interfaces.AuditRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateAdmin(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			respondWrongMethod(w, r, http.MethodGet)
+			return
+		}
+
+		lang, ok := listLang(w, r)
+		if !ok {
+			return
+		}
+		query := r.URL.Query()
+		filter := interfaces.AuditFilter{
+			Username: query.Get("user"),
+			Action:   query.Get("action"),
+			Word:     query.Get("word"),
+			Lang:     lang,
+			Limit:    defaultAuditLimit,
+		}
+
+		var err error
+		if since := query.Get("since"); since != "" {
+			if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+				respond(w, r, http.StatusBadRequest, "api.invalid_since", err)
+				return
+			}
+		}
+		if until := query.Get("until"); until != "" {
+			if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+				respond(w, r, http.StatusBadRequest, "api.invalid_until", err)
+				return
+			}
+		}
+		if limit := query.Get("limit"); limit != "" {
+			if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+				respond(w, r, http.StatusBadRequest, "api.invalid_limit")
+				return
+			}
+		}
+
+		entries, err := repo.ListAuditEntries(r.Context(), filter)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.audit_failed", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, entries)
+	}
+}
@@ -0,0 +1,147 @@
+package api_mode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"tp2/dictionary"
+	"tp2/i18n"
+	"tp2/interfaces"
+)
+
+const maxBatchSize = 1000
+
+// Statuts d'une opération dans la réponse de /api/words/batch.
+const (
+	batchStatusOK         = "ok"
+	batchStatusError      = "error"
+	batchStatusRolledBack = "rolled_back"
+)
+
+type batchRequest struct {
+	Atomic     bool                        `json:"atomic"`
+	Operations []interfaces.BatchOperation `json:"operations"`
+}
+
+type batchResult struct {
+	Index  int           `json:"index"`
+	Op     string        `json:"op"`
+	Word   string        `json:"word"`
+	Status string        `json:"status"`
+	Error  string        `json:"error,omitempty"`
+	Fields []fieldReport `json:"fields,omitempty"`
+}
+
+type batchResponse struct {
+	Atomic    bool          `json:"atomic"`
+	Committed bool          `json:"committed"`
+	Results   []batchResult `json:"results"`
+}
+
+// ApiBatchHandler applique une liste d'opérations add, define et remove en une
+// transaction. Avec "atomic": true, une seule opération invalide ou en échec
+// annule tout le lot (409) ; sinon seules les opérations en échec sont ignorées.
+func (s *Server) ApiBatchHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			respondWrongMethod(w, r, http.MethodPost)
+			return
+		}
+
+		lang, ok := entryLang(w, r, r.URL.Query().Get("lang"))
+		if !ok {
+			return
+		}
+
+		var req batchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respond(w, r, http.StatusBadRequest, "api.decode_body", err, r.URL.Path)
+			return
+		}
+		if len(req.Operations) == 0 || len(req.Operations) > maxBatchSize {
+			respond(w, r, http.StatusBadRequest, "api.batch_size", maxBatchSize)
+			return
+		}
+
+		// Les opérations invalides ne sont pas transmises au dépôt.
+		p := printer(r)
+		results := make([]batchResult, len(req.Operations))
+		var valid []interfaces.BatchOperation
+		var validIndexes []int
+		invalid := false
+		for i, op := range req.Operations {
+			results[i] = batchResult{Index: i, Op: op.Op, Word: op.Word, Status: batchStatusOK}
+			if op.Lang == "" {
+				op.Lang = lang
+			}
+			if err := validateBatchOperation(op); err != nil {
+				results[i].Status = batchStatusError
+				results[i].Error = p.Error(err)
+				invalid = true
+				continue
+			}
+			valid = append(valid, op)
+			validIndexes = append(validIndexes, i)
+		}
+
+		response := batchResponse{Atomic: req.Atomic, Results: results}
+		if req.Atomic && invalid {
+			markRolledBack(results)
+			writeJSON(w, http.StatusConflict, response)
+			return
+		}
+
+		errs, err := d.ApplyBatch(r.Context(), valid, req.Atomic)
+		if err != nil && !errors.Is(err, interfaces.ErrBatchRolledBack) {
+			respond(w, r, http.StatusInternalServerError, "api.batch_failed", err)
+			return
+		}
+		for j, opErr := range errs {
+			if opErr != nil {
+				results[validIndexes[j]].Status = batchStatusError
+				results[validIndexes[j]].Error = p.Error(opErr)
+				results[validIndexes[j]].Fields = fieldReports(p, opErr)
+			}
+		}
+
+		if err != nil {
+			markRolledBack(results)
+			writeJSON(w, http.StatusConflict, response)
+			return
+		}
+
+		response.Committed = true
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+func validateBatchOperation(op interfaces.BatchOperation) error {
+	if op.Word == "" {
+		return i18n.Errorf("api.batch_missing_word")
+	}
+	if op.Lang != "" {
+		if _, err := interfaces.NormalizeLang(op.Lang); err != nil {
+			return err
+		}
+	}
+	// Les entrées elles-mêmes sont contrôlées par le dictionnaire (voir dictionary.Validator).
+	switch op.Op {
+	case interfaces.BatchAdd, interfaces.BatchDefine, interfaces.BatchRemove:
+		return nil
+	default:
+		return i18n.Errorf("api.batch_unknown_op", op.Op)
+	}
+}
+
+// markRolledBack signale que les opérations réussies ont été annulées avec le lot.
+func markRolledBack(results []batchResult) {
+	for i := range results {
+		if results[i].Status == batchStatusOK {
+			results[i].Status = batchStatusRolledBack
+		}
+	}
+}
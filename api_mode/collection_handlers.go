@@ -0,0 +1,105 @@
+package api_mode
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"tp2/dictionary"
+	"tp2/interfaces"
+)
+
+// ApiTagsHandler liste les étiquettes utilisées et leur nombre de mots.
+func (s *Server) ApiTagsHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+		if r.Method != http.MethodGet {
+			respondWrongMethod(w, r, http.MethodGet)
+			return
+		}
+
+		tags, err := d.AllTags(r.Context())
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "app.tags_failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tags)
+	}
+}
+
+// ApiCollectionsHandler gère les collections :
+//
+//	GET    /api/collections         liste des collections
+//	POST   /api/collections         crée une collection {"name", "description", "words"}
+//	GET    /api/collections/{nom}   détail d'une collection
+//	PUT    /api/collections/{nom}   remplace la description et les mots
+//	DELETE /api/collections/{nom}   supprime la collection (pas ses mots)
+func (s *Server) ApiCollectionsHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+
+		// Les mots d'une collection sont désignés dans la langue du paramètre lang.
+		lang, ok := entryLang(w, r, r.URL.Query().Get("lang"))
+		if !ok {
+			return
+		}
+
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/collections"), "/")
+		switch {
+		case name == "" && r.Method == http.MethodGet:
+			collections, err := d.Collections(r.Context())
+			if err != nil {
+				respondWordError(w, r, "api.read_collections_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, collections)
+		case name == "" && r.Method == http.MethodPost:
+			var collection interfaces.Collection
+			if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+				respond(w, r, http.StatusBadRequest, "api.decode_body", err, r.URL.Path)
+				return
+			}
+			if collection.Words == nil {
+				collection.Words = []string{}
+			}
+			if err := d.SaveCollection(r.Context(), collection, lang, false); err != nil {
+				respondWordError(w, r, "api.create_collection_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusCreated, collection)
+		case name != "" && r.Method == http.MethodGet:
+			collection, err := d.Collection(r.Context(), name)
+			if err != nil {
+				respondWordError(w, r, "api.read_collection_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, collection)
+		case name != "" && r.Method == http.MethodPut:
+			var collection interfaces.Collection
+			if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+				respond(w, r, http.StatusBadRequest, "api.decode_body", err, r.URL.Path)
+				return
+			}
+			collection.Name = name
+			if collection.Words == nil {
+				collection.Words = []string{}
+			}
+			if err := d.SaveCollection(r.Context(), collection, lang, true); err != nil {
+				respondWordError(w, r, "api.update_collection_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, collection)
+		case name != "" && r.Method == http.MethodDelete:
+			if err := d.DeleteCollection(r.Context(), name); err != nil {
+				respondWordError(w, r, "api.delete_collection_failed", err)
+				return
+			}
+			respond(w, r, http.StatusOK, "api.collection_deleted", name)
+		default:
+			respond(w, r, http.StatusNotFound, "api.unknown_route", r.Method, r.URL.Path)
+		}
+	}
+}
@@ -2,154 +2,176 @@ package api_mode
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 	"tp2/dictionary"
+	"tp2/interfaces"
 )
 
 func WelcomeHandler(w http.ResponseWriter, r *http.Request) {
-	LogAndRespond(w, r, "Bienvenue dans le dico !", http.StatusOK)
+	respond(w, r, http.StatusOK, "app.welcome")
 }
 
-func ApiAddWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
+// addWordRequest est le corps de POST /api/words/add. PartOfSpeech choisit
+// les champs obligatoires (voir validation.required_fields).
+type addWordRequest struct {
+	Word         string `json:"word"`
+	Lang         string `json:"lang"`
+	Definition   string `json:"definition"`
+	PartOfSpeech string `json:"part_of_speech"`
+}
+
+func (s *Server) ApiAddWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !authenticateRequest(w, r) {
+		if !s.authenticateRequest(w, r) {
 			return
 		}
 
 		if r.Method != http.MethodPost {
-			logMessage := fmt.Sprintf("Mauvaise méthode de requête : %s, attendue POST %s", r.Method, r.URL.Path)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+			respondWrongMethod(w, r, http.MethodPost)
 			return
 		}
 
-		var word dictionary.Word
+		var word addWordRequest
 		err := json.NewDecoder(r.Body).Decode(&word)
 		if err != nil {
-			logMessage := fmt.Sprintf("Error decoding request body: %v. Route: %s", err, r.URL.Path)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+			respond(w, r, http.StatusBadRequest, "api.decode_body", err, r.URL.Path)
 			return
 		}
 
-		if word.Word == "" || word.Definition == "" {
-			logMessage := fmt.Sprintf("Clés manquantes dans le corps de la requête. Route: %s", r.URL.Path)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
-			return
+		if word.Lang == "" {
+			word.Lang = r.URL.Query().Get("lang")
 		}
-
-		if err := validateWordAndDefinitionLength(word.Word, word.Definition); err != nil {
-			logMessage := fmt.Sprintf("Erreur de validation : %v", err)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+		lang, ok := entryLang(w, r, word.Lang)
+		if !ok {
 			return
 		}
 
-		if err := d.AddAsync(word.Word, word.Definition); err != nil {
-			logMessage := fmt.Sprintf("Erreur lors de l'ajout du mot : %v", err)
-			LogAndRespond(w, r, logMessage, http.StatusInternalServerError)
+		entry := dictionary.Entry{Word: word.Word, Lang: lang, Definition: word.Definition, PartOfSpeech: word.PartOfSpeech}
+		if err := d.AddEntryAsync(r.Context(), entry); err != nil {
+			if !respondInvalid(w, r, err) {
+				respond(w, r, http.StatusInternalServerError, "api.add_failed", err)
+			}
 			return
 		}
 
-		LogAndRespond(w, r, fmt.Sprintf("Le mot '%s' avec la définition '%s' a été ajouté.", word.Word, word.Definition), http.StatusCreated)
+		respond(w, r, http.StatusCreated, "app.word_added", word.Word, word.Definition)
 	}
 }
 
-func ApiDefineWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
+func (s *Server) ApiDefineWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !authenticateRequest(w, r) {
+		if !s.authenticateRequest(w, r) {
 			return
 		}
 		if r.Method != http.MethodPut {
-			logMessage := fmt.Sprintf("Mauvaise méthode de requête : %s, PUT attendu. Route: %s", r.Method, r.URL.Path)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+			respondWrongMethod(w, r, http.MethodPut)
 			return
 		}
 
 		word := extractWordFromURL(r.URL.Path)
 
 		if word == "" {
-			LogAndRespond(w, r, "Veuillez saisir un mot dans l'URL.", http.StatusBadRequest)
+			respond(w, r, http.StatusBadRequest, "api.word_missing_in_url")
 			return
 		}
 
 		var newDefinition string
 		err := json.NewDecoder(r.Body).Decode(&newDefinition)
 		if err != nil {
-			LogAndRespond(w, r, "Corps de la demande non valide", http.StatusBadRequest)
+			respond(w, r, http.StatusBadRequest, "api.invalid_body")
 			return
 		}
 
-		if err := validateWordAndDefinitionLength(word, newDefinition); err != nil {
-			logMessage := fmt.Sprintf("Erreur de validation : %v", err)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+		lang, ok := entryLang(w, r, r.URL.Query().Get("lang"))
+		if !ok {
 			return
 		}
 
-		err = d.EditAsync(word, newDefinition)
+		err = d.EditAsync(r.Context(), word, lang, newDefinition)
 		if err != nil {
-			logMessage := fmt.Sprintf("Erreur lors de la mise à jour de la définition dans la base de données : %v", err)
-			LogAndRespond(w, r, logMessage, http.StatusInternalServerError)
+			if !respondInvalid(w, r, err) {
+				respond(w, r, http.StatusInternalServerError, "api.define_failed", err)
+			}
 			return
 		}
 
-		LogAndRespond(w, r, fmt.Sprintf("La définition pour le mot '%s' a été mise à jour.", word), http.StatusOK)
+		respond(w, r, http.StatusOK, "app.word_defined", word)
 	}
 }
 
-func ApiRemoveWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
+func (s *Server) ApiRemoveWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !authenticateRequest(w, r) {
+		if !s.authenticateRequest(w, r) {
 			return
 		}
 
 		if r.Method != http.MethodDelete {
-			logMessage := fmt.Sprintf("Mauvaise méthode de requête: %s, Delete attendu. Route: %s", r.Method, r.URL.Path)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+			respondWrongMethod(w, r, http.MethodDelete)
 			return
 		}
 
 		word := extractWordFromURL(r.URL.Path)
 
 		if word == "" {
-			LogAndRespond(w, r, "Veuillez saisir un mot dans l'URL.", http.StatusBadRequest)
+			respond(w, r, http.StatusBadRequest, "api.word_missing_in_url")
+			return
+		}
+
+		lang, ok := entryLang(w, r, r.URL.Query().Get("lang"))
+		if !ok {
 			return
 		}
 
-		err := d.RemoveAsync(word)
+		err := d.RemoveAsync(r.Context(), word, lang)
 		if err != nil {
-			logMessage := fmt.Sprintf("Erreur lors de la suppression du mot dans la base de données : %v", err)
-			LogAndRespond(w, r, logMessage, http.StatusInternalServerError)
+			respondWordError(w, r, "api.remove_failed", err)
 			return
 		}
 
-		logMessage := fmt.Sprintf("Suppression du mot %s", word)
-		LogAndRespond(w, r, logMessage, http.StatusOK)
+		respond(w, r, http.StatusOK, "api.word_removed", word)
 	}
 }
 
-func ApiListWordsHandler(d *dictionary.Dictionary) http.HandlerFunc {
+func (s *Server) ApiListWordsHandler(d *dictionary.Dictionary) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !authenticateRequest(w, r) {
+		if !s.authenticateRequest(w, r) {
 			return
 		}
 
 		if r.Method != http.MethodGet {
-			logMessage := fmt.Sprintf("Mauvaise méthode de requête :%s, GET attendu. Route: %s", r.Method, r.URL.Path)
-			LogAndRespond(w, r, logMessage, http.StatusBadRequest)
+			respondWrongMethod(w, r, http.MethodGet)
 			return
 		}
 
-		wordsList, err := d.List()
+		lang, ok := listLang(w, r)
+		if !ok {
+			return
+		}
+
+		var (
+			wordsList []dictionary.Word
+			err       error
+		)
+		if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+			wordsList, err = d.ListByTags(r.Context(), tags)
+		} else {
+			wordsList, err = d.List(r.Context())
+		}
+		if errors.Is(err, interfaces.ErrInvalidTag) {
+			respond(w, r, http.StatusBadRequest, "api.invalid_tag", err)
+			return
+		}
 		if err != nil {
-			LogAndRespond(w, r, fmt.Sprintf("Erreur lors de la récupération de la liste des mots : %s", err.Error()), http.StatusInternalServerError)
+			respond(w, r, http.StatusInternalServerError, "app.list_failed", err)
 			return
 		}
+		wordsList = dictionary.FilterLang(wordsList, lang)
 
 		if len(wordsList) == 0 {
-			LogAndRespond(w, r, "Aucun mot dans le dico.", http.StatusOK)
+			respond(w, r, http.StatusOK, "app.no_words")
 		} else {
-			logMessage := fmt.Sprintf("Requête : %s. Route: %s", r.Method, r.URL.Path)
-			LogToFile("ApiListWordsHandler", logMessage)
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(wordsList)
 		}
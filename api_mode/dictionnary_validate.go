@@ -1,20 +1,48 @@
 package api_mode
 
-import "fmt"
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"tp2/dictionary"
+	"tp2/i18n"
+)
 
-func validateWordAndDefinitionLength(word, definition string) error {
-	minWordLength := 2
-	maxWordLength := 30
-	minDefinitionLength := 5
-	maxDefinitionLength := 255
+// fieldReport est le défaut d'un champ tel que renvoyé aux clients.
+type fieldReport struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
 
-	if len(word) < minWordLength || len(word) > maxWordLength {
-		return fmt.Errorf("La longueur du mot doit être entre %d et %d caractères", minWordLength, maxWordLength)
-	}
+type validationResponse struct {
+	Error  string        `json:"error"`
+	Fields []fieldReport `json:"fields"`
+}
 
-	if len(definition) < minDefinitionLength || len(definition) > maxDefinitionLength {
-		return fmt.Errorf("La longueur de la définition doit être entre %d et %d caractères", minDefinitionLength, maxDefinitionLength)
+// fieldReports renvoie les défauts de err dans la langue de p, ou nil si err
+// n'est pas un *dictionary.ValidationError.
+func fieldReports(p i18n.Printer, err error) []fieldReport {
+	var invalid *dictionary.ValidationError
+	if !errors.As(err, &invalid) {
+		return nil
 	}
+	reports := make([]fieldReport, len(invalid.Fields))
+	for i, f := range invalid.Fields {
+		reports[i] = fieldReport{Field: f.Field, Code: f.Code, Message: f.Localize(p)}
+	}
+	return reports
+}
 
-	return nil
+// respondInvalid répond 400 avec le détail de chaque champ lorsque err est un
+// *dictionary.ValidationError, et renvoie false sinon.
+func respondInvalid(w http.ResponseWriter, r *http.Request, err error) bool {
+	p := printer(r)
+	fields := fieldReports(p, err)
+	if fields == nil {
+		return false
+	}
+	slog.Log(r.Context(), levelForStatus(http.StatusBadRequest), i18n.T("api.validation_error", err), "route", r.URL.Path, "status", http.StatusBadRequest)
+	writeJSON(w, http.StatusBadRequest, validationResponse{Error: p.T("api.validation_error", err), Fields: fields})
+	return true
 }
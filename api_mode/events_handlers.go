@@ -0,0 +1,189 @@
+package api_mode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tp2/dictionary"
+
+	"github.com/gorilla/websocket"
+)
+
+// heartbeatInterval espace les messages de maintien envoyés aux clients inactifs.
+const heartbeatInterval = 15 * time.Second
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// ApiEventsHandler diffuse les modifications du dictionnaire en Server-Sent Events.
+// Filtres : type (liste séparée par des virgules) et prefix. La reprise se fait
+// avec l'en-tête Last-Event-ID ou le paramètre last_event_id.
+func (s *Server) ApiEventsHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateStream(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			respondWrongMethod(w, r, http.MethodGet)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			respond(w, r, http.StatusInternalServerError, "api.streaming_unsupported")
+			return
+		}
+
+		lastID := r.Header.Get("Last-Event-ID")
+		if lastID == "" {
+			lastID = r.URL.Query().Get("last_event_id")
+		}
+		sub, missed, ok := subscribe(w, r, d, lastID)
+		if !ok {
+			return
+		}
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", 3000)
+
+		for _, event := range missed {
+			writeSSE(w, event)
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, open := <-sub.C:
+				if !open {
+					// Client trop lent : il se reconnecte et reprend depuis son dernier ID.
+					return
+				}
+				writeSSE(w, event)
+				flusher.Flush()
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event dictionary.Event) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+// ApiEventsWebSocketHandler diffuse les mêmes événements que ApiEventsHandler
+// sur une WebSocket, un message JSON par événement.
+func (s *Server) ApiEventsWebSocketHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateStream(w, r) {
+			return
+		}
+
+		sub, missed, ok := subscribe(w, r, d, r.URL.Query().Get("last_event_id"))
+		if !ok {
+			return
+		}
+		defer sub.Close()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade a déjà répondu au client.
+			return
+		}
+		defer conn.Close()
+
+		// La lecture détecte la fermeture de la connexion par le client.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for _, event := range missed {
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case event, open := <-sub.C:
+				if !open {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, printer(r).T("api.client_too_slow")))
+					return
+				}
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeatInterval)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// subscribe abonne le client avec les filtres de la requête. Un identifiant
+// de reprise trop ancien est refusé avec 410 pour que le client recharge la liste.
+func subscribe(w http.ResponseWriter, r *http.Request, d *dictionary.Dictionary, lastEventID string) (*dictionary.Subscription, []dictionary.Event, bool) {
+	var lastID uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			respond(w, r, http.StatusBadRequest, "api.invalid_event_id", lastEventID)
+			return nil, nil, false
+		}
+		lastID = id
+	}
+
+	lang, ok := listLang(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	query := r.URL.Query()
+	filter := dictionary.EventFilter{Prefix: query.Get("prefix"), Lang: lang}
+	if types := query.Get("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			filter.Types = append(filter.Types, strings.TrimSpace(t))
+		}
+	}
+
+	sub, missed, err := d.Events().Subscribe(r.Context(), lastID, filter)
+	if errors.Is(err, dictionary.ErrEventsExpired) {
+		respond(w, r, http.StatusGone, "api.events_expired")
+		return nil, nil, false
+	}
+	if err != nil {
+		respond(w, r, http.StatusInternalServerError, "api.events_subscribe_failed", err)
+		return nil, nil, false
+	}
+	return sub, missed, true
+}
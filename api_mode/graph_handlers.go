@@ -0,0 +1,116 @@
+package api_mode
+
+import (
+	"net/http"
+	"strconv"
+	"tp2/dictionary"
+	"tp2/graph"
+)
+
+const maxGraphDepth = 5
+
+type pathResponse struct {
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Path []graph.Step `json:"path"`
+}
+
+type componentResponse struct {
+	Word      string   `json:"word"`
+	Component []string `json:"component"`
+}
+
+type neighbourhoodResponse struct {
+	Word       string            `json:"word"`
+	Depth      int               `json:"depth"`
+	Neighbours []graph.Neighbour `json:"neighbours"`
+	Edges      []graph.Edge      `json:"edges"`
+}
+
+// serveGraph répond à GET /api/words/{mot}/graph :
+//
+//	?to=cible         plus court chemin vers cible
+//	?component=true   composante connexe du mot
+//	?depth=n          mots à au plus n relations (1 par défaut, 5 au plus),
+//	                  en JSON ou en export avec format=dot ou format=graphml
+func serveGraph(w http.ResponseWriter, r *http.Request, d *dictionary.Dictionary, word, lang string) {
+	query := r.URL.Query()
+	component, _ := strconv.ParseBool(query.Get("component"))
+	if to := query.Get("to"); to != "" || component {
+		g, err := d.Graph(r.Context(), lang)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.graph_failed", err)
+			return
+		}
+		// Le mot de l'URL peut différer du mot enregistré par la casse ou les accents.
+		if resolved, ok := g.Resolve(word); ok {
+			word = resolved
+		}
+		if to != "" {
+			servePath(w, r, g, word, to)
+			return
+		}
+		words, err := g.Component(word)
+		if err != nil {
+			respondWordError(w, r, "api.component_failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, componentResponse{Word: word, Component: words})
+		return
+	}
+
+	depth := 1
+	if value := query.Get("depth"); value != "" {
+		var err error
+		if depth, err = strconv.Atoi(value); err != nil || depth < 1 || depth > maxGraphDepth {
+			respond(w, r, http.StatusBadRequest, "api.invalid_depth", maxGraphDepth)
+			return
+		}
+	}
+	sub, err := d.NeighbourhoodGraph(r.Context(), word, lang, depth)
+	if err != nil {
+		respondWordError(w, r, "api.traverse_failed", err)
+		return
+	}
+	if resolved, ok := sub.Resolve(word); ok {
+		word = resolved
+	}
+	neighbours, err := sub.Neighbourhood(word, depth)
+	if err != nil {
+		respondWordError(w, r, "api.traverse_failed", err)
+		return
+	}
+
+	switch query.Get("format") {
+	case "", "json":
+		edges := sub.Edges()
+		if edges == nil {
+			edges = []graph.Edge{}
+		}
+		if neighbours == nil {
+			neighbours = []graph.Neighbour{}
+		}
+		writeJSON(w, http.StatusOK, neighbourhoodResponse{Word: word, Depth: depth, Neighbours: neighbours, Edges: edges})
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		sub.WriteDOT(w)
+	case "graphml":
+		w.Header().Set("Content-Type", "application/graphml+xml; charset=utf-8")
+		sub.WriteGraphML(w)
+	default:
+		respond(w, r, http.StatusBadRequest, "api.invalid_format")
+	}
+}
+
+// servePath répond avec le plus court chemin de word à to dans g.
+func servePath(w http.ResponseWriter, r *http.Request, g *graph.Graph, word, to string) {
+	if resolved, ok := g.Resolve(to); ok {
+		to = resolved
+	}
+	path, err := g.ShortestPath(word, to)
+	if err != nil {
+		respondWordError(w, r, "api.path_failed", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pathResponse{From: word, To: to, Path: path})
+}
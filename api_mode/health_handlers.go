@@ -0,0 +1,70 @@
+package api_mode
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"tp2/buildinfo"
+	"tp2/dictionary"
+	"tp2/logging"
+)
+
+// Les sondes ne passent pas par LogAndRespond et sont exclues des logs
+// d'accès afin de ne pas les remplir à chaque interrogation du répartiteur de charge.
+
+const workerPingTimeout = 2 * time.Second
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// HealthzHandler indique simplement que le processus est en vie.
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ReadyzHandler vérifie la base de données, la goroutine de traitement et le fichier de log.
+func ReadyzHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := readinessResponse{Status: "ok", Checks: map[string]string{}}
+		status := http.StatusOK
+
+		fail := func(check string, message string) {
+			response.Checks[check] = message
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+
+		if err := d.Ping(r.Context()); err != nil {
+			fail("database", err.Error())
+		} else {
+			response.Checks["database"] = "ok"
+		}
+
+		if !d.WorkerAlive(workerPingTimeout) {
+			fail("worker", printer(r).T("api.worker_unresponsive"))
+		} else {
+			response.Checks["worker"] = "ok"
+		}
+
+		if err := logging.CheckWritable(); err != nil {
+			fail("log_file", err.Error())
+		} else {
+			response.Checks["log_file"] = "ok"
+		}
+
+		writeJSON(w, status, response)
+	}
+}
+
+// VersionHandler renvoie les informations de build du binaire.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildinfo.Get())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,227 @@
+package api_mode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+	"tp2/interfaces"
+)
+
+const (
+	headerIdempotencyKey      = "Idempotency-Key"
+	headerIdempotencyReplayed = "Idempotency-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentBodySize     = 1 << 20 // Taille maximale du corps d'une requête portant une clé
+	// Durée au-delà de laquelle une requête sans réponse est tenue pour
+	// interrompue : sa clé peut alors être reprise par une nouvelle tentative.
+	idempotencyReservation = time.Minute
+)
+
+// SetIdempotencyStore conserve les clés Idempotency-Key du serveur dans store,
+// en mémoire par défaut.
+func (s *Server) SetIdempotencyStore(store interfaces.IdempotencyRepository) {
+	s.idempotency = store
+}
+
+// Idempotent rejoue la réponse d'origine lorsqu'une requête de modification est
+// renvoyée avec le même en-tête Idempotency-Key. Une clé réutilisée pour une
+// requête différente est refusée (422), tout comme une clé dont la première
+// requête est encore en cours (409) ; passé idempotencyReservation, une
+// requête sans réponse est tenue pour interrompue et la clé est reprise. Les
+// erreurs 5xx ne sont pas enregistrées pour que le client puisse réessayer.
+func (s *Server) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(headerIdempotencyKey)
+		if key == "" || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next(w, r)
+			return
+		}
+		// Le jeton est vérifié avant la clé : un refus 401 n'est pas enregistré.
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respond(w, r, http.StatusBadRequest, "api.idempotency_key_too_long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respond(w, r, http.StatusRequestEntityTooLarge, "api.body_too_large", maxIdempotentBodySize)
+			return
+		}
+		if err != nil {
+			respond(w, r, http.StatusBadRequest, "api.unreadable_body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// La clé est propre à chaque jeton : deux clients ne partagent pas leurs réponses.
+		storeKey := hashOf([]byte(r.Header.Get("Authorization")), []byte(key))
+		requestHash := hashOf([]byte(r.Method), []byte(r.URL.RequestURI()), body)
+
+		ctx := context.WithoutCancel(r.Context())
+		now := time.Now()
+		s.purgeIdempotencyKeys(ctx, now)
+		reservedUntil := now.Add(min(idempotencyReservation, s.idempotencyWindow))
+		recorded, found, err := s.idempotency.ReserveIdempotencyKey(ctx, storeKey, requestHash, now, reservedUntil, now.Add(s.idempotencyWindow))
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.idempotency_failed", err)
+			return
+		}
+		if found {
+			switch {
+			case recorded.RequestHash != requestHash:
+				respond(w, r, http.StatusUnprocessableEntity, "api.idempotency_key_reused")
+			case recorded.Status == 0:
+				respond(w, r, http.StatusConflict, "api.idempotency_in_progress")
+			default:
+				replay(w, recorded)
+			}
+			return
+		}
+
+		rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			err = s.idempotency.ReleaseIdempotencyKey(ctx, storeKey)
+		} else {
+			err = s.idempotency.CompleteIdempotencyKey(ctx, storeKey, interfaces.IdempotentResponse{
+				RequestHash: requestHash,
+				Status:      rec.status,
+				Header:      w.Header().Clone(),
+				Body:        rec.body.Bytes(),
+			})
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "enregistrement de la clé d'idempotence impossible", "error", err)
+		}
+	}
+}
+
+// purgeIdempotencyKeys supprime les clés expirées, au plus une fois par
+// dixième de la durée de conservation.
+func (s *Server) purgeIdempotencyKeys(ctx context.Context, now time.Time) {
+	s.purgeMu.Lock()
+	if now.Sub(s.lastPurge) <= s.idempotencyWindow/10 {
+		s.purgeMu.Unlock()
+		return
+	}
+	s.lastPurge = now
+	s.purgeMu.Unlock()
+
+	if _, err := s.idempotency.PurgeIdempotencyKeys(ctx, now); err != nil {
+		slog.WarnContext(ctx, "purge des clés d'idempotence impossible", "error", err)
+	}
+}
+
+// memoryIdempotencyStore garde les clés en mémoire, pour les stockages qui ne
+// les conservent pas : elles sont perdues au redémarrage.
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+}
+
+type memoryIdempotencyRecord struct {
+	response interfaces.IdempotentResponse
+	reserved time.Time
+	expires  time.Time
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]memoryIdempotencyRecord)}
+}
+
+func (m *memoryIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, now, reservedUntil, expiresAt time.Time) (interfaces.IdempotentResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.records[key]; ok && now.Before(record.expires) {
+		if record.response.Status != 0 || now.Before(record.reserved) {
+			return record.response, true, nil
+		}
+	}
+	m.records[key] = memoryIdempotencyRecord{
+		response: interfaces.IdempotentResponse{RequestHash: requestHash},
+		reserved: reservedUntil,
+		expires:  expiresAt,
+	}
+	return interfaces.IdempotentResponse{}, false, nil
+}
+
+func (m *memoryIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, key string, response interfaces.IdempotentResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.records[key]; ok {
+		record.response = response
+		m.records[key] = record
+	}
+	return nil
+}
+
+func (m *memoryIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, key)
+	return nil
+}
+
+func (m *memoryIdempotencyStore) PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	purged := 0
+	for key, record := range m.records {
+		if !now.Before(record.expires) {
+			delete(m.records, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// replay renvoie la réponse enregistrée, sans l'identifiant de la requête d'origine.
+func replay(w http.ResponseWriter, response interfaces.IdempotentResponse) {
+	for name, values := range response.Header {
+		if name == "X-Request-Id" {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.Header().Set(headerIdempotencyReplayed, "true")
+	w.WriteHeader(response.Status)
+	w.Write(response.Body)
+}
+
+// capturingWriter transmet la réponse au client tout en la copiant.
+type capturingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *capturingWriter) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingWriter) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+func hashOf(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,93 @@
+package api_mode
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"tp2/i18n"
+	"tp2/interfaces"
+)
+
+// printer renvoie le Printer de la langue des messages demandée par l'en-tête
+// Accept-Language, sinon de celle du serveur (voir Server.Localize), sinon de
+// la langue par défaut.
+func printer(r *http.Request) i18n.Printer {
+	for _, locale := range acceptedLangs(r.Header.Get("Accept-Language")) {
+		if i18n.IsSupported(locale) {
+			return i18n.For(locale)
+		}
+	}
+	if locale, ok := r.Context().Value(localeKey{}).(string); ok {
+		return i18n.For(locale)
+	}
+	return i18n.For(i18n.Default())
+}
+
+// entryLang renvoie la langue des entrées lues ou modifiées par la requête,
+// la même pour toutes les routes : lang (paramètre ou champ de la requête) s'il
+// est donné, sinon la langue préférée de l'en-tête Accept-Language, sinon
+// DefaultLang. Répond 400 et renvoie false si lang est invalide.
+func entryLang(w http.ResponseWriter, r *http.Request, lang string) (string, bool) {
+	if lang == "" {
+		if accepted := acceptedLangs(r.Header.Get("Accept-Language")); len(accepted) > 0 {
+			return accepted[0], true
+		}
+		return interfaces.DefaultLang, true
+	}
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		respond(w, r, http.StatusBadRequest, "api.invalid_lang", err)
+		return "", false
+	}
+	return lang, true
+}
+
+// listLang renvoie la langue du paramètre lang normalisée, "" s'il est absent.
+func listLang(w http.ResponseWriter, r *http.Request) (string, bool) {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		return "", true
+	}
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		respond(w, r, http.StatusBadRequest, "api.invalid_lang", err)
+		return "", false
+	}
+	return lang, true
+}
+
+// acceptedLangs renvoie les langues de l'en-tête Accept-Language, de la plus
+// à la moins préférée. Les codes invalides, « * » et q=0 sont ignorés.
+func acceptedLangs(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, err := interfaces.NormalizeLang(tag)
+		if err != nil || q <= 0 || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		langs = append(langs, weighted{lang, q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, len(langs))
+	for i, l := range langs {
+		result[i] = l.lang
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package api_mode
+
+import (
+	"net/http"
+	"tp2/interfaces"
+	"tp2/lint"
+)
+
+// ApiLintHandler analyse le dictionnaire et renvoie ses anomalies avec une
+// correction suggérée, en JSON ou en texte avec format=text. Réservé aux administrateurs.
+func (s *Server) ApiLintHandler(repo interfaces.WordRepository, opts lint.Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateAdmin(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			respondWrongMethod(w, r, http.MethodGet)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "text" {
+			respond(w, r, http.StatusBadRequest, "api.invalid_lint_format")
+			return
+		}
+
+		report, err := lint.Run(r.Context(), repo, opts)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.lint_failed", err)
+			return
+		}
+
+		p := printer(r)
+		if format == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			report.WriteText(w, p)
+			return
+		}
+		writeJSON(w, http.StatusOK, report.Summary(p))
+	}
+}
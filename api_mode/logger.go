@@ -1,43 +1,100 @@
 package api_mode
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
-	"path/filepath"
 	"time"
+	"tp2/i18n"
+	"tp2/requestctx"
 )
 
-var (
-	logFile *os.File
-	logger  *log.Logger
-)
+// Routes exclues des logs d'accès : sondes et collecte des métriques.
+var unloggedRoutes = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/version": true,
+	"/metrics": true,
+}
 
-func init() {
-	currentTime := time.Now()
-	logFileName := fmt.Sprintf("logs/app_%s.log", currentTime.Format("2006-01-02"))
-	logsDir := filepath.Dir(logFileName)
-	if err := os.MkdirAll(logsDir, os.ModePerm); err != nil {
-		log.Fatal("Impossible de créer le dossier logs:", err)
-	}
+// LogAndRespond journalise le message au niveau correspondant au statut puis l'envoie au client.
+func LogAndRespond(w http.ResponseWriter, r *http.Request, message string, status int) {
+	slog.Log(r.Context(), levelForStatus(status), message, "route", r.URL.Path, "status", status)
+	w.WriteHeader(status)
+	fmt.Fprintln(w, message)
+}
 
-	var err error
-	logFile, err = os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatal("Impossible d'ouvrir le fichier de log:", err)
+// respond envoie au client le message key du catalogue dans la langue de son
+// en-tête Accept-Language, et le journalise dans la langue par défaut.
+func respond(w http.ResponseWriter, r *http.Request, status int, key string, args ...any) {
+	slog.Log(r.Context(), levelForStatus(status), i18n.T(key, args...), "route", r.URL.Path, "status", status)
+	w.WriteHeader(status)
+	fmt.Fprintln(w, printer(r).T(key, args...))
+}
+
+// respondWrongMethod refuse une requête dont la méthode n'est pas expected.
+func respondWrongMethod(w http.ResponseWriter, r *http.Request, expected string) {
+	respond(w, r, http.StatusBadRequest, "api.wrong_method", r.Method, expected, r.URL.Path)
+}
+
+func levelForStatus(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelDebug
 	}
+}
+
+// RequestLogger attribue un identifiant de corrélation à chaque requête
+// (repris de l'en-tête X-Request-ID s'il est fourni), le propage dans le
+// contexte et écrit un log d'accès une fois la réponse envoyée.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
 
-	logger = log.New(logFile, "DICO: ", log.Ldate|log.Ltime|log.Lshortfile)
+		info := &requestctx.Info{RequestID: requestID, ClientIP: clientIP(r)}
+		r = r.WithContext(requestctx.With(r.Context(), info))
+
+		start := time.Now()
+		rec := newResponseRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		if unloggedRoutes[r.URL.Path] {
+			return
+		}
+		slog.LogAttrs(r.Context(), slog.LevelInfo, "requête traitée",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", info.ClientIP),
+		)
+	})
 }
 
-func LogToFile(context, message string) {
-	logMessage := fmt.Sprintf("[%s] %s", context, message)
-	logger.Println(logMessage)
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
 }
 
-func LogAndRespond(w http.ResponseWriter, r *http.Request, message string, status int) {
-	LogToFile(r.URL.Path, fmt.Sprintf("Requête reçue : %s %s", r.Method, r.URL.Path))
-	w.WriteHeader(status)
-	fmt.Fprintln(w, message)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
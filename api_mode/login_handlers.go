@@ -1,36 +1,14 @@
 package api_mode
 
 import (
+	"crypto/subtle"
 	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
-	"os"
-
-	"github.com/joho/godotenv"
 )
 
-func init() {
-	if !isTesting() {
-		err := godotenv.Load()
-		if err != nil {
-			log.Fatal("Erreur lors du chargement du fichier .env")
-		}
-	}
-}
-
-func isTesting() bool {
-	for _, arg := range os.Args {
-		if arg == "-test.v=true" {
-			return true
-		}
-	}
-	return false
-}
-
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		LogAndRespond(w, r, fmt.Sprintf("Mauvaise méthode de requête: %s, POST attendu. Route: %s", r.Method, r.URL.Path), http.StatusBadRequest)
+		respondWrongMethod(w, r, http.MethodPost)
 		return
 	}
 
@@ -38,7 +16,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	err := decoder.Decode(&requestBody)
 	if err != nil {
-		LogAndRespond(w, r, "Erreur lors de la lecture du corps de la requête.", http.StatusBadRequest)
+		respond(w, r, http.StatusBadRequest, "api.read_body_failed")
 		return
 	}
 
@@ -46,24 +24,25 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	password, passwordExists := requestBody["password"]
 
 	if !usernameExists || !passwordExists {
-		LogAndRespond(w, r, "Nom d'utilisateur et mot de passe requis.", http.StatusBadRequest)
+		respond(w, r, http.StatusBadRequest, "api.credentials_required")
 		return
 	}
 
-	if !isValidUser(username, password) {
-		LogAndRespond(w, r, fmt.Sprintf("Nom d'utilisateur ou mot de passe incorrect pour l'utilisateur: %s", username), http.StatusUnauthorized)
+	if !s.isValidUser(username, password) {
+		respond(w, r, http.StatusUnauthorized, "api.invalid_credentials", username)
 		return
 	}
 
-	token, err := generateToken(username)
+	token, err := s.generateToken(username)
 	if err != nil {
-		LogAndRespond(w, r, fmt.Sprintf("Erreur lors de la génération du jeton d'authentification pour l'utilisateur: %s", username), http.StatusInternalServerError)
+		respond(w, r, http.StatusInternalServerError, "api.token_failed", username)
 		return
 	}
 
 	LogAndRespond(w, r, token, http.StatusOK)
 }
 
-func isValidUser(username, password string) bool {
-	return username == "nabil" && password == "10"
+func (s *Server) isValidUser(username, password string) bool {
+	expected, ok := s.auth.Users[username]
+	return ok && subtle.ConstantTimeCompare([]byte(expected), []byte(password)) == 1
 }
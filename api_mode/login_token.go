@@ -2,35 +2,80 @@ package api_mode
 
 import (
 	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"strings"
+	"tp2/requestctx"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
-func authenticateRequest(w http.ResponseWriter, r *http.Request) bool {
-	token := r.Header.Get("Authorization")
-	if token == "" {
-		LogAndRespond(w, r, "Accès non autorisé. Le jeton d'authentification est requis.", http.StatusUnauthorized)
+const roleAdmin = "admin"
+
+func (s *Server) authenticateRequest(w http.ResponseWriter, r *http.Request) bool {
+	_, ok := s.authenticate(w, r)
+	return ok
+}
+
+// authenticateAdmin n'accepte que les jetons portant le rôle administrateur.
+func (s *Server) authenticateAdmin(w http.ResponseWriter, r *http.Request) bool {
+	claims, ok := s.authenticate(w, r)
+	if !ok {
 		return false
 	}
 
-	if !IsValidToken(token) {
-		LogAndRespond(w, r, "Accès non autorisé. Jeton d'authentification invalide.", http.StatusUnauthorized)
+	if role, _ := claims["role"].(string); role != roleAdmin {
+		respond(w, r, http.StatusForbidden, "api.admin_only")
 		return false
 	}
 
 	return true
 }
 
-func IsValidToken(tokenString string) bool {
-	secretKey := []byte(os.Getenv("SECRET_KEY"))
-	if secretKey == nil {
-		log.Println("La variable d'environnement SECRET_KEY n'est pas définie.")
-		return false
+// authenticateStream accepte aussi le jeton dans le paramètre token, car
+// EventSource et WebSocket ne permettent pas d'ajouter d'en-tête depuis un navigateur.
+func (s *Server) authenticateStream(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") == "" {
+		if token := r.URL.Query().Get("token"); token != "" {
+			r.Header.Set("Authorization", token)
+		}
+	}
+	return s.authenticateRequest(w, r)
+}
+
+// authenticate valide le jeton de la requête et renseigne l'utilisateur
+// dans le contexte pour les logs d'accès et le journal d'audit.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (jwt.MapClaims, bool) {
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		respond(w, r, http.StatusUnauthorized, "api.token_required")
+		return nil, false
 	}
 
+	claims, err := s.parseToken(token)
+	if err != nil {
+		respond(w, r, http.StatusUnauthorized, "api.token_invalid")
+		return nil, false
+	}
+
+	username, _ := claims["username"].(string)
+	requestctx.SetUser(r.Context(), username)
+
+	return claims, true
+}
+
+func (s *Server) IsValidToken(tokenString string) bool {
+	_, err := s.parseToken(tokenString)
+	return err == nil
+}
+
+// parseToken vérifie la signature du jeton et renvoie ses claims.
+// Le préfixe "Bearer " et les espaces autour du jeton sont ignorés.
+func (s *Server) parseToken(tokenString string) (jwt.MapClaims, error) {
+	tokenString = strings.TrimSpace(tokenString)
+	tokenString = strings.TrimSpace(strings.TrimPrefix(tokenString, "Bearer "))
+
+	secretKey := []byte(s.auth.SecretKey)
+
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Méthode de signature invalide: %v", token.Header["alg"])
@@ -39,22 +84,27 @@ func IsValidToken(tokenString string) bool {
 	})
 
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	return token.Valid
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("jeton invalide")
+	}
+
+	return claims, nil
 }
 
-func generateToken(username string) (string, error) {
-	secretKey := []byte(os.Getenv("SECRET_KEY"))
-	if secretKey == nil {
-		log.Fatal("La variable d'environnement SECRET_KEY n'est pas définie.")
-	}
+func (s *Server) generateToken(username string) (string, error) {
+	secretKey := []byte(s.auth.SecretKey)
 
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	claims := token.Claims.(jwt.MapClaims)
 	claims["username"] = username
+	if s.isAdminUser(username) {
+		claims["role"] = roleAdmin
+	}
 
 	tokenString, err := token.SignedString(secretKey)
 	if err != nil {
@@ -63,3 +113,13 @@ func generateToken(username string) (string, error) {
 
 	return tokenString, nil
 }
+
+// isAdminUser indique si l'utilisateur figure dans auth.admin_users.
+func (s *Server) isAdminUser(username string) bool {
+	for _, admin := range s.auth.AdminUsers {
+		if admin == username {
+			return true
+		}
+	}
+	return false
+}
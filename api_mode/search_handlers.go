@@ -0,0 +1,175 @@
+package api_mode
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"tp2/dictionary"
+	"tp2/i18n"
+	"tp2/interfaces"
+)
+
+const (
+	defaultSearchLimit  = 20
+	defaultSuggestLimit = 10
+)
+
+type searchResponse struct {
+	Query   string                 `json:"query"`
+	Results []interfaces.SearchHit `json:"results"`
+}
+
+// wordsResponse liste les entrées trouvées pour q.
+type wordsResponse struct {
+	Query   string            `json:"query"`
+	Results []interfaces.Word `json:"results"`
+}
+
+type suggestResponse struct {
+	Query       string                `json:"query"`
+	Suggestions []interfaces.EntryRef `json:"suggestions"`
+}
+
+// notFoundResponse accompagne un mot introuvable des entrées proches.
+type notFoundResponse struct {
+	Error       string                `json:"error"`
+	Suggestions []interfaces.EntryRef `json:"suggestions"`
+}
+
+// searchParams lit les paramètres q (obligatoire), lang et limit d'une
+// recherche. Répond 400 et renvoie false s'ils sont invalides.
+func searchParams(w http.ResponseWriter, r *http.Request, defaultLimit int) (string, string, int, bool) {
+	if r.Method != http.MethodGet {
+		respondWrongMethod(w, r, http.MethodGet)
+		return "", "", 0, false
+	}
+	query := r.URL.Query()
+	q := query.Get("q")
+	if q == "" {
+		respond(w, r, http.StatusBadRequest, "api.query_missing")
+		return "", "", 0, false
+	}
+	lang, ok := listLang(w, r)
+	if !ok {
+		return "", "", 0, false
+	}
+	limit := defaultLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			respond(w, r, http.StatusBadRequest, "api.invalid_limit")
+			return "", "", 0, false
+		}
+	}
+	return q, lang, limit, true
+}
+
+// ApiSearchHandler cherche q dans les mots et les définitions, formes fléchies
+// comprises, parmi les entrées portant l'étiquette tag si elle est donnée :
+// GET /api/words/search?q=langages&lang=fr&tag=informatique&limit=20.
+func (s *Server) ApiSearchHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+		q, lang, limit, ok := searchParams(w, r, defaultSearchLimit)
+		if !ok {
+			return
+		}
+
+		hits, err := d.Search(r.Context(), q, lang, r.URL.Query().Get("tag"), limit)
+		if errors.Is(err, interfaces.ErrInvalidTag) {
+			respond(w, r, http.StatusBadRequest, "api.invalid_tag", err)
+			return
+		}
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.search_failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, searchResponse{Query: q, Results: hits})
+	}
+}
+
+// ApiSuggestHandler propose les entrées proches de q : le mot lui-même, une
+// autre forme du même lemme ou un mot qui commence par q.
+func (s *Server) ApiSuggestHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+		q, lang, limit, ok := searchParams(w, r, defaultSuggestLimit)
+		if !ok {
+			return
+		}
+
+		suggestions, err := d.Suggest(r.Context(), q, lang, limit)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.search_failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, suggestResponse{Query: q, Suggestions: suggestions})
+	}
+}
+
+// ApiSoundsLikeHandler renvoie les entrées qui se prononcent comme q :
+// GET /api/words/sounds-like?q=fotografie&lang=fr&limit=20.
+func (s *Server) ApiSoundsLikeHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+		q, lang, limit, ok := searchParams(w, r, defaultSearchLimit)
+		if !ok {
+			return
+		}
+
+		words, err := d.SoundsLike(r.Context(), q, lang, limit)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.search_failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, wordsResponse{Query: q, Results: words})
+	}
+}
+
+// nearEntries renvoie les suggestions de Suggest suivies des entrées qui se
+// prononcent comme word, sans doublons, au plus defaultSuggestLimit.
+func nearEntries(r *http.Request, d *dictionary.Dictionary, word, lang string) ([]interfaces.EntryRef, error) {
+	suggestions, err := d.Suggest(r.Context(), word, lang, defaultSuggestLimit)
+	if err != nil {
+		return nil, err
+	}
+	sounds, err := d.SoundsLike(r.Context(), word, lang, defaultSuggestLimit)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[interfaces.EntryRef]bool, len(suggestions))
+	for _, ref := range suggestions {
+		seen[ref] = true
+	}
+	for _, w := range sounds {
+		if ref := (interfaces.EntryRef{Word: w.Word, Lang: w.Lang}); !seen[ref] && len(suggestions) < defaultSuggestLimit {
+			seen[ref] = true
+			suggestions = append(suggestions, ref)
+		}
+	}
+	return suggestions, nil
+}
+
+// respondLookupError répond comme respondWordError ; un mot introuvable est
+// renvoyé en JSON avec les entrées proches par l'orthographe ou la
+// prononciation (voir nearEntries) dans la langue lang, toutes si elle est vide.
+func respondLookupError(w http.ResponseWriter, r *http.Request, d *dictionary.Dictionary, word, lang string, err error) {
+	if !errors.Is(err, interfaces.ErrWordNotFound) {
+		respondWordError(w, r, "api.read_word_failed", err)
+		return
+	}
+	suggestions, suggestErr := nearEntries(r, d, word, lang)
+	if suggestErr != nil {
+		slog.WarnContext(r.Context(), "suggestions indisponibles", "word", word, "error", suggestErr)
+		suggestions = []interfaces.EntryRef{}
+	}
+	slog.Log(r.Context(), levelForStatus(http.StatusNotFound), i18n.T("api.read_word_failed", err), "route", r.URL.Path, "status", http.StatusNotFound)
+	writeJSON(w, http.StatusNotFound, notFoundResponse{Error: printer(r).T("api.read_word_failed", err), Suggestions: suggestions})
+}
@@ -0,0 +1,44 @@
+package api_mode
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+	"tp2/config"
+	"tp2/i18n"
+	"tp2/interfaces"
+)
+
+// Server porte la configuration des handlers de l'API, chargée une fois au
+// démarrage et transmise à NewServer : chaque serveur a ses propres réglages
+// et son propre stockage des clés d'idempotence.
+type Server struct {
+	auth              config.AuthConfig
+	backup            config.BackupConfig
+	locale            string
+	idempotency       interfaces.IdempotencyRepository
+	idempotencyWindow time.Duration
+	purgeMu           sync.Mutex // Protège lastPurge
+	lastPurge         time.Time  // Dernière purge des clés d'idempotence expirées
+}
+
+func NewServer(cfg config.Config) *Server {
+	return &Server{
+		auth:              cfg.Auth,
+		backup:            cfg.Backup,
+		locale:            i18n.Resolve(cfg.UI.Locale),
+		idempotency:       newMemoryIdempotencyStore(),
+		idempotencyWindow: cfg.Server.IdempotencyWindow,
+	}
+}
+
+type localeKey struct{}
+
+// Localize fait répondre les handlers dans la langue de ui.locale lorsque
+// l'en-tête Accept-Language n'en demande aucune disponible.
+func (s *Server) Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localeKey{}, s.locale)))
+	})
+}
@@ -0,0 +1,176 @@
+package api_mode
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"tp2/dictionary"
+	"tp2/i18n"
+	"tp2/interfaces"
+)
+
+const defaultDeliveryLimit = 100
+
+var webhookEventTypes = map[string]bool{
+	dictionary.EventWordAdded:   true,
+	dictionary.EventWordUpdated: true,
+	dictionary.EventWordRemoved: true,
+}
+
+type webhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+// ApiWebhooksHandler liste (GET) ou crée (POST) les abonnements aux webhooks.
+// Le secret n'est renvoyé qu'à la création ; il est généré s'il n'est pas fourni.
+// Réservé aux administrateurs.
+func (s *Server) ApiWebhooksHandler(repo interfaces.WebhookRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateAdmin(w, r) {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			webhooks, err := repo.ListWebhooks(r.Context())
+			if err != nil {
+				respond(w, r, http.StatusInternalServerError, "api.read_webhooks_failed", err)
+				return
+			}
+			for i := range webhooks {
+				webhooks[i].Secret = ""
+			}
+			writeJSON(w, http.StatusOK, webhooks)
+		case http.MethodPost:
+			var req webhookRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				respond(w, r, http.StatusBadRequest, "api.decode_body", err, r.URL.Path)
+				return
+			}
+			if err := validateWebhook(req); err != nil {
+				respond(w, r, http.StatusBadRequest, "api.validation_error", err)
+				return
+			}
+			if req.Secret == "" {
+				secret, err := newWebhookSecret()
+				if err != nil {
+					respond(w, r, http.StatusInternalServerError, "api.create_webhook_failed", err)
+					return
+				}
+				req.Secret = secret
+			}
+
+			webhook, err := repo.CreateWebhook(r.Context(), interfaces.Webhook{URL: req.URL, Secret: req.Secret, EventTypes: req.EventTypes})
+			if err != nil {
+				respond(w, r, http.StatusInternalServerError, "api.create_webhook_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusCreated, webhook)
+		default:
+			respondWrongMethod(w, r, "GET, POST")
+		}
+	}
+}
+
+// ApiDeleteWebhookHandler supprime l'abonnement /api/webhooks/{id}. Réservé aux administrateurs.
+func (s *Server) ApiDeleteWebhookHandler(repo interfaces.WebhookRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateAdmin(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodDelete {
+			respondWrongMethod(w, r, http.MethodDelete)
+			return
+		}
+
+		id, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/api/webhooks/"), 10, 64)
+		if err != nil {
+			respond(w, r, http.StatusBadRequest, "api.invalid_webhook_id", r.URL.Path)
+			return
+		}
+
+		err = repo.DeleteWebhook(r.Context(), uint(id))
+		if errors.Is(err, interfaces.ErrWebhookNotFound) {
+			respond(w, r, http.StatusNotFound, "api.webhook_not_found", id)
+			return
+		}
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.delete_webhook_failed", err)
+			return
+		}
+
+		respond(w, r, http.StatusOK, "api.webhook_deleted", id)
+	}
+}
+
+// ApiWebhookDeliveriesHandler renvoie le journal des livraisons, filtrable par
+// webhook_id, status (delivered, failed, dead pour les lettres mortes) et limit.
+// Réservé aux administrateurs.
+func (s *Server) ApiWebhookDeliveriesHandler(repo interfaces.WebhookRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateAdmin(w, r) {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			respondWrongMethod(w, r, http.MethodGet)
+			return
+		}
+
+		query := r.URL.Query()
+		filter := interfaces.DeliveryFilter{Status: query.Get("status"), Limit: defaultDeliveryLimit}
+		if id := query.Get("webhook_id"); id != "" {
+			webhookID, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				respond(w, r, http.StatusBadRequest, "api.invalid_webhook_id_param")
+				return
+			}
+			filter.WebhookID = uint(webhookID)
+		}
+		if limit := query.Get("limit"); limit != "" {
+			var err error
+			if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+				respond(w, r, http.StatusBadRequest, "api.invalid_limit")
+				return
+			}
+		}
+
+		deliveries, err := repo.ListDeliveries(r.Context(), filter)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.read_deliveries_failed", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, deliveries)
+	}
+}
+
+func validateWebhook(req webhookRequest) error {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return i18n.Errorf("api.webhook_url_invalid")
+	}
+	for _, eventType := range req.EventTypes {
+		if !webhookEventTypes[eventType] {
+			return i18n.Errorf("api.webhook_event_unknown", eventType)
+		}
+	}
+	return nil
+}
+
+// newWebhookSecret tire un secret de signature aléatoire de 32 octets.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,177 @@
+package api_mode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"tp2/dictionary"
+	"tp2/graph"
+	"tp2/interfaces"
+)
+
+type translationRequest struct {
+	Word string `json:"word"`
+	Lang string `json:"lang"`
+}
+
+type tagRequest struct {
+	Tag string `json:"tag"`
+}
+
+type relationRequest struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// ApiWordHandler répond aux routes /api/words/{mot}... qui ne sont pas
+// enregistrées ailleurs. La langue de l'entrée est choisie comme pour les
+// autres routes (voir entryLang) ; sans paramètre lang, le détail cherche aussi
+// le mot dans les autres langues.
+//
+//	GET    /api/words/{mot}                          détail de l'entrée, de ses relations, étiquettes et traductions ;
+//	                                                 404 avec {"error", "suggestions"} si le mot est introuvable
+//	GET    /api/words/{mot}/relations                relations du mot
+//	POST   /api/words/{mot}/relations                ajoute une relation {"type", "target"}
+//	DELETE /api/words/{mot}/relations/{type}/{cible} supprime une relation
+//	GET    /api/words/{mot}/tags                     étiquettes du mot
+//	POST   /api/words/{mot}/tags                     étiquette le mot {"tag"}
+//	DELETE /api/words/{mot}/tags/{étiquette}         retire une étiquette
+//	GET    /api/words/{mot}/translations             traductions de l'entrée
+//	POST   /api/words/{mot}/translations             ajoute une traduction {"word", "lang"}
+//	DELETE /api/words/{mot}/translations/{langue}/{mot traduit} supprime une traduction
+//	GET    /api/words/{mot}/graph                    parcours du graphe des relations
+func (s *Server) ApiWordHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/words/"), "/")
+		word := parts[0]
+		if word == "" {
+			respond(w, r, http.StatusBadRequest, "api.word_missing_in_url")
+			return
+		}
+
+		lang, ok := entryLang(w, r, r.URL.Query().Get("lang"))
+		if !ok {
+			return
+		}
+
+		switch {
+		case len(parts) == 1 && r.Method == http.MethodGet:
+			var (
+				detail dictionary.WordDetail
+				err    error
+			)
+			suggestLang := ""
+			if r.URL.Query().Has("lang") {
+				suggestLang = lang
+				detail, err = d.Detail(r.Context(), word, lang)
+			} else {
+				detail, err = d.Lookup(r.Context(), word, acceptedLangs(r.Header.Get("Accept-Language")))
+			}
+			if err != nil {
+				respondLookupError(w, r, d, word, suggestLang, err)
+				return
+			}
+			w.Header().Set("Content-Language", detail.Lang)
+			writeJSON(w, http.StatusOK, detail)
+		case len(parts) == 2 && parts[1] == "translations" && r.Method == http.MethodGet:
+			translations, err := d.Translations(r.Context(), word, lang)
+			if err != nil {
+				respondWordError(w, r, "api.read_translations_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, translations)
+		case len(parts) == 2 && parts[1] == "translations" && r.Method == http.MethodPost:
+			var req translationRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Word == "" || req.Lang == "" {
+				respond(w, r, http.StatusBadRequest, "api.translation_keys_expected", r.URL.Path)
+				return
+			}
+			if err := d.AddTranslation(r.Context(), word, lang, interfaces.EntryRef(req)); err != nil {
+				respondWordError(w, r, "api.add_translation_failed", err)
+				return
+			}
+			respond(w, r, http.StatusCreated, "api.translation_added", req.Word, req.Lang, word)
+		case len(parts) == 4 && parts[1] == "translations" && r.Method == http.MethodDelete:
+			if err := d.RemoveTranslation(r.Context(), word, lang, interfaces.EntryRef{Word: parts[3], Lang: parts[2]}); err != nil {
+				respondWordError(w, r, "api.remove_translation_failed", err)
+				return
+			}
+			respond(w, r, http.StatusOK, "api.translation_removed", parts[3], parts[2], word)
+		case len(parts) == 2 && parts[1] == "relations" && r.Method == http.MethodGet:
+			relations, err := d.Relations(r.Context(), word, lang)
+			if err != nil {
+				respondWordError(w, r, "api.read_relations_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, relations)
+		case len(parts) == 2 && parts[1] == "relations" && r.Method == http.MethodPost:
+			var req relationRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type == "" || req.Target == "" {
+				respond(w, r, http.StatusBadRequest, "api.relation_keys_expected", r.URL.Path)
+				return
+			}
+			if err := d.AddRelation(r.Context(), word, lang, req.Type, req.Target); err != nil {
+				respondWordError(w, r, "api.add_relation_failed", err)
+				return
+			}
+			respond(w, r, http.StatusCreated, "api.relation_added", req.Type, word, req.Target)
+		case len(parts) == 2 && parts[1] == "tags" && r.Method == http.MethodGet:
+			tags, err := d.Tags(r.Context(), word, lang)
+			if err != nil {
+				respondWordError(w, r, "app.tags_failed", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, tags)
+		case len(parts) == 2 && parts[1] == "tags" && r.Method == http.MethodPost:
+			var req tagRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Tag == "" {
+				respond(w, r, http.StatusBadRequest, "api.tag_key_expected", r.URL.Path)
+				return
+			}
+			if err := d.TagWord(r.Context(), word, lang, req.Tag); err != nil {
+				respondWordError(w, r, "api.tag_failed", err)
+				return
+			}
+			respond(w, r, http.StatusOK, "app.word_tagged", word, req.Tag)
+		case len(parts) == 3 && parts[1] == "tags" && r.Method == http.MethodDelete:
+			if err := d.UntagWord(r.Context(), word, lang, parts[2]); err != nil {
+				respondWordError(w, r, "api.untag_failed", err)
+				return
+			}
+			respond(w, r, http.StatusOK, "app.word_untagged", parts[2], word)
+		case len(parts) == 2 && parts[1] == "graph" && r.Method == http.MethodGet:
+			serveGraph(w, r, d, word, lang)
+		case len(parts) == 4 && parts[1] == "relations" && r.Method == http.MethodDelete:
+			if err := d.RemoveRelation(r.Context(), word, lang, parts[2], parts[3]); err != nil {
+				respondWordError(w, r, "api.remove_relation_failed", err)
+				return
+			}
+			respond(w, r, http.StatusOK, "api.relation_removed", parts[2], word, parts[3])
+		default:
+			respond(w, r, http.StatusNotFound, "api.unknown_route", r.Method, r.URL.Path)
+		}
+	}
+}
+
+// respondWordError choisit le statut HTTP correspondant à une erreur du dépôt
+// et la renvoie dans le message key, qui la reçoit en argument.
+func respondWordError(w http.ResponseWriter, r *http.Request, key string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, interfaces.ErrWordNotFound), errors.Is(err, interfaces.ErrRelationNotFound), errors.Is(err, graph.ErrNoPath),
+		errors.Is(err, interfaces.ErrTagNotFound), errors.Is(err, interfaces.ErrCollectionNotFound), errors.Is(err, interfaces.ErrTranslationNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, interfaces.ErrRelationExists), errors.Is(err, interfaces.ErrWordExists), errors.Is(err, interfaces.ErrCollectionExists),
+		errors.Is(err, interfaces.ErrTranslationExists):
+		status = http.StatusConflict
+	case errors.Is(err, interfaces.ErrInvalidRelation), errors.Is(err, interfaces.ErrInvalidTag), errors.Is(err, interfaces.ErrInvalidCollection),
+		errors.Is(err, interfaces.ErrInvalidTranslation), errors.Is(err, interfaces.ErrInvalidLang):
+		status = http.StatusBadRequest
+	}
+	respond(w, r, status, key, err)
+}
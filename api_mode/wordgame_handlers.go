@@ -0,0 +1,53 @@
+package api_mode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"tp2/dictionary"
+	"tp2/interfaces"
+)
+
+// wordGameHandler répond à une recherche pour les jeux de lettres : q est un
+// motif ou une liste de lettres, passé à find avec lang et limit.
+func (s *Server) wordGameHandler(find func(ctx context.Context, q, lang string, limit int) ([]interfaces.Word, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authenticateRequest(w, r) {
+			return
+		}
+		q, lang, limit, ok := searchParams(w, r, defaultSearchLimit)
+		if !ok {
+			return
+		}
+
+		words, err := find(r.Context(), q, lang, limit)
+		if errors.Is(err, interfaces.ErrInvalidPattern) {
+			respond(w, r, http.StatusBadRequest, "api.invalid_pattern", err)
+			return
+		}
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, "api.search_failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, wordsResponse{Query: q, Results: words})
+	}
+}
+
+// ApiMatchHandler renvoie les mots qui suivent un motif de mots croisés, où
+// « ? » remplace une lettre et « * » zéro ou plusieurs :
+// GET /api/words/match?q=s?mf*&lang=fr&limit=20.
+func (s *Server) ApiMatchHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return s.wordGameHandler(d.MatchWords)
+}
+
+// ApiAnagramsHandler renvoie les mots formés exactement des lettres de q :
+// GET /api/words/anagrams?q=chien&lang=fr.
+func (s *Server) ApiAnagramsHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return s.wordGameHandler(d.Anagrams)
+}
+
+// ApiContainingHandler renvoie les mots qui contiennent toutes les lettres de
+// q, autant de fois qu'elles y figurent : GET /api/words/containing?q=zq.
+func (s *Server) ApiContainingHandler(d *dictionary.Dictionary) http.HandlerFunc {
+	return s.wordGameHandler(d.WordsContaining)
+}
@@ -0,0 +1,77 @@
+package cli_mode
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+	"tp2/config"
+	"tp2/db"
+	"tp2/i18n"
+)
+
+// RunBackup prend une sauvegarde cohérente de la base, y compris pendant que le serveur tourne.
+//
+//	go run main.go backup [-o fichier.db]
+func RunBackup(cfg config.Config, args []string) error {
+	if cfg.Storage.Driver != db.DriverSQLite {
+		return i18n.Errorf("cli.backup.sqlite_only", db.DriverSQLite, cfg.Storage.Driver)
+	}
+
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := fs.String("o", "", "fichier de destination (par défaut un fichier horodaté dans backup.dir)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dest := *output
+	if dest == "" {
+		dest = db.BackupFileName(cfg.Backup.Dir, time.Now())
+	}
+
+	// La base est sauvegardée telle quelle : ni création ni migration.
+	conn, err := db.OpenSQLiteReadOnly(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := conn.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	repo := &db.GormWordRepository{DB: conn}
+	if err := repo.Backup(context.Background(), dest); err != nil {
+		return err
+	}
+	if *output == "" {
+		if err := db.PruneBackups(cfg.Backup.Dir, cfg.Backup.Retention); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(i18n.T("cli.backup.created", dest))
+	return nil
+}
+
+// RunRestore remplace la base par une sauvegarde après en avoir vérifié
+// l'intégrité et le schéma. Le serveur doit être arrêté.
+//
+//	go run main.go restore fichier.db
+func RunRestore(cfg config.Config, args []string) error {
+	if cfg.Storage.Driver != db.DriverSQLite {
+		return i18n.Errorf("cli.restore.sqlite_only", db.DriverSQLite, cfg.Storage.Driver)
+	}
+	if len(args) != 1 {
+		return i18n.Errorf("cli.restore.usage")
+	}
+
+	previous, err := db.Restore(args[0], cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(i18n.T("cli.restore.done", cfg.Database.Path, args[0]))
+	if previous != "" {
+		fmt.Println(i18n.T("cli.restore.previous_kept", previous))
+	}
+	return nil
+}
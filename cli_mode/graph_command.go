@@ -0,0 +1,132 @@
+package cli_mode
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"tp2/config"
+	"tp2/db"
+	"tp2/graph"
+	"tp2/i18n"
+	"tp2/interfaces"
+)
+
+// RunGraph explore et exporte le graphe des relations entre les mots d'une
+// langue, DefaultLang sans -lang.
+//
+//	go run main.go graph [-lang code] export [-format dot|graphml] [-o fichier] [-word mot -depth n] [-tag a,b]
+//	go run main.go graph [-lang code] path <mot> <cible>
+//	go run main.go graph [-lang code] components
+func RunGraph(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	lang := fs.String("lang", interfaces.DefaultLang, "langue des mots du graphe")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		return i18n.Errorf("cli.graph.usage")
+	}
+	normalized, err := interfaces.NormalizeLang(*lang)
+	if err != nil {
+		return err
+	}
+
+	repo, err := db.NewWordRepository(cfg.Storage.Driver)
+	if err != nil {
+		return err
+	}
+	if err := repo.InitializeDB(cfg.Database.Path); err != nil {
+		return err
+	}
+	defer repo.CloseDB()
+
+	g, err := graph.Load(context.Background(), repo, normalized)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "export":
+		return exportGraph(context.Background(), repo, g, args[1:])
+	case "path":
+		if len(args) != 3 {
+			return i18n.Errorf("cli.graph.path_usage")
+		}
+		path, err := g.ShortestPath(args[1], args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Print(path[0].Word)
+		for _, step := range path[1:] {
+			fmt.Printf(" -[%s]-> %s", step.Relation, step.Word)
+		}
+		fmt.Println()
+		return nil
+	case "components":
+		for i, component := range g.Components() {
+			fmt.Println(i18n.N("cli.graph.component", len(component), i+1, len(component), strings.Join(component, ", ")))
+		}
+		return nil
+	default:
+		return i18n.Errorf("cli.graph.unknown_subcommand", args[0])
+	}
+}
+
+func exportGraph(ctx context.Context, repo interfaces.WordRepository, g *graph.Graph, args []string) error {
+	fs := flag.NewFlagSet("graph export", flag.ContinueOnError)
+	format := fs.String("format", "dot", "format d'export : dot ou graphml")
+	output := fs.String("o", "", "fichier de destination (sortie standard par défaut)")
+	word := fs.String("word", "", "n'exporter que le voisinage de ce mot")
+	depth := fs.Int("depth", 1, "profondeur du voisinage avec -word")
+	tags := fs.String("tag", "", "n'exporter que les mots portant toutes ces étiquettes (séparées par des virgules)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *tags != "" {
+		words, err := repo.ListWordsByTags(ctx, strings.Split(*tags, ","))
+		if err != nil {
+			return err
+		}
+		headwords := make([]string, len(words))
+		for i, w := range words {
+			headwords[i] = w.Word
+		}
+		g = g.Subgraph(headwords)
+	}
+
+	if *word != "" {
+		neighbours, err := g.Neighbourhood(*word, *depth)
+		if err != nil {
+			return err
+		}
+		words := []string{*word}
+		for _, n := range neighbours {
+			words = append(words, n.Word)
+		}
+		g = g.Subgraph(words)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch *format {
+	case "dot":
+		return g.WriteDOT(w)
+	case "graphml":
+		return g.WriteGraphML(w)
+	default:
+		return i18n.Errorf("cli.graph.unknown_format", *format)
+	}
+}
@@ -0,0 +1,125 @@
+package cli_mode
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"tp2/config"
+	"tp2/db"
+	"tp2/i18n"
+	"tp2/interfaces"
+	"tp2/lint"
+)
+
+// RunLint analyse le dictionnaire et affiche ses anomalies avec une correction suggérée.
+//
+//	go run main.go lint [-format text|json] [-o fichier] [-file dictionary.csv] [-prune-orphans]
+func RunLint(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	format := fs.String("format", "text", "format du rapport : text ou json")
+	output := fs.String("o", "", "fichier de destination (sortie standard par défaut)")
+	file := fs.String("file", "", "analyser ce fichier CSV (mot,définition) plutôt que le stockage")
+	prune := fs.Bool("prune-orphans", false, "supprimer les relations orphelines avant l'analyse")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return i18n.Errorf("cli.lint.unknown_format", *format)
+	}
+
+	opts := lint.OptionsFrom(cfg.Validation)
+	var report lint.Report
+	if *file != "" {
+		if *prune {
+			return i18n.Errorf("cli.lint.prune_needs_storage")
+		}
+		words, err := readCSVWords(*file)
+		if err != nil {
+			return err
+		}
+		report = lint.Check(words, opts)
+	} else {
+		repo, err := openLintRepository(cfg, *prune)
+		if err != nil {
+			return err
+		}
+		defer repo.CloseDB()
+
+		ctx := context.Background()
+		if *prune {
+			integrity, ok := repo.(interfaces.IntegrityRepository)
+			if !ok {
+				return i18n.Errorf("cli.lint.prune_unsupported", cfg.Storage.Driver)
+			}
+			deleted, err := integrity.DeleteOrphanRelations(ctx)
+			if err != nil {
+				return err
+			}
+			// Sur la sortie d'erreur pour ne pas mêler ce message au rapport JSON.
+			fmt.Fprintln(os.Stderr, i18n.N("cli.lint.orphans_deleted", deleted, deleted))
+		}
+		if report, err = lint.Run(ctx, repo, opts); err != nil {
+			return err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	p := i18n.For(i18n.Default())
+	if *format == "json" {
+		return report.WriteJSON(w, p)
+	}
+	return report.WriteText(w, p)
+}
+
+// openLintRepository ouvre le stockage sans appliquer de migration : l'analyse
+// ne modifie pas le schéma. La base SQLite n'est ouverte en écriture que pour
+// supprimer les relations orphelines.
+func openLintRepository(cfg config.Config, write bool) (interfaces.WordRepository, error) {
+	if cfg.Storage.Driver == db.DriverSQLite {
+		return db.OpenGormRepository(cfg.Database.Path, !write)
+	}
+	repo, err := db.NewWordRepository(cfg.Storage.Driver)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.InitializeDB(cfg.Database.Path); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// readCSVWords lit un fichier mot,définition comme dictionary.csv ; ses entrées sont en DefaultLang.
+func readCSVWords(name string) ([]interfaces.Word, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var words []interfaces.Word
+	for _, record := range records {
+		if len(record) == 2 {
+			words = append(words, interfaces.Word{Word: record[0], Lang: interfaces.DefaultLang, Definition: record[1]})
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,47 @@
+package cli_mode
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+	"tp2/config"
+	"tp2/logging"
+)
+
+// RunLogs affiche les logs du dossier logs, filtrés par niveau, route ou utilisateur.
+//
+//	go run main.go logs [-dir logs] [-level warn] [-route /api/words] [-user nabil] [-n 50] [-f]
+func RunLogs(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	dir := fs.String("dir", cfg.Log.Dir, "dossier des fichiers de log")
+	level := fs.String("level", "", "niveau minimal (debug, info, warn, error)")
+	route := fs.String("route", "", "préfixe de la route de la requête")
+	user := fs.String("user", "", "utilisateur authentifié")
+	n := fs.Int("n", 100, "nombre de lignes à afficher (0 pour toutes)")
+	follow := fs.Bool("f", false, "suivre les nouvelles lignes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter := logging.Filter{Route: *route, User: *user}
+	if *level != "" {
+		minLevel, err := logging.ParseLevel(*level)
+		if err != nil {
+			return err
+		}
+		filter.MinLevel = &minLevel
+	}
+
+	if err := logging.Tail(*dir, filter, *n, os.Stdout); err != nil {
+		return err
+	}
+	if !*follow {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return logging.Follow(ctx, *dir, filter, 500*time.Millisecond, os.Stdout)
+}
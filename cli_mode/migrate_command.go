@@ -0,0 +1,90 @@
+package cli_mode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"tp2/config"
+	"tp2/db"
+	"tp2/i18n"
+)
+
+// RunMigrate gère le schéma de la base SQLite.
+//
+//	go run main.go migrate up [n]
+//	go run main.go migrate down [n]
+//	go run main.go migrate status
+func RunMigrate(cfg config.Config, args []string) error {
+	if cfg.Storage.Driver != db.DriverSQLite {
+		return i18n.Errorf("cli.migrate.sqlite_only", db.DriverSQLite, cfg.Storage.Driver)
+	}
+	if len(args) == 0 {
+		return i18n.Errorf("cli.migrate.usage")
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return i18n.Errorf("cli.migrate.invalid_steps", args[1])
+		}
+		steps = n
+	}
+
+	gormDB, err := db.OpenSQLite(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := gormDB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx, steps)
+		for _, m := range applied {
+			fmt.Println(i18n.T("cli.migrate.applied", m.Version, m.Name))
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println(i18n.T("cli.migrate.none_pending"))
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Println(i18n.T("cli.migrate.reverted", m.Version, m.Name))
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println(i18n.T("cli.migrate.none_to_revert"))
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("cli.migrate.status_header"))
+		for _, s := range statuses {
+			state, appliedAt := i18n.T("cli.migrate.state_pending"), ""
+			if s.Applied {
+				state, appliedAt = i18n.T("cli.migrate.state_applied"), s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Version > migrator.LatestVersion() {
+				state = i18n.T("cli.migrate.state_unknown")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return i18n.Errorf("cli.migrate.unknown_subcommand", args[0])
+	}
+}
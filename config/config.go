@@ -0,0 +1,435 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"tp2/i18n"
+	"tp2/logging"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "config.yaml"
+
+// Config regroupe tous les réglages de l'application. Ils sont chargés dans
+// l'ordre : valeurs par défaut, fichier YAML, variables d'environnement
+// (y compris .env) puis options de la ligne de commande.
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Dictionary DictionaryConfig `yaml:"dictionary"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Log        LogConfig        `yaml:"log"`
+	Backup     BackupConfig     `yaml:"backup"`
+	Webhooks   WebhooksConfig   `yaml:"webhooks"`
+	Validation ValidationConfig `yaml:"validation"`
+	UI         UIConfig         `yaml:"ui"`
+}
+
+// ServerConfig règle le serveur HTTP. IdempotencyWindow est la durée pendant
+// laquelle une requête rejouée avec le même en-tête Idempotency-Key reçoit la réponse d'origine.
+type ServerConfig struct {
+	Port              string        `yaml:"port"`
+	IdempotencyWindow time.Duration `yaml:"idempotency_window"`
+}
+
+type DatabaseConfig struct {
+	Path string `yaml:"path"`
+}
+
+// StorageConfig choisit l'implémentation de interfaces.WordRepository.
+// database.path désigne alors la base SQLite, le fichier JSON ou la base bbolt.
+type StorageConfig struct {
+	Driver string `yaml:"driver"` // sqlite, memory, json ou bolt
+}
+
+type DictionaryConfig struct {
+	File string `yaml:"file"`
+}
+
+type AuthConfig struct {
+	SecretKey  string            `yaml:"secret_key"`
+	Users      map[string]string `yaml:"users"`
+	AdminUsers []string          `yaml:"admin_users"`
+}
+
+type LogConfig struct {
+	Level         string   `yaml:"level"`
+	Outputs       []string `yaml:"outputs"`
+	Dir           string   `yaml:"dir"`
+	MaxSizeMB     int      `yaml:"max_size_mb"`
+	RetentionDays int      `yaml:"retention_days"`
+	Compress      bool     `yaml:"compress"`
+}
+
+// BackupConfig règle les sauvegardes de la base SQLite. Interval à 0 désactive
+// les sauvegardes planifiées ; Retention est le nombre de sauvegardes conservées.
+type BackupConfig struct {
+	Dir       string        `yaml:"dir"`
+	Interval  time.Duration `yaml:"interval"`
+	Retention int           `yaml:"retention"`
+}
+
+// WebhooksConfig règle la livraison des webhooks : une livraison est
+// abandonnée après MaxAttempts tentatives espacées de InitialBackoff, doublé à
+// chaque échec jusqu'à MaxBackoff.
+type WebhooksConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+// UIConfig choisit la langue des messages de la console et de l'API (fr ou en).
+// Vide, elle est déduite de LC_ALL, LC_MESSAGES ou LANG ; l'API suit en plus
+// l'en-tête Accept-Language de chaque requête.
+type UIConfig struct {
+	Locale string `yaml:"locale"`
+}
+
+// ValidationConfig règle les contrôles des entrées (voir dictionary.Validator).
+// Les longueurs sont comptées en caractères. Charsets et ForbiddenWords sont
+// indexés par code de langue, "*" valant pour les langues non citées ;
+// RequiredFields est indexé par nature grammaticale, "*" valant pour les
+// entrées qui n'en précisent pas.
+type ValidationConfig struct {
+	MinWordLength       int                 `yaml:"min_word_length"`
+	MaxWordLength       int                 `yaml:"max_word_length"`
+	MinDefinitionLength int                 `yaml:"min_definition_length"`
+	MaxDefinitionLength int                 `yaml:"max_definition_length"`
+	Charsets            map[string][]string `yaml:"charsets"`        // classes Unicode (L, Nd, Latin...) ou caractères autorisés dans les mots
+	ForbiddenWords      map[string][]string `yaml:"forbidden_words"` // comparés sans casse ni accents
+	RequiredFields      map[string][]string `yaml:"required_fields"` // word, definition, part_of_speech
+}
+
+// Default renvoie la configuration utilisée en l'absence de tout réglage.
+func Default() Config {
+	return Config{
+		Server:     ServerConfig{Port: ":8080", IdempotencyWindow: 24 * time.Hour},
+		Database:   DatabaseConfig{Path: "db/database.db"},
+		Storage:    StorageConfig{Driver: "sqlite"},
+		Dictionary: DictionaryConfig{File: "dictionary.csv"},
+		Log: LogConfig{
+			Level:         "info",
+			Outputs:       []string{"stderr"},
+			Dir:           "logs",
+			MaxSizeMB:     100,
+			RetentionDays: 30,
+			Compress:      true,
+		},
+		Backup: BackupConfig{
+			Dir:       "backups",
+			Retention: 7,
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     5 * time.Minute,
+			Timeout:        10 * time.Second,
+		},
+		Validation: ValidationConfig{
+			MinWordLength:       2,
+			MaxWordLength:       30,
+			MinDefinitionLength: 5,
+			MaxDefinitionLength: 255,
+			RequiredFields: map[string][]string{
+				"*":            {"word", "definition"},
+				"nom":          {"word", "definition"},
+				"verbe":        {"word", "definition"},
+				"adjectif":     {"word", "definition"},
+				"adverbe":      {"word", "definition"},
+				"pronom":       {"word", "definition"},
+				"déterminant":  {"word", "definition"},
+				"préposition":  {"word", "definition"},
+				"conjonction":  {"word", "definition"},
+				"interjection": {"word"},
+			},
+		},
+	}
+}
+
+// Load construit la configuration à partir des arguments de la ligne de commande
+// (sans le nom du programme) et renvoie les arguments restants (mode ou sous-commande).
+func Load(args []string) (Config, []string, error) {
+	cfg := Default()
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cfg, nil, fmt.Errorf("lecture du fichier .env : %w", err)
+	}
+
+	fs := flag.NewFlagSet("dico", flag.ContinueOnError)
+	configFile := fs.String("config", "", "fichier de configuration YAML (config.yaml par défaut)")
+	port := fs.String("port", "", "adresse d'écoute du serveur, par exemple :8080")
+	dbPath := fs.String("db", "", "chemin de la base de données")
+	storageDriver := fs.String("storage", "", "pilote de stockage (sqlite, memory, json, bolt)")
+	dictionaryFile := fs.String("dictionary", "", "fichier CSV du dictionnaire")
+	logLevel := fs.String("log-level", "", "niveau de log (debug, info, warn, error)")
+	logOutput := fs.String("log-output", "", "sorties de log séparées par des virgules (stderr, stdout, file)")
+	logDir := fs.String("log-dir", "", "dossier des fichiers de log")
+	locale := fs.String("locale", "", "langue des messages (fr, en)")
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+
+	path, explicit := *configFile, *configFile != ""
+	if !explicit {
+		path, explicit = os.Getenv("DICO_CONFIG"), os.Getenv("DICO_CONFIG") != ""
+	}
+	if !explicit {
+		path = defaultConfigFile
+	}
+	if err := cfg.loadFile(path, explicit); err != nil {
+		return cfg, nil, err
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return cfg, nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "db":
+			cfg.Database.Path = *dbPath
+		case "storage":
+			cfg.Storage.Driver = *storageDriver
+		case "dictionary":
+			cfg.Dictionary.File = *dictionaryFile
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "log-output":
+			cfg.Log.Outputs = splitList(*logOutput)
+		case "log-dir":
+			cfg.Log.Dir = *logDir
+		case "locale":
+			cfg.UI.Locale = *locale
+		}
+	})
+
+	cfg.Server.Port = normalizePort(cfg.Server.Port)
+	return cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("lecture du fichier de configuration : %w", err)
+	}
+	// yaml.Unmarshal complète les maps déjà remplies : on les vide pour que
+	// celles du fichier remplacent les valeurs par défaut au lieu de s'y ajouter.
+	defaults := *c
+	c.Auth.Users = nil
+	c.Validation.Charsets, c.Validation.ForbiddenWords, c.Validation.RequiredFields = nil, nil, nil
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("fichier de configuration %s invalide : %w", path, err)
+	}
+	if c.Auth.Users == nil {
+		c.Auth.Users = defaults.Auth.Users
+	}
+	if c.Validation.Charsets == nil {
+		c.Validation.Charsets = defaults.Validation.Charsets
+	}
+	if c.Validation.ForbiddenWords == nil {
+		c.Validation.ForbiddenWords = defaults.Validation.ForbiddenWords
+	}
+	if c.Validation.RequiredFields == nil {
+		c.Validation.RequiredFields = defaults.Validation.RequiredFields
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString(&c.Server.Port, "PORT")
+	setString(&c.Server.Port, "SERVER_PORT")
+	setString(&c.Database.Path, "DB_PATH")
+	setString(&c.Storage.Driver, "STORAGE_DRIVER")
+	setString(&c.Dictionary.File, "DICTIONARY_FILE")
+	setString(&c.Auth.SecretKey, "SECRET_KEY")
+	if admins := os.Getenv("ADMIN_USERS"); admins != "" {
+		c.Auth.AdminUsers = splitList(admins)
+	}
+	setString(&c.Log.Level, "LOG_LEVEL")
+	if outputs := os.Getenv("LOG_OUTPUT"); outputs != "" {
+		c.Log.Outputs = splitList(outputs)
+	}
+	setString(&c.Log.Dir, "LOG_DIR")
+	setString(&c.Backup.Dir, "BACKUP_DIR")
+	setString(&c.UI.Locale, "DICO_LOCALE")
+
+	for _, v := range []struct {
+		target *time.Duration
+		name   string
+	}{
+		{&c.Server.IdempotencyWindow, "IDEMPOTENCY_WINDOW"},
+		{&c.Backup.Interval, "BACKUP_INTERVAL"},
+	} {
+		if value := os.Getenv(v.name); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s invalide : %q", v.name, value)
+			}
+			*v.target = duration
+		}
+	}
+
+	for _, v := range []struct {
+		target *int
+		name   string
+	}{
+		{&c.Log.MaxSizeMB, "LOG_MAX_SIZE_MB"},
+		{&c.Log.RetentionDays, "LOG_RETENTION_DAYS"},
+		{&c.Backup.Retention, "BACKUP_RETENTION"},
+		{&c.Webhooks.MaxAttempts, "WEBHOOKS_MAX_ATTEMPTS"},
+		{&c.Validation.MinWordLength, "VALIDATION_MIN_WORD_LENGTH"},
+		{&c.Validation.MaxWordLength, "VALIDATION_MAX_WORD_LENGTH"},
+		{&c.Validation.MinDefinitionLength, "VALIDATION_MIN_DEFINITION_LENGTH"},
+		{&c.Validation.MaxDefinitionLength, "VALIDATION_MAX_DEFINITION_LENGTH"},
+	} {
+		if err := setInt(v.target, v.name); err != nil {
+			return err
+		}
+	}
+
+	if value := os.Getenv("LOG_COMPRESS"); value != "" {
+		compress, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("LOG_COMPRESS invalide : %q", value)
+		}
+		c.Log.Compress = compress
+	}
+	return nil
+}
+
+// Validate vérifie la cohérence de la configuration pour le mode demandé.
+func (c Config) Validate(mode string) error {
+	var problems []string
+
+	if c.Server.Port == "" {
+		problems = append(problems, "server.port est vide")
+	}
+	if c.Server.IdempotencyWindow <= 0 {
+		problems = append(problems, "server.idempotency_window doit être positif")
+	}
+	switch c.Storage.Driver {
+	case "sqlite", "json", "bolt":
+		if c.Database.Path == "" {
+			problems = append(problems, "database.path est vide")
+		}
+	case "memory":
+	default:
+		problems = append(problems, fmt.Sprintf("storage.driver inconnu : %q", c.Storage.Driver))
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, output := range c.Log.Outputs {
+		switch output {
+		case "stderr", "stdout", "file":
+		default:
+			problems = append(problems, fmt.Sprintf("sortie de log inconnue : %q", output))
+		}
+	}
+	if c.Log.MaxSizeMB < 0 || c.Log.RetentionDays < 0 {
+		problems = append(problems, "log.max_size_mb et log.retention_days doivent être positifs")
+	}
+
+	if c.Backup.Interval < 0 || c.Backup.Retention < 0 {
+		problems = append(problems, "backup.interval et backup.retention doivent être positifs")
+	}
+	if c.Backup.Interval > 0 && c.Storage.Driver != "sqlite" {
+		problems = append(problems, "les sauvegardes planifiées nécessitent storage.driver sqlite")
+	}
+
+	if c.Webhooks.MaxAttempts < 1 || c.Webhooks.InitialBackoff <= 0 || c.Webhooks.Timeout <= 0 {
+		problems = append(problems, "webhooks.max_attempts, webhooks.initial_backoff et webhooks.timeout doivent être positifs")
+	}
+	if c.Webhooks.MaxBackoff < c.Webhooks.InitialBackoff || c.Webhooks.MaxBackoff <= 0 {
+		problems = append(problems, "webhooks.max_backoff doit être positif et au moins égal à webhooks.initial_backoff")
+	}
+
+	v := c.Validation
+	if v.MinWordLength < 1 || v.MaxWordLength < v.MinWordLength {
+		problems = append(problems, "validation : longueurs de mot incohérentes")
+	}
+	if v.MinDefinitionLength < 1 || v.MaxDefinitionLength < v.MinDefinitionLength {
+		problems = append(problems, "validation : longueurs de définition incohérentes")
+	}
+
+	if c.UI.Locale != "" && !i18n.IsSupported(c.UI.Locale) {
+		problems = append(problems, fmt.Sprintf("ui.locale non prise en charge : %q (%s)", c.UI.Locale, strings.Join(i18n.Supported(), ", ")))
+	}
+
+	if mode == "api" || mode == "2" {
+		if c.Auth.SecretKey == "" {
+			problems = append(problems, "auth.secret_key (SECRET_KEY) est requis en mode API")
+		}
+		if len(c.Auth.Users) == 0 {
+			problems = append(problems, "auth.users est vide : aucun utilisateur ne peut se connecter en mode API")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("configuration invalide : %s", strings.Join(problems, " ; "))
+	}
+	return nil
+}
+
+// LoggingOptions convertit la section log en options du paquet logging.
+func (c Config) LoggingOptions() logging.Options {
+	return logging.Options{
+		Level:         c.Log.Level,
+		Outputs:       c.Log.Outputs,
+		Dir:           c.Log.Dir,
+		MaxSizeMB:     c.Log.MaxSizeMB,
+		RetentionDays: c.Log.RetentionDays,
+		Compress:      c.Log.Compress,
+	}
+}
+
+func setString(target *string, name string) {
+	if value := os.Getenv(name); value != "" {
+		*target = value
+	}
+}
+
+func setInt(target *int, name string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s invalide : %q", name, value)
+	}
+	*target = n
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// normalizePort accepte "8080" comme ":8080".
+func normalizePort(port string) string {
+	if port != "" && !strings.Contains(port, ":") {
+		return ":" + port
+	}
+	return port
+}
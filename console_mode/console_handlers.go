@@ -2,69 +2,240 @@ package console_mode
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"tp2/dictionary"
+	"tp2/i18n"
+	"tp2/interfaces"
+	"tp2/requestctx"
 )
 
-func ActionAddAsync(d *dictionary.Dictionary, reader *bufio.Reader) {
-	fmt.Print("Entrez le nouveau mot : ")
+// consoleContext identifie les modifications faites depuis la console dans le journal d'audit.
+func consoleContext() context.Context {
+	return requestctx.With(context.Background(), &requestctx.Info{User: "console"})
+}
+
+// Les actions reçoivent la langue de travail choisie avec ActionChooseLang :
+// elle filtre les listes et désigne les entrées modifiées. Vide, toutes les
+// langues sont listées et les modifications portent sur DefaultLang (voir entryLang).
+
+// entryLang renvoie la langue des entrées lues ou modifiées pour la langue de travail lang.
+func entryLang(lang string) string {
+	if lang == "" {
+		return interfaces.DefaultLang
+	}
+	return lang
+}
+
+// ActionChooseLang demande la nouvelle langue de travail de la console et la
+// renvoie ; current est gardée si le code saisi est invalide.
+func ActionChooseLang(reader *bufio.Reader, current string) string {
+	fmt.Print(i18n.T("console.prompt_lang"))
+	lang, _ := reader.ReadString('\n')
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		fmt.Println(i18n.T("console.all_langs"))
+		return ""
+	}
+
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		fmt.Println(i18n.T("console.invalid_lang", err))
+		return current
+	}
+	fmt.Println(i18n.T("console.lang_chosen", lang))
+	return lang
+}
+
+func ActionAddAsync(d *dictionary.Dictionary, reader *bufio.Reader, lang string) {
+	fmt.Print(i18n.T("console.prompt_new_word"))
 	word, _ := reader.ReadString('\n')
 	word = strings.TrimSpace(word)
 
-	fmt.Print("Entrez la nouvelle définition : ")
+	fmt.Print(i18n.T("console.prompt_new_definition"))
 	definition, _ := reader.ReadString('\n')
 	definition = strings.TrimSpace(definition)
 
-	d.AddAsync(word, definition)
+	if err := d.AddAsync(consoleContext(), word, entryLang(lang), definition); err != nil {
+		if !printInvalid(err) {
+			fmt.Println(i18n.T("console.add_failed", word, err))
+		}
+		return
+	}
+
+	fmt.Println(i18n.T("app.word_added", word, definition))
+}
 
-	fmt.Printf("Le mot '%s' avec la définition '%s' a été ajouté.\n", word, definition)
+// printInvalid affiche chaque défaut d'une entrée refusée par la validation,
+// et renvoie false si err n'est pas un *dictionary.ValidationError.
+func printInvalid(err error) bool {
+	var invalid *dictionary.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	fmt.Println(i18n.T("console.invalid_entry"))
+	for _, field := range invalid.Fields {
+		fmt.Println(i18n.T("console.field_error", field))
+	}
+	return true
 }
 
-func ActionDefineAsync(d *dictionary.Dictionary, reader *bufio.Reader) {
-	fmt.Print("Entrez le mot : ")
+func ActionDefineAsync(d *dictionary.Dictionary, reader *bufio.Reader, lang string) {
+	fmt.Print(i18n.T("console.prompt_word"))
 	word, _ := reader.ReadString('\n')
 	word = strings.TrimSpace(word)
 
-	fmt.Print("Entrez la nouvelle définition : ")
+	fmt.Print(i18n.T("console.prompt_new_definition"))
 	newDefinition, _ := reader.ReadString('\n')
 	newDefinition = strings.TrimSpace(newDefinition)
 
-	err := d.EditAsync(word, newDefinition)
+	err := d.EditAsync(consoleContext(), word, entryLang(lang), newDefinition)
 	if err != nil {
-		fmt.Printf("Erreur lors de la mise à jour du mot '%s' : %v\n", word, err)
+		if !printInvalid(err) {
+			fmt.Println(i18n.T("console.define_failed", word, err))
+		}
 		return
 	}
 
-	fmt.Printf("La définition pour le mot '%s' a été mise à jour.\n", word)
+	fmt.Println(i18n.T("app.word_defined", word))
 }
 
-func ActionRemoveAsync(d *dictionary.Dictionary, reader *bufio.Reader) {
-	fmt.Print("Écrivez le mot à supprimer : ")
+func ActionRemoveAsync(d *dictionary.Dictionary, reader *bufio.Reader, lang string) {
+	fmt.Print(i18n.T("console.prompt_remove"))
 	word, _ := reader.ReadString('\n')
 	word = strings.TrimSpace(word)
 
-	err := d.RemoveAsync(word)
+	err := d.RemoveAsync(consoleContext(), word, entryLang(lang))
 	if err != nil {
-		fmt.Printf("Erreur lors de la suppression du mot '%s': %v\n", word, err)
+		fmt.Println(i18n.T("console.remove_failed", word, err))
 	} else {
-		fmt.Printf("Le mot '%s' a été supprimé avec succès.\n", word)
+		fmt.Println(i18n.T("console.word_removed", word))
 	}
 }
 
-func ActionList(d *dictionary.Dictionary) {
-	wordsList, err := d.List()
+func ActionList(d *dictionary.Dictionary, lang string) {
+	wordsList, err := d.List(consoleContext())
 	if err != nil {
-		fmt.Printf("Erreur lors de la récupération de la liste des mots : %s\n", err.Error())
+		fmt.Println(i18n.T("app.list_failed", err))
 		return
 	}
+	wordsList = dictionary.FilterLang(wordsList, lang)
 
 	if len(wordsList) == 0 {
-		fmt.Println("Aucun mot dans le dico.")
+		fmt.Println(i18n.T("app.no_words"))
 	} else {
-		fmt.Println("Liste des mots du dico:")
+		fmt.Println(i18n.T("console.list_header"))
 		for _, word := range wordsList {
-			fmt.Println(word.String())
+			ctx := consoleContext()
+			if word.Lang == interfaces.DefaultLang {
+				fmt.Println(word.String())
+			} else {
+				fmt.Printf("[%s] %s\n", word.Lang, word.String())
+			}
+			printRelations(ctx, d, word.Word, word.Lang)
+			if tags, err := d.Tags(ctx, word.Word, word.Lang); err == nil && len(tags) > 0 {
+				fmt.Println(i18n.T("console.tags_line", strings.Join(tags, ", ")))
+			}
+			if translations, err := d.Translations(ctx, word.Word, word.Lang); err == nil && len(translations) > 0 {
+				labels := make([]string, len(translations))
+				for i, t := range translations {
+					labels[i] = fmt.Sprintf("%s (%s)", t.Word, t.Lang)
+				}
+				fmt.Println(i18n.T("console.translations_line", strings.Join(labels, ", ")))
+			}
+		}
+	}
+}
+
+// relationLabels associe chaque type de relation à la clé de son libellé.
+var relationLabels = map[string]string{
+	interfaces.RelationSynonym:  "console.relation.synonym",
+	interfaces.RelationAntonym:  "console.relation.antonym",
+	interfaces.RelationHypernym: "console.relation.hypernym",
+	interfaces.RelationHyponym:  "console.relation.hyponym",
+	interfaces.RelationSeeAlso:  "console.relation.see_also",
+}
+
+// printRelations affiche les relations d'un mot, regroupées par type.
+func printRelations(ctx context.Context, d *dictionary.Dictionary, word, lang string) {
+	relations, err := d.Relations(ctx, word, lang)
+	if err != nil {
+		return
+	}
+
+	var types []string
+	targets := make(map[string][]string)
+	for _, rel := range relations {
+		if _, seen := targets[rel.Type]; !seen {
+			types = append(types, rel.Type)
+		}
+		targets[rel.Type] = append(targets[rel.Type], rel.Target)
+	}
+	for _, relationType := range types {
+		fmt.Println(i18n.T("console.relation_line", i18n.T(relationLabels[relationType]), strings.Join(targets[relationType], ", ")))
+	}
+}
+
+// ActionTag ajoute des étiquettes à un mot ; une étiquette précédée de « - » est retirée.
+func ActionTag(d *dictionary.Dictionary, reader *bufio.Reader, lang string) {
+	fmt.Print(i18n.T("console.prompt_word"))
+	word, _ := reader.ReadString('\n')
+	word = strings.TrimSpace(word)
+
+	fmt.Print(i18n.T("console.prompt_tags"))
+	line, _ := reader.ReadString('\n')
+
+	for _, tag := range strings.Split(line, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
 		}
+		if untag, ok := strings.CutPrefix(tag, "-"); ok {
+			if err := d.UntagWord(consoleContext(), word, entryLang(lang), untag); err != nil {
+				fmt.Println(i18n.T("console.untag_failed", untag, err))
+			} else {
+				fmt.Println(i18n.T("app.word_untagged", untag, word))
+			}
+			continue
+		}
+		if err := d.TagWord(consoleContext(), word, entryLang(lang), tag); err != nil {
+			fmt.Println(i18n.T("console.tag_failed", tag, err))
+		} else {
+			fmt.Println(i18n.T("app.word_tagged", word, tag))
+		}
+	}
+}
+
+// ActionBrowseTags affiche les étiquettes puis les mots portant celles choisies.
+func ActionBrowseTags(d *dictionary.Dictionary, reader *bufio.Reader, lang string) {
+	tags, err := d.AllTags(consoleContext())
+	if err != nil {
+		fmt.Println(i18n.T("app.tags_failed", err))
+		return
+	}
+	if len(tags) == 0 {
+		fmt.Println(i18n.T("console.no_tags"))
+		return
+	}
+	fmt.Println(i18n.T("console.tags_header"))
+	for _, tag := range tags {
+		fmt.Println(i18n.N("console.tag_words", tag.Words, tag.Tag, tag.Words))
+	}
+
+	fmt.Print(i18n.T("console.prompt_browse_tags"))
+	line, _ := reader.ReadString('\n')
+	words, err := d.ListByTags(consoleContext(), strings.Split(strings.TrimSpace(line), ","))
+	if err != nil {
+		fmt.Println(i18n.T("console.browse_failed", err))
+		return
+	}
+	if len(words) == 0 {
+		fmt.Println(i18n.T("console.no_tagged_words"))
+		return
+	}
+	for _, word := range dictionary.FilterLang(words, lang) {
+		fmt.Println(word.String())
 	}
 }
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tp2/interfaces"
+	"tp2/requestctx"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionAdd    = "add"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+var errAuditAppendOnly = errors.New("le journal d'audit est en ajout seul")
+
+// AuditRecord est une ligne de la table audit_entries. Les hooks gorm
+// empêchent toute modification ou suppression après insertion.
+type AuditRecord struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	Username  string    `gorm:"index"`
+	Action    string    `gorm:"not null"`
+	Word      string    `gorm:"index;not null"`
+	Lang      string    `gorm:"index;not null"` // vide pour les entrées antérieures à la colonne
+	Before    string
+	After     string
+	ClientIP  string
+	RequestID string
+}
+
+func (AuditRecord) TableName() string {
+	return "audit_entries"
+}
+
+func (AuditRecord) BeforeUpdate(tx *gorm.DB) error {
+	return errAuditAppendOnly
+}
+
+func (AuditRecord) BeforeDelete(tx *gorm.DB) error {
+	return errAuditAppendOnly
+}
+
+// recordAudit ajoute une entrée d'audit sur le mot de la langue lang dans la
+// transaction tx, avec l'utilisateur, l'adresse IP et l'identifiant de requête
+// du contexte. lang, déjà validée par l'appelant, est enregistrée normalisée.
+func recordAudit(ctx context.Context, tx *gorm.DB, action, word, lang, before, after string) error {
+	if normalized, err := interfaces.NormalizeLang(lang); err == nil {
+		lang = normalized
+	}
+	record := AuditRecord{
+		Action: action,
+		Word:   word,
+		Lang:   lang,
+		Before: before,
+		After:  after,
+	}
+	if info := requestctx.From(ctx); info != nil {
+		record.Username = info.User
+		record.ClientIP = info.ClientIP
+		record.RequestID = info.RequestID
+	}
+	return tx.Create(&record).Error
+}
+
+func (g *GormWordRepository) ListAuditEntries(ctx context.Context, filter interfaces.AuditFilter) ([]interfaces.AuditEntry, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := db.Model(&AuditRecord{}).Order("id DESC")
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Word != "" {
+		query = query.Where("word = ?", filter.Word)
+	}
+	if filter.Lang != "" {
+		query = query.Where("lang = ?", filter.Lang)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var records []AuditRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]interfaces.AuditEntry, len(records))
+	for i, r := range records {
+		entries[i] = interfaces.AuditEntry{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			Username:  r.Username,
+			Action:    r.Action,
+			Word:      r.Word,
+			Lang:      r.Lang,
+			Before:    r.Before,
+			After:     r.After,
+			ClientIP:  r.ClientIP,
+			RequestID: r.RequestID,
+		}
+	}
+	return entries, nil
+}
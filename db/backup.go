@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const backupPrefix = "database_"
+
+// BackupFileName renvoie le nom d'une sauvegarde horodatée dans dir.
+func BackupFileName(dir string, now time.Time) string {
+	return filepath.Join(dir, backupPrefix+now.UTC().Format("20060102T150405.000Z")+".db")
+}
+
+// Backup écrit une copie cohérente de la base dans dest avec VACUUM INTO,
+// sans interrompre les lectures et écritures en cours.
+func (g *GormWordRepository) Backup(ctx context.Context, dest string) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	return backupSQLite(db, dest)
+}
+
+func backupSQLite(db *gorm.DB, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("le fichier de sauvegarde %s existe déjà", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+	return db.Exec("VACUUM INTO ?", dest).Error
+}
+
+// PruneBackups ne conserve que les keep sauvegardes les plus récentes de dir (toutes si keep <= 0).
+func PruneBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(backups)-keep; i++ {
+		if err := os.Remove(backups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListBackups renvoie les sauvegardes de dir, de la plus ancienne à la plus récente.
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), backupPrefix) && strings.HasSuffix(entry.Name(), ".db") {
+			backups = append(backups, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// ScheduleBackups sauvegarde la base toutes les interval dans dir et ne garde
+// que les keep plus récentes, jusqu'à l'annulation de ctx.
+func (g *GormWordRepository) ScheduleBackups(ctx context.Context, dir string, interval time.Duration, keep int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			dest := BackupFileName(dir, now)
+			if err := g.Backup(ctx, dest); err != nil {
+				slog.ErrorContext(ctx, "échec de la sauvegarde planifiée", "file", dest, "error", err)
+				continue
+			}
+			if err := PruneBackups(dir, keep); err != nil {
+				slog.ErrorContext(ctx, "échec de la purge des sauvegardes", "dir", dir, "error", err)
+			}
+			slog.InfoContext(ctx, "sauvegarde planifiée effectuée", "file", dest)
+		}
+	}
+}
+
+// ValidateBackup vérifie qu'un fichier est une base SQLite intègre dont le
+// schéma est connu de ce binaire.
+func ValidateBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	db, err := OpenSQLiteReadOnly(path)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	var integrity string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&integrity).Error; err != nil {
+		return fmt.Errorf("sauvegarde illisible : %w", err)
+	}
+	if integrity != "ok" {
+		return fmt.Errorf("sauvegarde corrompue : %s", integrity)
+	}
+
+	for _, table := range []string{"schema_migrations", "words"} {
+		if !db.Migrator().HasTable(table) {
+			return fmt.Errorf("sauvegarde invalide : table %s absente", table)
+		}
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	migrator := &Migrator{db: db, migrations: migrations}
+	return migrator.CheckCompatible(context.Background())
+}
+
+// Restore remplace la base dbPath par la sauvegarde backupPath après l'avoir
+// validée. La base remplacée est conservée sous dbPath.before-restore-<date>.
+// Le serveur doit être arrêté pendant la restauration.
+func Restore(backupPath, dbPath string) (string, error) {
+	if err := ValidateBackup(backupPath); err != nil {
+		return "", err
+	}
+
+	previous := ""
+	if _, err := os.Stat(dbPath); err == nil {
+		// Le journal WAL de l'ancienne base est reporté dans son fichier avant
+		// la copie ; laissé en place, il serait rejoué sur la base restaurée.
+		if err := checkpoint(dbPath); err != nil {
+			return "", err
+		}
+		previous = fmt.Sprintf("%s.before-restore-%s", dbPath, time.Now().UTC().Format("20060102T150405Z"))
+		if err := copyFile(dbPath, previous); err != nil {
+			return "", err
+		}
+	}
+
+	tmp := dbPath + ".restore.tmp"
+	if err := copyFile(backupPath, tmp); err != nil {
+		return previous, err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return previous, err
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return previous, err
+	}
+	return previous, nil
+}
+
+// checkpoint écrit le contenu du journal WAL de la base dans son fichier principal.
+func checkpoint(dbPath string) error {
+	db, err := OpenSQLite(dbPath)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	return db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+// ApplyBatch exécute chaque opération dans un point de sauvegarde de la même
+// transaction : une opération en échec est annulée seule, sauf en mode atomique
+// où la transaction entière l'est.
+func (g *GormWordRepository) ApplyBatch(ctx context.Context, ops []interfaces.BatchOperation, atomic bool) ([]interfaces.BatchResult, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []interfaces.BatchResult
+	err = db.Transaction(func(tx *gorm.DB) error {
+		results = make([]interfaces.BatchResult, len(ops))
+		failed := false
+		for i, op := range ops {
+			results[i].Err = translateError(tx.Transaction(func(tx *gorm.DB) error {
+				var err error
+				results[i].Entry, err = applyOperation(ctx, tx, op)
+				return err
+			}))
+			failed = failed || results[i].Err != nil
+		}
+		if atomic && failed {
+			return interfaces.ErrBatchRolledBack
+		}
+		return nil
+	})
+	return results, err
+}
+
+func applyOperation(ctx context.Context, tx *gorm.DB, op interfaces.BatchOperation) (interfaces.Word, error) {
+	switch op.Op {
+	case interfaces.BatchAdd:
+		return addWord(ctx, tx, interfaces.Word{Word: op.Word, Lang: op.Lang, Definition: op.Definition, PartOfSpeech: op.PartOfSpeech})
+	case interfaces.BatchDefine:
+		return updateWord(ctx, tx, op.Word, op.Lang, op.Definition)
+	case interfaces.BatchRemove:
+		return deleteWord(ctx, tx, op.Word, op.Lang)
+	default:
+		return interfaces.Word{}, interfaces.ErrUnknownBatchOp
+	}
+}
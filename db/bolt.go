@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltWordRepository stocke les mots dans une base clé/valeur bbolt embarquée :
+// un bucket par table, une clé par ligne et la ligne sérialisée en JSON.
+// Les lectures sont servies depuis la mémoire, chargée à l'ouverture.
+type BoltWordRepository struct {
+	storeRepository
+	db *bolt.DB
+}
+
+func (b *BoltWordRepository) InitializeDB(dbPath string) error {
+	if err := os.MkdirAll(filepath.Dir(dbPath), os.ModePerm); err != nil {
+		return err
+	}
+	db, err := bolt.Open(dbPath, 0666, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	b.db = db
+	s := b.store()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for name, t := range s.tables() {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return err
+			}
+			err = bucket.ForEach(func(k, v []byte) error {
+				return t.load(string(k), v)
+			})
+			if err != nil {
+				return fmt.Errorf("bucket %s invalide : %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+	s.afterLoad()
+
+	s.persist = b.persist
+	if err := s.update(s.rekey); err != nil {
+		db.Close()
+		return err
+	}
+	return nil
+}
+
+// persist écrit les lignes modifiées dans une seule transaction bbolt.
+func (b *BoltWordRepository) persist(changes []change) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, c := range changes {
+			bucket := tx.Bucket([]byte(c.table))
+			value, exists := c.value()
+			if !exists {
+				if err := bucket.Delete([]byte(c.key)); err != nil {
+					return err
+				}
+				continue
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(c.key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltWordRepository) CloseDB() {
+	if b.db != nil {
+		b.db.Close()
+	}
+}
+
+func (b *BoltWordRepository) Ping(ctx context.Context) error {
+	if b.db == nil {
+		return ErrNotInitialized
+	}
+	return b.db.View(func(tx *bolt.Tx) error { return nil })
+}
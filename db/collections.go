@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+type CollectionRecord struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string `gorm:"not null"`
+	Description string
+}
+
+func (CollectionRecord) TableName() string {
+	return "collections"
+}
+
+// CollectionWordRecord place un mot dans une collection ; Position donne l'ordre.
+type CollectionWordRecord struct {
+	CollectionID uint `gorm:"primaryKey"`
+	WordID       uint `gorm:"primaryKey"`
+	Position     int  `gorm:"not null"`
+}
+
+func (CollectionWordRecord) TableName() string {
+	return "collection_words"
+}
+
+func (g *GormWordRepository) CreateCollection(ctx context.Context, collection interfaces.Collection, lang string) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		record := CollectionRecord{Name: collection.Name, Description: collection.Description}
+		if err := tx.Create(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return interfaces.ErrCollectionExists
+			}
+			return err
+		}
+		return setCollectionWords(tx, record.ID, lang, collection.Words)
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) UpdateCollection(ctx context.Context, collection interfaces.Collection, lang string) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		record, err := findCollection(tx, collection.Name)
+		if err != nil {
+			return err
+		}
+		record.Description = collection.Description
+		if err := tx.Save(&record).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", record.ID).Delete(&CollectionWordRecord{}).Error; err != nil {
+			return err
+		}
+		return setCollectionWords(tx, record.ID, lang, collection.Words)
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) DeleteCollection(ctx context.Context, name string) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		record, err := findCollection(tx, name)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", record.ID).Delete(&CollectionWordRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) GetCollection(ctx context.Context, name string) (interfaces.Collection, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return interfaces.Collection{}, err
+	}
+	record, err := findCollection(db, name)
+	if err != nil {
+		return interfaces.Collection{}, err
+	}
+	return loadCollection(db, record)
+}
+
+func (g *GormWordRepository) ListCollections(ctx context.Context) ([]interfaces.Collection, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var records []CollectionRecord
+	if err := db.Order("name").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	collections := make([]interfaces.Collection, len(records))
+	for i, record := range records {
+		if collections[i], err = loadCollection(db, record); err != nil {
+			return nil, err
+		}
+	}
+	return collections, nil
+}
+
+func findCollection(tx *gorm.DB, name string) (CollectionRecord, error) {
+	var record CollectionRecord
+	err := tx.Where("name = ?", name).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return record, interfaces.ErrCollectionNotFound
+	}
+	return record, err
+}
+
+func loadCollection(tx *gorm.DB, record CollectionRecord) (interfaces.Collection, error) {
+	collection := interfaces.Collection{Name: record.Name, Description: record.Description, Words: []string{}}
+	err := tx.Table("collection_words AS cw").
+		Joins("JOIN words w ON w.id = cw.word_id").
+		Where("cw.collection_id = ?", record.ID).
+		Order("cw.position").
+		Pluck("w.word", &collection.Words).Error
+	return collection, err
+}
+
+func setCollectionWords(tx *gorm.DB, collectionID uint, lang string, words []string) error {
+	for position, word := range words {
+		id, err := wordID(tx, word, lang)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&CollectionWordRecord{CollectionID: collectionID, WordID: id, Position: position}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteWordMemberships retire un mot de ses étiquettes et collections avant sa suppression.
+func deleteWordMemberships(tx *gorm.DB, wordID uint) error {
+	if err := tx.Where("word_id = ?", wordID).Delete(&WordTagRecord{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("word_id = ?", wordID).Delete(&CollectionWordRecord{}).Error
+}
@@ -1,6 +1,9 @@
 package db
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"tp2/dictionary"
 	"tp2/interfaces"
 
@@ -10,91 +13,323 @@ import (
 
 type GormWordRepository struct {
 	DB *gorm.DB
+
+	readOnly bool // ouverte par OpenGormRepository en lecture seule
+}
+
+var ErrNotInitialized = errors.New("base de données non initialisée")
+
+// translateError convertit les erreurs gorm en erreurs communes aux dépôts.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return interfaces.ErrWordNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return interfaces.ErrWordExists
+	default:
+		return err
+	}
+}
+
+// session renvoie une session gorm liée au contexte, ou ErrNotInitialized si InitializeDB n'a pas été appelée.
+func (g *GormWordRepository) session(ctx context.Context) (*gorm.DB, error) {
+	if g.DB == nil {
+		return nil, ErrNotInitialized
+	}
+	return g.DB.WithContext(ctx), nil
 }
 
+// OpenSQLite ouvre la base SQLite sans appliquer de migration.
+func OpenSQLite(dbPath string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{Logger: NewSlogLogger(), TranslateError: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite n'accepte qu'un écrivain à la fois : une seule connexion évite les
+	// erreurs "database is locked" et partage une base ":memory:" entre requêtes.
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// OpenSQLiteReadOnly ouvre une base SQLite existante en lecture seule.
+func OpenSQLiteReadOnly(dbPath string) (*gorm.DB, error) {
+	return OpenSQLite("file:" + dbPath + "?mode=ro")
+}
+
+// OpenGormRepository ouvre la base sans appliquer de migration, en lecture
+// seule si readOnly, pour les commandes qui ne doivent pas toucher au schéma.
+// Elle refuse une base qui n'est pas à la version de ce binaire.
+func OpenGormRepository(dbPath string, readOnly bool) (*GormWordRepository, error) {
+	open := OpenSQLite
+	if readOnly {
+		open = OpenSQLiteReadOnly
+	}
+	db, err := open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaCurrent(db); err != nil {
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			sqlDB.Close()
+		}
+		return nil, err
+	}
+	return &GormWordRepository{DB: db, readOnly: readOnly}, nil
+}
+
+// InitializeDB ouvre la base et applique les migrations en attente. Elle refuse
+// une base dont le schéma est plus récent que ce binaire.
 func (g *GormWordRepository) InitializeDB(dbPath string) error {
-	var err error
-	g.DB, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	db, err := OpenSQLite(dbPath)
+	if err != nil {
+		return err
+	}
+
+	migrator, err := NewMigrator(db)
+	if err == nil {
+		_, err = migrator.Up(context.Background(), 0)
+	}
 	if err != nil {
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			sqlDB.Close()
+		}
 		return err
 	}
 
-	g.DB.AutoMigrate(&dictionary.Word{})
+	g.DB = db
 	return nil
 }
 
+// CloseDB met à jour les statistiques des index si nécessaire (PRAGMA
+// optimize), sauf en lecture seule, puis ferme la base.
 func (g *GormWordRepository) CloseDB() {
+	if g.DB == nil {
+		return
+	}
 	sqlDB, err := g.DB.DB()
 	if err != nil {
 		return
 	}
+	if g.readOnly {
+		sqlDB.Close()
+		return
+	}
+	if err := g.DB.Exec("PRAGMA optimize").Error; err != nil {
+		slog.Warn("échec de la mise à jour des statistiques de la base", "error", err)
+	}
 	sqlDB.Close()
 }
 
-func (g *GormWordRepository) AddWordToDB(word, definition string) error {
+// Ping vérifie que la connexion à la base de données est utilisable.
+func (g *GormWordRepository) Ping(ctx context.Context) error {
+	if g.DB == nil {
+		return ErrNotInitialized
+	}
+	sqlDB, err := g.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (g *GormWordRepository) AddWordToDB(ctx context.Context, entry interfaces.Word) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := addWord(ctx, tx, entry)
+		return err
+	})
+	return translateError(err)
+}
+
+// addWord enregistre l'entrée et la renvoie telle qu'enregistrée.
+func addWord(ctx context.Context, tx *gorm.DB, entry interfaces.Word) (interfaces.Word, error) {
+	lang, err := interfaces.NormalizeLang(entry.Lang)
+	if err != nil {
+		return interfaces.Word{}, err
+	}
+	word := interfaces.NormalizeWord(entry.Word)
 	newWord := dictionary.Word{
-		Word:       word,
-		Definition: definition,
+		Word:         word,
+		Key:          interfaces.WordKey(word),
+		Lang:         lang,
+		Definition:   entry.Definition,
+		PartOfSpeech: entry.PartOfSpeech,
 	}
+	setDerivedKeys(&newWord)
 
-	result := g.DB.Create(&newWord)
+	result := tx.Create(&newWord)
 	if result.Error != nil {
-		return result.Error
+		return interfaces.Word{}, result.Error
 	}
 
-	return nil
+	stored := fromRecord(newWord)
+	if err := indexSearchTerms(tx, newWord.ID, stored); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := indexWordLetters(tx, newWord.ID, newWord.SortedLetters); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := recordRevision(ctx, tx, dictionary.EventWordAdded, stored); err != nil {
+		return interfaces.Word{}, err
+	}
+	return stored, recordAudit(ctx, tx, AuditActionAdd, word, lang, "", entry.Definition)
 }
-func (g *GormWordRepository) DeleteWordFromDB(word string) error {
-	result := g.DB.Where("word = ?", word).Unscoped().Delete(&dictionary.Word{})
+
+func (g *GormWordRepository) DeleteWordFromDB(ctx context.Context, word, lang string) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := deleteWord(ctx, tx, word, lang)
+		return err
+	})
+	return translateError(err)
+}
+
+// deleteWord supprime l'entrée et la renvoie telle qu'elle était enregistrée.
+func deleteWord(ctx context.Context, tx *gorm.DB, word, lang string) (interfaces.Word, error) {
+	existingWord, err := findWord(tx, word, lang)
+	if err != nil {
+		return interfaces.Word{}, err
+	}
+
+	if err := deleteWordRelations(tx, existingWord.ID); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := deleteWordMemberships(tx, existingWord.ID); err != nil {
+		return interfaces.Word{}, err
+	}
+
+	if err := deleteWordTranslations(tx, existingWord.ID); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := deleteSearchTerms(tx, existingWord.ID); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := deleteWordLetters(tx, existingWord.ID); err != nil {
+		return interfaces.Word{}, err
+	}
+
+	result := tx.Unscoped().Delete(&existingWord)
 	if result.Error != nil {
-		return result.Error
+		return interfaces.Word{}, result.Error
 	}
 
-	return nil
+	removed := interfaces.Word{Word: existingWord.Word, Lang: existingWord.Lang}
+	if err := recordRevision(ctx, tx, dictionary.EventWordRemoved, removed); err != nil {
+		return interfaces.Word{}, err
+	}
+	return fromRecord(existingWord), recordAudit(ctx, tx, AuditActionDelete, existingWord.Word, existingWord.Lang, existingWord.Definition, "")
 }
-func (g *GormWordRepository) ListWordsFromDB() ([]interfaces.Word, error) {
+
+func (g *GormWordRepository) ListWordsFromDB(ctx context.Context) ([]interfaces.Word, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var words []dictionary.Word
-	result := g.DB.Find(&words)
+	result := db.Order("id").Find(&words)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	var interfaceWords []interfaces.Word
 	for _, w := range words {
-		interfaceWords = append(interfaceWords, interfaces.Word{
-			Word:       w.Word,
-			Definition: w.Definition,
-		})
+		interfaceWords = append(interfaceWords, fromRecord(w))
 	}
 
 	return interfaceWords, nil
 }
 
-func (g *GormWordRepository) UpdateWordInDB(word, newDefinition string) error {
-	var existingWord dictionary.Word
-	result := g.DB.Where("word = ?", word).First(&existingWord)
-	if result.Error != nil {
-		return result.Error
+func (g *GormWordRepository) UpdateWordInDB(ctx context.Context, word, lang, newDefinition string) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := updateWord(ctx, tx, word, lang, newDefinition)
+		return err
+	})
+	return translateError(err)
+}
+
+// updateWord remplace la définition de l'entrée et la renvoie telle qu'enregistrée.
+func updateWord(ctx context.Context, tx *gorm.DB, word, lang, newDefinition string) (interfaces.Word, error) {
+	existingWord, err := findWord(tx, word, lang)
+	if err != nil {
+		return interfaces.Word{}, err
 	}
 
+	before := existingWord.Definition
 	existingWord.Definition = newDefinition
+	setDerivedKeys(&existingWord)
 
-	result = g.DB.Save(&existingWord)
+	result := tx.Save(&existingWord)
 	if result.Error != nil {
-		return result.Error
+		return interfaces.Word{}, result.Error
 	}
 
-	return nil
+	stored := fromRecord(existingWord)
+	if err := indexSearchTerms(tx, existingWord.ID, stored); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := indexWordLetters(tx, existingWord.ID, existingWord.SortedLetters); err != nil {
+		return interfaces.Word{}, err
+	}
+	if err := recordRevision(ctx, tx, dictionary.EventWordUpdated, stored); err != nil {
+		return interfaces.Word{}, err
+	}
+	return stored, recordAudit(ctx, tx, AuditActionUpdate, existingWord.Word, existingWord.Lang, before, newDefinition)
 }
 
-func (g *GormWordRepository) GetWordFromDB(word string) (interfaces.Word, error) {
-	var existingWord dictionary.Word
-	result := g.DB.Where("word = ?", word).First(&existingWord)
-	if result.Error != nil {
-		return interfaces.Word{}, result.Error
+func (g *GormWordRepository) GetWordFromDB(ctx context.Context, word, lang string) (interfaces.Word, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return interfaces.Word{}, err
+	}
+	existingWord, err := findWord(db, word, lang)
+	if err != nil {
+		return interfaces.Word{}, translateError(err)
 	}
 
-	return interfaces.Word{
-		Word:       existingWord.Word,
-		Definition: existingWord.Definition,
-	}, nil
+	return fromRecord(existingWord), nil
+}
+
+func (g *GormWordRepository) ListWordsByKey(ctx context.Context, word string) ([]interfaces.Word, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var records []dictionary.Word
+	if err := db.Where("word_key = ?", interfaces.WordKey(word)).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	words := []interfaces.Word{}
+	for _, w := range records {
+		words = append(words, fromRecord(w))
+	}
+	return words, nil
+}
+
+func fromRecord(w dictionary.Word) interfaces.Word {
+	return interfaces.Word{Word: w.Word, Lang: w.Lang, Definition: w.Definition, PartOfSpeech: w.PartOfSpeech}
+}
+
+// findWord renvoie l'entrée du mot dans la langue lang.
+func findWord(tx *gorm.DB, word, lang string) (dictionary.Word, error) {
+	var existing dictionary.Word
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return existing, err
+	}
+	err = tx.Where("word_key = ? AND lang = ?", interfaces.WordKey(word), lang).First(&existing).Error
+	return existing, err
 }
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyRecord est une ligne de la table idempotency_keys. Header est
+// l'en-tête de la réponse en JSON ; ReservedUntil borne l'attente d'une
+// requête en cours (Status 0). Les dates sont en UTC pour être comparées
+// telles qu'enregistrées.
+type IdempotencyKeyRecord struct {
+	Key           string `gorm:"primaryKey"`
+	RequestHash   string `gorm:"not null"`
+	Status        int    `gorm:"not null"`
+	Header        string `gorm:"not null"`
+	Body          []byte
+	CreatedAt     time.Time
+	ReservedUntil time.Time
+	ExpiresAt     time.Time `gorm:"index"`
+}
+
+func (IdempotencyKeyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+func (g *GormWordRepository) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, now, reservedUntil, expiresAt time.Time) (interfaces.IdempotentResponse, bool, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return interfaces.IdempotentResponse{}, false, err
+	}
+	var (
+		response interfaces.IdempotentResponse
+		found    bool
+	)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var record IdempotencyKeyRecord
+		err := tx.Where("key = ?", key).Take(&record).Error
+		abandoned := record.Status == 0 && !record.ReservedUntil.After(now.UTC())
+		switch {
+		case err == nil && record.ExpiresAt.After(now.UTC()) && !abandoned:
+			found = true
+			response, err = record.toResponse()
+			return err
+		case err == nil:
+			// Clé expirée ou réservation abandonnée : elle est réservée à nouveau.
+			if err := tx.Delete(&record).Error; err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		return tx.Create(&IdempotencyKeyRecord{
+			Key:           key,
+			RequestHash:   requestHash,
+			CreatedAt:     now.UTC(),
+			ReservedUntil: reservedUntil.UTC(),
+			ExpiresAt:     expiresAt.UTC(),
+		}).Error
+	})
+	return response, found, err
+}
+
+func (g *GormWordRepository) CompleteIdempotencyKey(ctx context.Context, key string, response interfaces.IdempotentResponse) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	return db.Model(&IdempotencyKeyRecord{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"status": response.Status,
+		"header": string(header),
+		"body":   response.Body,
+	}).Error
+}
+
+func (g *GormWordRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("key = ?", key).Delete(&IdempotencyKeyRecord{}).Error
+}
+
+func (g *GormWordRepository) PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.Where("expires_at <= ?", now.UTC()).Delete(&IdempotencyKeyRecord{})
+	return int(result.RowsAffected), result.Error
+}
+
+func (r IdempotencyKeyRecord) toResponse() (interfaces.IdempotentResponse, error) {
+	response := interfaces.IdempotentResponse{RequestHash: r.RequestHash, Status: r.Status, Body: r.Body}
+	if r.Header != "" {
+		if err := json.Unmarshal([]byte(r.Header), &response.Header); err != nil {
+			return interfaces.IdempotentResponse{}, err
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// JSONFileWordRepository conserve les mots en mémoire et réécrit un fichier
+// JSON après chaque modification. Le fichier est remplacé atomiquement.
+type JSONFileWordRepository struct {
+	storeRepository
+	path string
+}
+
+func (j *JSONFileWordRepository) InitializeDB(dbPath string) error {
+	j.path = dbPath
+	s := j.store()
+
+	data, err := os.ReadFile(dbPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		var content map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(data, &content); err != nil {
+			return fmt.Errorf("fichier %s invalide : %w", dbPath, err)
+		}
+		tables := s.tables()
+		for name, rows := range content {
+			t, ok := tables[name]
+			if !ok {
+				continue
+			}
+			for key, row := range rows {
+				if err := t.load(key, row); err != nil {
+					return fmt.Errorf("fichier %s invalide : %w", dbPath, err)
+				}
+			}
+		}
+		s.afterLoad()
+	}
+
+	s.persist = func(changes []change) error {
+		return j.save()
+	}
+	return s.update(s.rekey)
+}
+
+func (j *JSONFileWordRepository) CloseDB() {}
+
+// save est appelée sous le verrou du magasin.
+func (j *JSONFileWordRepository) save() error {
+	content := make(map[string]map[string]interface{})
+	for name, t := range j.s.tables() {
+		rows := make(map[string]interface{})
+		t.each(func(key string, v interface{}) {
+			rows[key] = v
+		})
+		content[name] = rows
+	}
+
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
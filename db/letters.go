@@ -0,0 +1,432 @@
+package db
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"tp2/dictionary"
+	"tp2/interfaces"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// backfillWordLetters calcule les lettres des mots existants.
+func backfillWordLetters(tx *gorm.DB) error {
+	var words []dictionary.Word
+	if err := tx.Select("id, word").Order("id").Find(&words).Error; err != nil {
+		return err
+	}
+	for _, w := range words {
+		update := map[string]any{
+			"letters":        interfaces.WordLetters(w.Word),
+			"sorted_letters": interfaces.SortedLetters(w.Word),
+		}
+		if err := tx.Table("words").Where("id = ?", w.ID).Updates(update).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// letterCount est le nombre d'occurrences d'une lettre.
+type letterCount struct {
+	letter string
+	count  int
+}
+
+// letterCounts renvoie le nombre d'occurrences de chaque lettre de sorted,
+// déjà triée (voir interfaces.SortedLetters).
+func letterCounts(sorted string) []letterCount {
+	var counts []letterCount
+	for _, r := range sorted {
+		if n := len(counts); n > 0 && counts[n-1].letter == string(r) {
+			counts[n-1].count++
+		} else {
+			counts = append(counts, letterCount{letter: string(r), count: 1})
+		}
+	}
+	return counts
+}
+
+// patternLength renvoie le nombre de lettres des mots qui suivent pattern :
+// exactement n sans « * », au moins n avec.
+func patternLength(pattern string) (n int, exact bool) {
+	n = utf8.RuneCountInString(pattern) - strings.Count(pattern, "*")
+	return n, !strings.Contains(pattern, "*")
+}
+
+// WordLetterRecord est une ligne de la table word_letters : le nombre
+// d'occurrences d'une lettre d'un mot, remplacé dans la transaction de chaque
+// modification de l'entrée.
+type WordLetterRecord struct {
+	Letter string `gorm:"primaryKey"`
+	WordID uint   `gorm:"primaryKey"`
+	Count  int    `gorm:"not null"`
+}
+
+func (WordLetterRecord) TableName() string {
+	return "word_letters"
+}
+
+// indexWordLetters remplace dans tx les lettres de l'entrée d'identifiant id.
+func indexWordLetters(tx *gorm.DB, id uint, sorted string) error {
+	if err := deleteWordLetters(tx, id); err != nil {
+		return err
+	}
+	counts := letterCounts(sorted)
+	if len(counts) == 0 {
+		return nil
+	}
+	records := make([]WordLetterRecord, len(counts))
+	for i, c := range counts {
+		records[i] = WordLetterRecord{Letter: c.letter, WordID: id, Count: c.count}
+	}
+	return tx.Create(&records).Error
+}
+
+func deleteWordLetters(tx *gorm.DB, id uint) error {
+	return tx.Where("word_id = ?", id).Delete(&WordLetterRecord{}).Error
+}
+
+// backfillLetterCounts compte les lettres des mots existants, insérées par lots.
+func backfillLetterCounts(tx *gorm.DB) error {
+	var words []dictionary.Word
+	if err := tx.Select("id, sorted_letters").Order("id").Find(&words).Error; err != nil {
+		return err
+	}
+	var records []WordLetterRecord
+	for _, w := range words {
+		for _, c := range letterCounts(w.SortedLetters) {
+			records = append(records, WordLetterRecord{Letter: c.letter, WordID: w.ID, Count: c.count})
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&records, 1000).Error
+}
+
+// letterSample borne le comptage des mots d'une lettre par rarestLetter : au-delà,
+// la lettre est assez fréquente pour que la limite soit vite atteinte.
+const letterSample = 1000
+
+// rarestLetter renvoie l'indice dans counts de la lettre que le moins de mots
+// contiennent assez de fois.
+func rarestLetter(db *gorm.DB, counts []letterCount) (int, error) {
+	rarest, fewest := 0, int64(-1)
+	for i, c := range counts {
+		if len(counts) == 1 {
+			break
+		}
+		var n int64
+		err := db.Raw("SELECT COUNT(*) FROM (SELECT 1 FROM word_letters WHERE letter = ? AND count >= ? LIMIT ?)",
+			c.letter, c.count, letterSample).Scan(&n).Error
+		if err != nil {
+			return 0, err
+		}
+		if fewest < 0 || n < fewest {
+			rarest, fewest = i, n
+		}
+	}
+	return rarest, nil
+}
+
+// withLetters restreint db aux mots qui ont au moins les lettres de counts. Ils
+// sont lus dans la clé (letter, word_id) de word_letters, par ordre
+// d'identifiant, à partir de la lettre la plus rare : la lecture s'arrête dès
+// la limite atteinte.
+func withLetters(db *gorm.DB, counts []letterCount) (*gorm.DB, error) {
+	rarest, err := rarestLetter(db, counts)
+	if err != nil {
+		return nil, err
+	}
+	found := db.Joins("JOIN word_letters l ON l.word_id = words.id AND l.letter = ? AND l.count >= ?",
+		counts[rarest].letter, counts[rarest].count)
+	for i, c := range counts {
+		if i != rarest {
+			found = found.Where("EXISTS (SELECT 1 FROM word_letters WHERE letter = ? AND word_id = l.word_id AND count >= ?)", c.letter, c.count)
+		}
+	}
+	return found.Order("l.word_id"), nil
+}
+
+// findWords renvoie les entrées choisies par find, qui fixe aussi l'ordre
+// d'insertion. Le motif GLOB de SQLite a la même syntaxe que NormalizePattern.
+func (g *GormWordRepository) findWords(ctx context.Context, lang string, limit int, find func(db *gorm.DB) (*gorm.DB, error)) ([]interfaces.Word, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if db, err = find(db); err != nil {
+		return nil, err
+	}
+	if lang != "" {
+		db = db.Where("words.lang = ?", lang)
+	}
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	var words []dictionary.Word
+	if err := db.Find(&words).Error; err != nil {
+		return nil, err
+	}
+	result := make([]interfaces.Word, 0, len(words))
+	for _, w := range words {
+		result = append(result, fromRecord(w))
+	}
+	return result, nil
+}
+
+// MatchWords part des lettres du motif, ou à défaut de sa longueur (index sur
+// length(letters)), avant de lui comparer les mots.
+func (g *GormWordRepository) MatchWords(ctx context.Context, pattern, lang string, limit int) ([]interfaces.Word, error) {
+	pattern, err := interfaces.NormalizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	n, exact := patternLength(pattern)
+	return g.findWords(ctx, lang, limit, func(db *gorm.DB) (*gorm.DB, error) {
+		found := db.Order("words.id")
+		if counts := letterCounts(interfaces.SortedLetters(pattern)); len(counts) > 0 {
+			if found, err = withLetters(db, counts); err != nil {
+				return nil, err
+			}
+		}
+		if exact {
+			found = found.Where("length(words.letters) = ?", n)
+		} else {
+			found = found.Where("length(words.letters) >= ?", n)
+		}
+		return found.Where("words.letters GLOB ?", pattern), nil
+	})
+}
+
+func (g *GormWordRepository) ListAnagrams(ctx context.Context, letters, lang string, limit int) ([]interfaces.Word, error) {
+	sorted, err := interfaces.NormalizeLetters(letters)
+	if err != nil {
+		return nil, err
+	}
+	return g.findWords(ctx, lang, limit, func(db *gorm.DB) (*gorm.DB, error) {
+		return db.Where("sorted_letters = ?", sorted).Order("id"), nil
+	})
+}
+
+func (g *GormWordRepository) ListWordsContaining(ctx context.Context, letters, lang string, limit int) ([]interfaces.Word, error) {
+	sorted, err := interfaces.NormalizeLetters(letters)
+	if err != nil {
+		return nil, err
+	}
+	return g.findWords(ctx, lang, limit, func(db *gorm.DB) (*gorm.DB, error) {
+		return withLetters(db, letterCounts(sorted))
+	})
+}
+
+// letterEntry est une entrée de letterIndex, rangée par ordre d'insertion.
+type letterEntry struct {
+	seq uint64
+	key string
+}
+
+func (e letterEntry) before(other letterEntry) bool {
+	return e.seq < other.seq || e.seq == other.seq && e.key < other.key
+}
+
+// letterIndex range les entrées des dépôts en mémoire par lettres triées, par
+// lettre et par longueur, chaque liste dans l'ordre d'insertion : une recherche
+// ne lit que des candidats et s'arrête dès la limite atteinte.
+type letterIndex struct {
+	sorted  map[string][]letterEntry // Lettres triées → entrées
+	letters map[string][]letterEntry // Lettre → entrées qui la contiennent
+	lengths map[int][]letterEntry    // Nombre de lettres → entrées
+	entries map[string]storedWord    // Clé → mot indexé, pour le retirer
+}
+
+func newLetterIndex() *letterIndex {
+	return &letterIndex{
+		sorted:  make(map[string][]letterEntry),
+		letters: make(map[string][]letterEntry),
+		lengths: make(map[int][]letterEntry),
+		entries: make(map[string]storedWord),
+	}
+}
+
+func (idx *letterIndex) put(key string, w storedWord) {
+	idx.remove(key)
+	idx.entries[key] = w
+	e := letterEntry{seq: w.Seq, key: key}
+	idx.sorted[w.SortedLetters] = insertEntry(idx.sorted[w.SortedLetters], e)
+	n := utf8.RuneCountInString(w.SortedLetters)
+	idx.lengths[n] = insertEntry(idx.lengths[n], e)
+	for _, c := range letterCounts(w.SortedLetters) {
+		idx.letters[c.letter] = insertEntry(idx.letters[c.letter], e)
+	}
+}
+
+func (idx *letterIndex) remove(key string) {
+	w, ok := idx.entries[key]
+	if !ok {
+		return
+	}
+	delete(idx.entries, key)
+	e := letterEntry{seq: w.Seq, key: key}
+	removeEntry(idx.sorted, w.SortedLetters, e)
+	removeEntry(idx.lengths, utf8.RuneCountInString(w.SortedLetters), e)
+	for _, c := range letterCounts(w.SortedLetters) {
+		removeEntry(idx.letters, c.letter, e)
+	}
+}
+
+// insertEntry ajoute e à entries en gardant l'ordre d'insertion ; une nouvelle
+// entrée va simplement à la fin.
+func insertEntry(entries []letterEntry, e letterEntry) []letterEntry {
+	i := sort.Search(len(entries), func(i int) bool { return !entries[i].before(e) })
+	entries = append(entries, letterEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = e
+	return entries
+}
+
+func removeEntry[K comparable](m map[K][]letterEntry, k K, e letterEntry) {
+	entries := m[k]
+	i := sort.Search(len(entries), func(i int) bool { return !entries[i].before(e) })
+	if i == len(entries) || entries[i] != e {
+		return
+	}
+	if entries = append(entries[:i], entries[i+1:]...); len(entries) == 0 {
+		delete(m, k)
+	} else {
+		m[k] = entries
+	}
+}
+
+// anagrams renvoie les entrées de lettres triées sorted.
+func (idx *letterIndex) anagrams(sorted string) []letterEntry {
+	return idx.sorted[sorted]
+}
+
+// withLetter renvoie, parmi les lettres de counts, les entrées de la moins
+// fréquente : celles qui les contiennent toutes en font partie.
+func (idx *letterIndex) withLetter(counts []letterCount) []letterEntry {
+	rarest := idx.letters[counts[0].letter]
+	for _, c := range counts[1:] {
+		if entries := idx.letters[c.letter]; len(entries) < len(rarest) {
+			rarest = entries
+		}
+	}
+	return rarest
+}
+
+// withLength appelle yield sur les entrées de n lettres, ou d'au moins n si
+// exact est faux, dans l'ordre d'insertion, jusqu'à ce qu'il renvoie faux.
+func (idx *letterIndex) withLength(n int, exact bool, yield func(e letterEntry) bool) {
+	if exact {
+		for _, e := range idx.lengths[n] {
+			if !yield(e) {
+				return
+			}
+		}
+		return
+	}
+	// Fusion des listes des longueurs retenues, chacune déjà ordonnée.
+	var lists [][]letterEntry
+	for length, entries := range idx.lengths {
+		if length >= n {
+			lists = append(lists, entries)
+		}
+	}
+	for {
+		next := -1
+		for i, entries := range lists {
+			if len(entries) > 0 && (next < 0 || entries[0].before(lists[next][0])) {
+				next = i
+			}
+		}
+		if next < 0 || !yield(lists[next][0]) {
+			return
+		}
+		lists[next] = lists[next][1:]
+	}
+}
+
+// hasLetters indique si les lettres triées sorted contiennent celles de counts.
+func hasLetters(sorted string, counts []letterCount) bool {
+	for _, c := range counts {
+		if strings.Count(sorted, c.letter) < c.count {
+			return false
+		}
+	}
+	return true
+}
+
+// findWords renvoie les entrées que candidates passe à yield, dans l'ordre
+// d'insertion, qui sont dans la langue lang et vérifient match. candidates lit
+// l'index des lettres et s'arrête quand yield renvoie faux, la limite atteinte.
+func (r *storeRepository) findWords(lang string, limit int, candidates func(idx *letterIndex, yield func(e letterEntry) bool), match func(w storedWord) bool) []interfaces.Word {
+	var words []interfaces.Word
+	s := r.store()
+	s.view(func() {
+		candidates(s.letterIndex, func(e letterEntry) bool {
+			if w, ok := s.words.get(e.key); ok && (lang == "" || w.lang() == lang) && match(w) {
+				words = append(words, toWord(w))
+			}
+			return limit <= 0 || len(words) < limit
+		})
+	})
+	if words == nil {
+		words = []interfaces.Word{}
+	}
+	return words
+}
+
+// each appelle yield sur entries jusqu'à ce qu'il renvoie faux.
+func each(entries []letterEntry, yield func(e letterEntry) bool) {
+	for _, e := range entries {
+		if !yield(e) {
+			return
+		}
+	}
+}
+
+func (r *storeRepository) MatchWords(ctx context.Context, pattern, lang string, limit int) ([]interfaces.Word, error) {
+	pattern, err := interfaces.NormalizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	n, exact := patternLength(pattern)
+	counts := letterCounts(interfaces.SortedLetters(pattern))
+	return r.findWords(lang, limit, func(idx *letterIndex, yield func(e letterEntry) bool) {
+		if len(counts) > 0 {
+			each(idx.withLetter(counts), yield)
+		} else {
+			idx.withLength(n, exact, yield)
+		}
+	}, func(w storedWord) bool {
+		ok, _ := path.Match(pattern, w.Letters)
+		return ok
+	}), nil
+}
+
+func (r *storeRepository) ListAnagrams(ctx context.Context, letters, lang string, limit int) ([]interfaces.Word, error) {
+	sorted, err := interfaces.NormalizeLetters(letters)
+	if err != nil {
+		return nil, err
+	}
+	return r.findWords(lang, limit, func(idx *letterIndex, yield func(e letterEntry) bool) {
+		each(idx.anagrams(sorted), yield)
+	}, func(storedWord) bool { return true }), nil
+}
+
+func (r *storeRepository) ListWordsContaining(ctx context.Context, letters, lang string, limit int) ([]interfaces.Word, error) {
+	sorted, err := interfaces.NormalizeLetters(letters)
+	if err != nil {
+		return nil, err
+	}
+	counts := letterCounts(sorted)
+	return r.findWords(lang, limit, func(idx *letterIndex, yield func(e letterEntry) bool) {
+		each(idx.withLetter(counts), yield)
+	}, func(w storedWord) bool {
+		return hasLetters(w.SortedLetters, counts)
+	}), nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogLogger redirige les logs de gorm vers slog en conservant le contexte
+// de la requête, ce qui ajoute l'identifiant de corrélation aux requêtes SQL.
+type slogLogger struct {
+	level logger.LogLevel
+}
+
+func NewSlogLogger() logger.Interface {
+	return &slogLogger{level: logger.Info}
+}
+
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &slogLogger{level: level}
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, msg, "args", args)
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, msg, "args", args)
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, msg, "args", args)
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("duration", elapsed),
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		slog.LogAttrs(ctx, slog.LevelError, "erreur SQL", append(attrs, slog.String("error", err.Error()))...)
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		slog.LogAttrs(ctx, slog.LevelWarn, "requête SQL lente", attrs...)
+	case l.level >= logger.Info:
+		slog.LogAttrs(ctx, slog.LevelDebug, "requête SQL", attrs...)
+	}
+}
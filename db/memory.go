@@ -0,0 +1,633 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"tp2/interfaces"
+)
+
+// storeRepository implémente interfaces.WordRepository au-dessus d'un memoryStore.
+// Il est partagé par les dépôts mémoire, fichier JSON et bbolt.
+type storeRepository struct {
+	once sync.Once
+	s    *memoryStore
+}
+
+func (r *storeRepository) store() *memoryStore {
+	r.once.Do(func() {
+		if r.s == nil {
+			r.s = newMemoryStore()
+		}
+	})
+	return r.s
+}
+
+func (r *storeRepository) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (r *storeRepository) ListWordsFromDB(ctx context.Context) ([]interfaces.Word, error) {
+	var words []interfaces.Word
+	s := r.store()
+	s.view(func() {
+		for _, w := range s.sortedWords() {
+			words = append(words, toWord(w))
+		}
+	})
+	return words, nil
+}
+
+func (r *storeRepository) AddWordToDB(ctx context.Context, entry interfaces.Word) error {
+	lang, err := interfaces.NormalizeLang(entry.Lang)
+	if err != nil {
+		return err
+	}
+	entry.Lang = lang
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		_, err := s.addWord(ctx, c, entry)
+		return err
+	})
+}
+
+func (r *storeRepository) DeleteWordFromDB(ctx context.Context, word, lang string) error {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		_, err := s.deleteWord(ctx, c, word, lang)
+		return err
+	})
+}
+
+func (r *storeRepository) UpdateWordInDB(ctx context.Context, word, lang, newDefinition string) error {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		_, err := s.updateWord(ctx, c, word, lang, newDefinition)
+		return err
+	})
+}
+
+// ApplyBatch exécute chaque opération dans son propre changeSet pour pouvoir
+// l'annuler seule ; en mode atomique, une erreur annule tout le lot.
+func (r *storeRepository) ApplyBatch(ctx context.Context, ops []interfaces.BatchOperation, atomic bool) ([]interfaces.BatchResult, error) {
+	s := r.store()
+	results := make([]interfaces.BatchResult, len(ops))
+	err := s.update(func(c *changeSet) error {
+		failed := false
+		for i, op := range ops {
+			opChanges := &changeSet{}
+			if results[i].Entry, results[i].Err = s.applyOperation(ctx, opChanges, op); results[i].Err != nil {
+				opChanges.rollback()
+				failed = true
+				continue
+			}
+			c.merge(opChanges)
+		}
+		if atomic && failed {
+			return interfaces.ErrBatchRolledBack
+		}
+		return nil
+	})
+	return results, err
+}
+
+func (r *storeRepository) GetWordFromDB(ctx context.Context, word, lang string) (interfaces.Word, error) {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return interfaces.Word{}, err
+	}
+	var (
+		existing storedWord
+		exists   bool
+	)
+	s := r.store()
+	s.view(func() {
+		existing, exists = s.words.get(entryKey(word, lang))
+	})
+	if !exists {
+		return interfaces.Word{}, interfaces.ErrWordNotFound
+	}
+	return toWord(existing), nil
+}
+
+// ListWordsByKey compare les clés d'entrées, qui commencent par la clé du mot
+// (voir entryKey), sans recalculer celle de chaque mot.
+func (r *storeRepository) ListWordsByKey(ctx context.Context, word string) ([]interfaces.Word, error) {
+	key := interfaces.WordKey(word)
+	var matches []storedWord
+	s := r.store()
+	s.view(func() {
+		for entry, w := range s.words.rows {
+			if wordKey, _, _ := strings.Cut(entry, "\x01"); wordKey == key {
+				matches = append(matches, w)
+			}
+		}
+	})
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Seq < matches[j].Seq })
+	words := []interfaces.Word{}
+	for _, w := range matches {
+		words = append(words, toWord(w))
+	}
+	return words, nil
+}
+
+func toWord(w storedWord) interfaces.Word {
+	return interfaces.Word{Word: w.Word, Lang: w.lang(), Definition: w.Definition, PartOfSpeech: w.PartOfSpeech}
+}
+
+func (r *storeRepository) ListRevisions(ctx context.Context, afterID uint64, limit int) ([]interfaces.Revision, error) {
+	var revisions []interfaces.Revision
+	s := r.store()
+	s.view(func() {
+		for _, rev := range s.revisions.rows {
+			if rev.ID > afterID {
+				revisions = append(revisions, interfaces.Revision(rev))
+			}
+		}
+	})
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].ID < revisions[j].ID })
+	if limit > 0 && len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+	return revisions, nil
+}
+
+func (r *storeRepository) LastRevisionID(ctx context.Context) (uint64, error) {
+	var id uint64
+	s := r.store()
+	s.view(func() {
+		for _, rev := range s.revisions.rows {
+			if rev.ID > id {
+				id = rev.ID
+			}
+		}
+	})
+	return id, nil
+}
+
+func (r *storeRepository) AddRelation(ctx context.Context, word, lang, relationType, target string) error {
+	source, relationType, target, err := normalizeRelation(word, relationType, target)
+	if err != nil {
+		return err
+	}
+	if lang, err = interfaces.NormalizeLang(lang); err != nil {
+		return err
+	}
+	source, target = entryKey(source, lang), entryKey(target, lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if !s.hasWords(source, target) {
+			return interfaces.ErrWordNotFound
+		}
+		key := relationKey(source, relationType, target)
+		if _, exists := s.relations.get(key); exists {
+			return interfaces.ErrRelationExists
+		}
+		s.relations.put(c, key, storedRelation{Seq: s.nextSeq(), Word: source, Type: relationType, Target: target})
+		return nil
+	})
+}
+
+func (r *storeRepository) RemoveRelation(ctx context.Context, word, lang, relationType, target string) error {
+	source, relationType, target, err := normalizeRelation(word, relationType, target)
+	if err != nil {
+		return err
+	}
+	if lang, err = interfaces.NormalizeLang(lang); err != nil {
+		return err
+	}
+	source, target = entryKey(source, lang), entryKey(target, lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if !s.hasWords(source, target) {
+			return interfaces.ErrWordNotFound
+		}
+		key := relationKey(source, relationType, target)
+		if _, exists := s.relations.get(key); !exists {
+			return interfaces.ErrRelationNotFound
+		}
+		s.relations.remove(c, key)
+		return nil
+	})
+}
+
+func (r *storeRepository) ListRelations(ctx context.Context, word, lang string) ([]interfaces.Relation, error) {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		stored    []storedRelation
+		relations []interfaces.Relation
+		exists    bool
+	)
+	entry := entryKey(word, lang)
+	s := r.store()
+	s.view(func() {
+		_, exists = s.words.get(entry)
+		for _, rel := range s.relations.rows {
+			if rel.Word == entry || rel.Target == entry {
+				stored = append(stored, rel)
+			}
+		}
+
+		sort.Slice(stored, func(i, j int) bool { return stored[i].Seq < stored[j].Seq })
+		relations = make([]interfaces.Relation, len(stored))
+		for i, rel := range stored {
+			relations[i] = orientRelation(entry, rel.Word, rel.Type, rel.Target)
+			relations[i].Target = s.entryRef(relations[i].Target).Word
+		}
+	})
+	if !exists {
+		return nil, interfaces.ErrWordNotFound
+	}
+	return relations, nil
+}
+
+func (r *storeRepository) ListRelationEdges(ctx context.Context, lang string, words []string) ([]interfaces.RelationEdge, error) {
+	entries := make(map[string]bool, len(words))
+	for _, word := range words {
+		entries[entryKey(word, lang)] = true
+	}
+	var edges []interfaces.RelationEdge
+	s := r.store()
+	s.view(func() {
+		var stored []storedRelation
+		for _, rel := range s.relations.rows {
+			if entryLang(rel.Word) == lang && (len(entries) == 0 || entries[rel.Word] || entries[rel.Target]) && s.hasWords(rel.Word, rel.Target) {
+				stored = append(stored, rel)
+			}
+		}
+		sort.Slice(stored, func(i, j int) bool { return stored[i].Seq < stored[j].Seq })
+		edges = make([]interfaces.RelationEdge, len(stored))
+		for i, rel := range stored {
+			edges[i] = interfaces.RelationEdge{
+				Word:   toWord(s.words.rows[rel.Word]),
+				Type:   rel.Type,
+				Target: toWord(s.words.rows[rel.Target]),
+			}
+		}
+	})
+	return edges, nil
+}
+
+// OrphanRelations renvoie, dans l'ordre de création, les relations dont une
+// clé d'entrée n'existe plus, par exemple après une modification à la main du fichier.
+func (r *storeRepository) OrphanRelations(ctx context.Context) ([]interfaces.OrphanRelation, error) {
+	var stored []storedRelation
+	orphans := []interfaces.OrphanRelation{}
+	s := r.store()
+	s.view(func() {
+		for _, rel := range s.relations.rows {
+			if !s.hasWords(rel.Word, rel.Target) {
+				stored = append(stored, rel)
+			}
+		}
+		sort.Slice(stored, func(i, j int) bool { return stored[i].Seq < stored[j].Seq })
+		for _, rel := range stored {
+			orphans = append(orphans, interfaces.OrphanRelation{
+				Lang:   entryLang(rel.Word),
+				Word:   s.entryRef(rel.Word).Word,
+				Type:   rel.Type,
+				Target: s.entryRef(rel.Target).Word,
+			})
+		}
+	})
+	return orphans, nil
+}
+
+func (r *storeRepository) DeleteOrphanRelations(ctx context.Context) (int, error) {
+	deleted := 0
+	s := r.store()
+	err := s.update(func(c *changeSet) error {
+		for key, rel := range s.relations.rows {
+			if !s.hasWords(rel.Word, rel.Target) {
+				s.relations.remove(c, key)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func (r *storeRepository) TagWord(ctx context.Context, word, lang, tag string) error {
+	tag, err := interfaces.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	if lang, err = interfaces.NormalizeLang(lang); err != nil {
+		return err
+	}
+	entry := entryKey(word, lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if !s.hasWords(entry) {
+			return interfaces.ErrWordNotFound
+		}
+		key := tagKey(entry, tag)
+		if _, exists := s.tags.get(key); !exists {
+			s.tags.put(c, key, storedTag{Seq: s.nextSeq(), Word: entry, Tag: tag})
+		}
+		return nil
+	})
+}
+
+func (r *storeRepository) UntagWord(ctx context.Context, word, lang, tag string) error {
+	tag, err := interfaces.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	if lang, err = interfaces.NormalizeLang(lang); err != nil {
+		return err
+	}
+	entry := entryKey(word, lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if !s.hasWords(entry) {
+			return interfaces.ErrWordNotFound
+		}
+		key := tagKey(entry, tag)
+		if _, exists := s.tags.get(key); !exists {
+			return interfaces.ErrTagNotFound
+		}
+		s.tags.remove(c, key)
+		return nil
+	})
+}
+
+func (r *storeRepository) WordTags(ctx context.Context, word, lang string) ([]string, error) {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	var exists bool
+	entry := entryKey(word, lang)
+	s := r.store()
+	s.view(func() {
+		_, exists = s.words.get(entry)
+		for _, t := range s.tags.rows {
+			if t.Word == entry {
+				tags = append(tags, t.Tag)
+			}
+		}
+	})
+	if !exists {
+		return nil, interfaces.ErrWordNotFound
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (r *storeRepository) ListTags(ctx context.Context) ([]interfaces.TagCount, error) {
+	counts := make(map[string]int)
+	s := r.store()
+	s.view(func() {
+		for _, t := range s.tags.rows {
+			counts[t.Tag]++
+		}
+	})
+
+	result := make([]interfaces.TagCount, 0, len(counts))
+	for tag, words := range counts {
+		result = append(result, interfaces.TagCount{Tag: tag, Words: words})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Tag < result[j].Tag })
+	return result, nil
+}
+
+func (r *storeRepository) ListWordsByTags(ctx context.Context, tags []string) ([]interfaces.Word, error) {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		tag, err := interfaces.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = tag
+	}
+
+	words := []interfaces.Word{}
+	s := r.store()
+	s.view(func() {
+	next:
+		for _, w := range s.sortedWords() {
+			for _, tag := range keys {
+				if _, ok := s.tags.get(tagKey(entryKey(w.Word, w.lang()), tag)); !ok {
+					continue next
+				}
+			}
+			words = append(words, toWord(w))
+		}
+	})
+	return words, nil
+}
+
+func (r *storeRepository) CreateCollection(ctx context.Context, collection interfaces.Collection, lang string) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	words := entryKeys(collection.Words, lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if _, exists := s.collections.get(collection.Name); exists {
+			return interfaces.ErrCollectionExists
+		}
+		if !s.hasWords(words...) {
+			return interfaces.ErrWordNotFound
+		}
+		now := time.Now()
+		s.collections.put(c, collection.Name, storedCollection{
+			Seq:         s.nextSeq(),
+			Name:        collection.Name,
+			Description: collection.Description,
+			Words:       words,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		return nil
+	})
+}
+
+func (r *storeRepository) UpdateCollection(ctx context.Context, collection interfaces.Collection, lang string) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	words := entryKeys(collection.Words, lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		existing, exists := s.collections.get(collection.Name)
+		if !exists {
+			return interfaces.ErrCollectionNotFound
+		}
+		if !s.hasWords(words...) {
+			return interfaces.ErrWordNotFound
+		}
+		existing.Description = collection.Description
+		existing.Words = words
+		existing.UpdatedAt = time.Now()
+		s.collections.put(c, collection.Name, existing)
+		return nil
+	})
+}
+
+func (r *storeRepository) DeleteCollection(ctx context.Context, name string) error {
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if _, exists := s.collections.get(name); !exists {
+			return interfaces.ErrCollectionNotFound
+		}
+		s.collections.remove(c, name)
+		return nil
+	})
+}
+
+func (r *storeRepository) GetCollection(ctx context.Context, name string) (interfaces.Collection, error) {
+	var (
+		existing storedCollection
+		exists   bool
+	)
+	s := r.store()
+	s.view(func() {
+		existing, exists = s.collections.get(name)
+	})
+	if !exists {
+		return interfaces.Collection{}, interfaces.ErrCollectionNotFound
+	}
+	return s.toCollection(existing), nil
+}
+
+func (r *storeRepository) ListCollections(ctx context.Context) ([]interfaces.Collection, error) {
+	collections := []interfaces.Collection{}
+	s := r.store()
+	s.view(func() {
+		for _, c := range s.collections.rows {
+			collections = append(collections, s.toCollection(c))
+		}
+	})
+	sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+	return collections, nil
+}
+
+func (s *memoryStore) toCollection(c storedCollection) interfaces.Collection {
+	words := make([]string, len(c.Words))
+	for i, key := range c.Words {
+		words[i] = s.entryRef(key).Word
+	}
+	return interfaces.Collection{Name: c.Name, Description: c.Description, Words: words}
+}
+
+func entryKeys(words []string, lang string) []string {
+	keys := make([]string, len(words))
+	for i, word := range words {
+		keys[i] = entryKey(word, lang)
+	}
+	return keys
+}
+
+func (r *storeRepository) AddTranslation(ctx context.Context, entry, translation interfaces.EntryRef) error {
+	entry, translation, err := normalizeTranslation(entry, translation)
+	if err != nil {
+		return err
+	}
+	from, to := entryKey(entry.Word, entry.Lang), entryKey(translation.Word, translation.Lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if !s.hasWords(from, to) {
+			return interfaces.ErrWordNotFound
+		}
+		key := translationKey(from, to)
+		if _, exists := s.translations.get(key); exists {
+			return interfaces.ErrTranslationExists
+		}
+		s.translations.put(c, key, storedTranslation{Seq: s.nextSeq(), From: from, To: to})
+		return nil
+	})
+}
+
+func (r *storeRepository) RemoveTranslation(ctx context.Context, entry, translation interfaces.EntryRef) error {
+	entry, translation, err := normalizeTranslation(entry, translation)
+	if err != nil {
+		return err
+	}
+	from, to := entryKey(entry.Word, entry.Lang), entryKey(translation.Word, translation.Lang)
+	s := r.store()
+	return s.update(func(c *changeSet) error {
+		if !s.hasWords(from, to) {
+			return interfaces.ErrWordNotFound
+		}
+		key := translationKey(from, to)
+		if _, exists := s.translations.get(key); !exists {
+			return interfaces.ErrTranslationNotFound
+		}
+		s.translations.remove(c, key)
+		return nil
+	})
+}
+
+func (r *storeRepository) ListTranslations(ctx context.Context, entry interfaces.EntryRef) ([]interfaces.EntryRef, error) {
+	lang, err := interfaces.NormalizeLang(entry.Lang)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		stored       []storedTranslation
+		translations []interfaces.EntryRef
+		exists       bool
+	)
+	key := entryKey(entry.Word, lang)
+	s := r.store()
+	s.view(func() {
+		_, exists = s.words.get(key)
+		for _, t := range s.translations.rows {
+			if t.From == key || t.To == key {
+				stored = append(stored, t)
+			}
+		}
+
+		sort.Slice(stored, func(i, j int) bool { return stored[i].Seq < stored[j].Seq })
+		translations = make([]interfaces.EntryRef, len(stored))
+		for i, t := range stored {
+			other := t.To
+			if other == key {
+				other = t.From
+			}
+			translations[i] = s.entryRef(other)
+		}
+	})
+	if !exists {
+		return nil, interfaces.ErrWordNotFound
+	}
+	return translations, nil
+}
+
+// MemoryWordRepository conserve les mots en mémoire : son contenu est perdu à l'arrêt.
+// La valeur zéro est utilisable sans appeler InitializeDB.
+type MemoryWordRepository struct {
+	storeRepository
+}
+
+func (m *MemoryWordRepository) InitializeDB(dbPath string) error {
+	m.store()
+	return nil
+}
+
+func (m *MemoryWordRepository) CloseDB() {}
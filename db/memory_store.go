@@ -0,0 +1,586 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tp2/analysis"
+	"tp2/dictionary"
+	"tp2/interfaces"
+	"tp2/requestctx"
+	"tp2/search"
+)
+
+// memoryStore contient l'état et la logique communs aux dépôts mémoire,
+// fichier JSON et bbolt. Chaque modification passe par update, qui enregistre
+// les lignes touchées pour pouvoir les annuler et les transmettre à persist,
+// puis reporte les mots modifiés dans les index de recherche et de lettres.
+type memoryStore struct {
+	mu           sync.RWMutex
+	words        *table[storedWord]
+	relations    *table[storedRelation]
+	tags         *table[storedTag]
+	collections  *table[storedCollection]
+	translations *table[storedTranslation]
+	revisions    *table[storedRevision]
+	searchIndex  *search.Index // Termes des mots de words, reconstruit au chargement
+	letterIndex  *letterIndex  // Lettres des mots de words, reconstruit au chargement
+	seq          uint64
+	persist      func(changes []change) error
+}
+
+// storedWord est rangé sous entryKey(Word, Lang) ; les entrées enregistrées
+// avant l'ajout des langues n'ont pas de Lang et sont en DefaultLang.
+type storedWord struct {
+	Seq        uint64 `json:"seq"`
+	Word       string `json:"word"`
+	Lang       string `json:"lang,omitempty"`
+	Definition string `json:"definition"`
+	// Nature grammaticale facultative.
+	PartOfSpeech string    `json:"part_of_speech,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	// Clés de prononciation du mot (voir analysis.PhoneticKeys).
+	PhoneticKey string `json:"phonetic_key,omitempty"`
+	PhoneticAlt string `json:"phonetic_alt,omitempty"`
+	// Lettres du mot, telles quelles et triées (voir interfaces.WordLetters).
+	Letters       string `json:"letters,omitempty"`
+	SortedLetters string `json:"sorted_letters,omitempty"`
+}
+
+// withDerivedKeys renvoie w avec les clés phonétiques et les lettres de son mot.
+func (w storedWord) withDerivedKeys() storedWord {
+	w.PhoneticKey, w.PhoneticAlt = analysis.PhoneticKeys(w.lang(), w.Word)
+	w.Letters, w.SortedLetters = interfaces.WordLetters(w.Word), interfaces.SortedLetters(w.Word)
+	return w
+}
+
+func (w storedWord) lang() string {
+	if w.Lang == "" {
+		return interfaces.DefaultLang
+	}
+	return w.Lang
+}
+
+// entryKey identifie une entrée dans les tables par la clé de recherche de
+// son mot (voir interfaces.WordKey). Une entrée en DefaultLang n'a pas de
+// suffixe de langue, comme dans les fichiers antérieurs aux langues.
+func entryKey(word, lang string) string {
+	key := interfaces.WordKey(word)
+	if lang == interfaces.DefaultLang {
+		return key
+	}
+	return key + "\x01" + lang
+}
+
+// entryLang renvoie la langue d'une clé d'entrée, même si l'entrée n'existe plus.
+func entryLang(key string) string {
+	if _, lang, found := strings.Cut(key, "\x01"); found {
+		return lang
+	}
+	return interfaces.DefaultLang
+}
+
+// entryRef renvoie le mot enregistré et la langue d'une clé d'entrée.
+func (s *memoryStore) entryRef(key string) interfaces.EntryRef {
+	w := s.words.rows[key]
+	return interfaces.EntryRef{Word: w.Word, Lang: w.lang()}
+}
+
+// storedTranslation relie deux clés d'entrées, dans l'ordre de normalizeTranslation.
+type storedTranslation struct {
+	Seq  uint64 `json:"seq"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func translationKey(from, to string) string {
+	return from + "\x00" + to
+}
+
+// storedRelation est une relation sous sa forme normalisée (voir normalizeRelation),
+// entre deux clés d'entrées de même langue.
+type storedRelation struct {
+	Seq    uint64 `json:"seq"`
+	Word   string `json:"word"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// storedTag associe une étiquette normalisée à une clé d'entrée.
+type storedTag struct {
+	Seq  uint64 `json:"seq"`
+	Word string `json:"word"`
+	Tag  string `json:"tag"`
+}
+
+func tagKey(word, tag string) string {
+	return word + "\x00" + tag
+}
+
+// storedCollection range ses mots sous forme de clés d'entrées.
+type storedCollection struct {
+	Seq         uint64    `json:"seq"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Words       []string  `json:"words"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// storedRevision est une révision d'entrée (voir interfaces.Revision), rangée
+// sous son identifiant, tiré de la même séquence que les autres lignes.
+type storedRevision struct {
+	ID         uint64    `json:"id"`
+	Type       string    `json:"type"`
+	Word       string    `json:"word"`
+	Lang       string    `json:"lang"`
+	Definition string    `json:"definition,omitempty"`
+	User       string    `json:"user,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func revisionKey(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+func relationKey(word, relationType, target string) string {
+	return word + "\x00" + relationType + "\x00" + target
+}
+
+// change désigne une ligne modifiée ; value renvoie sa valeur actuelle ou false si elle a été supprimée.
+type change struct {
+	table string
+	key   string
+	value func() (interface{}, bool)
+}
+
+// changeSet accumule les lignes touchées par une modification et de quoi les annuler.
+type changeSet struct {
+	changes []change
+	undo    []func()
+}
+
+func (c *changeSet) rollback() {
+	for i := len(c.undo) - 1; i >= 0; i-- {
+		c.undo[i]()
+	}
+}
+
+// merge ajoute à c les lignes touchées par other, qui seront annulées avec c.
+func (c *changeSet) merge(other *changeSet) {
+	c.changes = append(c.changes, other.changes...)
+	c.undo = append(c.undo, other.undo...)
+}
+
+// table est une table clé/valeur en mémoire dont les modifications sont suivies.
+type table[T any] struct {
+	name string
+	rows map[string]T
+}
+
+func newTable[T any](name string) *table[T] {
+	return &table[T]{name: name, rows: make(map[string]T)}
+}
+
+func (t *table[T]) get(key string) (T, bool) {
+	v, ok := t.rows[key]
+	return v, ok
+}
+
+func (t *table[T]) put(c *changeSet, key string, v T) {
+	t.track(c, key)
+	t.rows[key] = v
+}
+
+func (t *table[T]) remove(c *changeSet, key string) {
+	t.track(c, key)
+	delete(t.rows, key)
+}
+
+func (t *table[T]) track(c *changeSet, key string) {
+	old, existed := t.rows[key]
+	c.undo = append(c.undo, func() {
+		if existed {
+			t.rows[key] = old
+		} else {
+			delete(t.rows, key)
+		}
+	})
+	c.changes = append(c.changes, change{table: t.name, key: key, value: func() (interface{}, bool) {
+		v, ok := t.rows[key]
+		return v, ok
+	}})
+}
+
+// load insère une ligne sérialisée en JSON, sans suivi (chargement initial).
+func (t *table[T]) load(key string, data []byte) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.rows[key] = v
+	return nil
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		words:        newTable[storedWord]("words"),
+		relations:    newTable[storedRelation]("relations"),
+		tags:         newTable[storedTag]("word_tags"),
+		collections:  newTable[storedCollection]("collections"),
+		translations: newTable[storedTranslation]("translations"),
+		revisions:    newTable[storedRevision]("revisions"),
+		searchIndex:  search.New(nil),
+		letterIndex:  newLetterIndex(),
+	}
+}
+
+// storeTable permet de charger et parcourir une table sans connaître le type de ses lignes.
+type storeTable interface {
+	load(key string, data []byte) error
+	each(fn func(key string, v interface{}))
+}
+
+func (t *table[T]) each(fn func(key string, v interface{})) {
+	for key, v := range t.rows {
+		fn(key, v)
+	}
+}
+
+// tables renvoie les tables du magasin par nom, pour le chargement et la sauvegarde complète.
+func (s *memoryStore) tables() map[string]storeTable {
+	return map[string]storeTable{
+		s.words.name:        s.words,
+		s.relations.name:    s.relations,
+		s.tags.name:         s.tags,
+		s.collections.name:  s.collections,
+		s.translations.name: s.translations,
+		s.revisions.name:    s.revisions,
+	}
+}
+
+// afterLoad recalcule les compteurs et les index après un chargement.
+func (s *memoryStore) afterLoad() {
+	words := make([]interfaces.Word, 0, len(s.words.rows))
+	keys := make([]string, 0, len(s.words.rows))
+	for key, w := range s.words.rows {
+		words = append(words, toWord(w))
+		keys = append(keys, key)
+	}
+	s.searchIndex = search.New(words)
+
+	// Dans l'ordre d'insertion, chaque entrée s'ajoute à la fin de ses listes.
+	sort.Slice(keys, func(i, j int) bool { return s.words.rows[keys[i]].Seq < s.words.rows[keys[j]].Seq })
+	s.letterIndex = newLetterIndex()
+	for _, key := range keys {
+		s.letterIndex.put(key, s.words.rows[key])
+	}
+
+	for _, w := range s.words.rows {
+		if w.Seq > s.seq {
+			s.seq = w.Seq
+		}
+	}
+	for _, r := range s.relations.rows {
+		if r.Seq > s.seq {
+			s.seq = r.Seq
+		}
+	}
+	for _, t := range s.tags.rows {
+		if t.Seq > s.seq {
+			s.seq = t.Seq
+		}
+	}
+	for _, c := range s.collections.rows {
+		if c.Seq > s.seq {
+			s.seq = c.Seq
+		}
+	}
+	for _, t := range s.translations.rows {
+		if t.Seq > s.seq {
+			s.seq = t.Seq
+		}
+	}
+	for _, r := range s.revisions.rows {
+		if r.ID > s.seq {
+			s.seq = r.ID
+		}
+	}
+}
+
+// rekey range sous entryKey les lignes des fichiers écrits avant les clés de
+// recherche, qui utilisaient le mot tel quel, normalise les mots et calcule
+// les clés phonétiques et les lettres manquantes. Elle renvoie
+// ErrWordKeyCollision si deux mots d'une langue ont la même clé.
+func (s *memoryStore) rekey(c *changeSet) error {
+	words := make([]keyedWord, 0, len(s.words.rows))
+	for _, w := range s.words.rows {
+		words = append(words, keyedWord{Word: w.Word, Lang: w.lang()})
+	}
+	if _, err := groupByKey(words); err != nil {
+		return err
+	}
+
+	renamed := make(map[string]string)
+	rekeyRows(c, s.words, func(key string, w storedWord) (string, storedWord, bool) {
+		newKey := entryKey(w.Word, w.lang())
+		if newKey != key {
+			renamed[key] = newKey
+		}
+		normalized := w
+		normalized.Word = interfaces.NormalizeWord(w.Word)
+		normalized = normalized.withDerivedKeys()
+		return newKey, normalized, newKey != key || normalized != w
+	})
+	if len(renamed) == 0 {
+		return nil
+	}
+	rename := func(key string) string {
+		if newKey, ok := renamed[key]; ok {
+			return newKey
+		}
+		return key
+	}
+
+	rekeyRows(c, s.relations, func(key string, r storedRelation) (string, storedRelation, bool) {
+		r.Word, r.Target = rename(r.Word), rename(r.Target)
+		if symmetricRelations[r.Type] && r.Target < r.Word {
+			r.Word, r.Target = r.Target, r.Word
+		}
+		newKey := relationKey(r.Word, r.Type, r.Target)
+		return newKey, r, newKey != key
+	})
+	rekeyRows(c, s.tags, func(key string, t storedTag) (string, storedTag, bool) {
+		t.Word = rename(t.Word)
+		newKey := tagKey(t.Word, t.Tag)
+		return newKey, t, newKey != key
+	})
+	rekeyRows(c, s.collections, func(key string, collection storedCollection) (string, storedCollection, bool) {
+		words := make([]string, len(collection.Words))
+		changed := false
+		for i, word := range collection.Words {
+			words[i] = rename(word)
+			changed = changed || words[i] != word
+		}
+		collection.Words = words
+		return key, collection, changed
+	})
+	rekeyRows(c, s.translations, func(key string, t storedTranslation) (string, storedTranslation, bool) {
+		t.From, t.To = rename(t.From), rename(t.To)
+		newKey := translationKey(t.From, t.To)
+		return newKey, t, newKey != key
+	})
+	return nil
+}
+
+// rekeyRows remplace les lignes de t que fn modifie, éventuellement sous une
+// nouvelle clé. Les anciennes lignes sont toutes retirées avant l'ajout des
+// nouvelles, pour qu'une clé libérée puisse être reprise.
+func rekeyRows[T any](c *changeSet, t *table[T], fn func(key string, v T) (string, T, bool)) {
+	type row struct {
+		key string
+		v   T
+	}
+	var moved []row
+	for key, v := range t.rows {
+		if newKey, v, changed := fn(key, v); changed {
+			moved = append(moved, row{key: newKey, v: v})
+			t.remove(c, key)
+		}
+	}
+	for _, r := range moved {
+		t.put(c, r.key, r.v)
+	}
+}
+
+// update exécute fn sous verrou exclusif ; en cas d'erreur de fn ou de la
+// persistance, toutes les lignes touchées reprennent leur valeur précédente.
+func (s *memoryStore) update(fn func(c *changeSet) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq := s.seq
+	c := &changeSet{}
+	if err := fn(c); err != nil {
+		c.rollback()
+		s.seq = seq
+		return err
+	}
+	if s.persist != nil && len(c.changes) > 0 {
+		if err := s.persist(c.changes); err != nil {
+			c.rollback()
+			s.seq = seq
+			return err
+		}
+	}
+	s.reindex(c.changes)
+	return nil
+}
+
+// reindex reporte dans les index de recherche et de lettres les mots touchés
+// par une modification validée.
+func (s *memoryStore) reindex(changes []change) {
+	for _, ch := range changes {
+		if ch.table != s.words.name {
+			continue
+		}
+		if v, ok := ch.value(); ok {
+			w := v.(storedWord)
+			s.searchIndex.Put(toWord(w))
+			s.letterIndex.put(ch.key, w)
+		} else {
+			word, _, _ := strings.Cut(ch.key, "\x01")
+			s.searchIndex.Delete(word, entryLang(ch.key))
+			s.letterIndex.remove(ch.key)
+		}
+	}
+}
+
+func (s *memoryStore) view(fn func()) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn()
+}
+
+func (s *memoryStore) nextSeq() uint64 {
+	s.seq++
+	return s.seq
+}
+
+// sortedWords renvoie les mots dans l'ordre d'insertion.
+func (s *memoryStore) sortedWords() []storedWord {
+	words := make([]storedWord, 0, len(s.words.rows))
+	for _, w := range s.words.rows {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool { return words[i].Seq < words[j].Seq })
+	return words
+}
+
+// addWord ajoute l'entrée, dont la langue est déjà normalisée, et la renvoie
+// telle qu'enregistrée.
+func (s *memoryStore) addWord(ctx context.Context, c *changeSet, entry interfaces.Word) (interfaces.Word, error) {
+	word := interfaces.NormalizeWord(entry.Word)
+	key := entryKey(word, entry.Lang)
+	if _, exists := s.words.get(key); exists {
+		return interfaces.Word{}, interfaces.ErrWordExists
+	}
+	now := time.Now()
+	stored := storedWord{
+		Seq:          s.nextSeq(),
+		Word:         word,
+		Lang:         entry.Lang,
+		Definition:   entry.Definition,
+		PartOfSpeech: entry.PartOfSpeech,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}.withDerivedKeys()
+	s.words.put(c, key, stored)
+	s.recordRevision(ctx, c, dictionary.EventWordAdded, toWord(stored))
+	return toWord(stored), nil
+}
+
+// updateWord remplace la définition de l'entrée et la renvoie telle qu'enregistrée.
+func (s *memoryStore) updateWord(ctx context.Context, c *changeSet, word, lang, newDefinition string) (interfaces.Word, error) {
+	key := entryKey(word, lang)
+	existing, exists := s.words.get(key)
+	if !exists {
+		return interfaces.Word{}, interfaces.ErrWordNotFound
+	}
+	existing.Definition = newDefinition
+	existing.UpdatedAt = time.Now()
+	s.words.put(c, key, existing.withDerivedKeys())
+	s.recordRevision(ctx, c, dictionary.EventWordUpdated, toWord(existing))
+	return toWord(existing), nil
+}
+
+// deleteWord supprime l'entrée et la renvoie telle qu'elle était enregistrée.
+func (s *memoryStore) deleteWord(ctx context.Context, c *changeSet, word, lang string) (interfaces.Word, error) {
+	entry := entryKey(word, lang)
+	existing, exists := s.words.get(entry)
+	if !exists {
+		return interfaces.Word{}, interfaces.ErrWordNotFound
+	}
+	for key, r := range s.relations.rows {
+		if r.Word == entry || r.Target == entry {
+			s.relations.remove(c, key)
+		}
+	}
+	for key, t := range s.tags.rows {
+		if t.Word == entry {
+			s.tags.remove(c, key)
+		}
+	}
+	for name, collection := range s.collections.rows {
+		if words := withoutWord(collection.Words, entry); len(words) != len(collection.Words) {
+			collection.Words = words
+			s.collections.put(c, name, collection)
+		}
+	}
+	for key, t := range s.translations.rows {
+		if t.From == entry || t.To == entry {
+			s.translations.remove(c, key)
+		}
+	}
+	s.words.remove(c, entry)
+	s.recordRevision(ctx, c, dictionary.EventWordRemoved, interfaces.Word{Word: existing.Word, Lang: existing.lang()})
+	return toWord(existing), nil
+}
+
+// recordRevision enregistre la modification de l'entrée dans c, avec
+// l'utilisateur du contexte : elle est annulée avec la modification.
+func (s *memoryStore) recordRevision(ctx context.Context, c *changeSet, revisionType string, entry interfaces.Word) {
+	id := s.nextSeq()
+	s.revisions.put(c, revisionKey(id), storedRevision{
+		ID:         id,
+		Type:       revisionType,
+		Word:       entry.Word,
+		Lang:       entry.Lang,
+		Definition: entry.Definition,
+		User:       requestctx.User(ctx),
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+func (s *memoryStore) applyOperation(ctx context.Context, c *changeSet, op interfaces.BatchOperation) (interfaces.Word, error) {
+	lang, err := interfaces.NormalizeLang(op.Lang)
+	if err != nil {
+		return interfaces.Word{}, err
+	}
+	switch op.Op {
+	case interfaces.BatchAdd:
+		return s.addWord(ctx, c, interfaces.Word{Word: op.Word, Lang: lang, Definition: op.Definition, PartOfSpeech: op.PartOfSpeech})
+	case interfaces.BatchDefine:
+		return s.updateWord(ctx, c, op.Word, lang, op.Definition)
+	case interfaces.BatchRemove:
+		return s.deleteWord(ctx, c, op.Word, lang)
+	default:
+		return interfaces.Word{}, interfaces.ErrUnknownBatchOp
+	}
+}
+
+// hasWords indique si toutes les clés d'entrées existent.
+func (s *memoryStore) hasWords(keys ...string) bool {
+	for _, key := range keys {
+		if _, exists := s.words.get(key); !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutWord renvoie une copie de words sans word.
+func withoutWord(words []string, word string) []string {
+	result := make([]string, 0, len(words))
+	for _, w := range words {
+		if w != word {
+			result = append(result, w)
+		}
+	}
+	return result
+}
@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Les migrations sont embarquées dans le binaire : NNNN_nom.up.sql et NNNN_nom.down.sql.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var ErrSchemaAhead = errors.New("la base de données est plus récente que ce binaire")
+
+// ErrSchemaBehind signale une base dont des migrations sont en attente.
+var ErrSchemaBehind = errors.New("la base de données n'est pas migrée (voir « migrate up »)")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+
+	// afterUp complète en Go une migration que le SQL seul ne peut pas exprimer.
+	afterUp func(tx *gorm.DB) error
+}
+
+// migrationHooks associe à une version le traitement exécuté après son SQL,
+// dans la même transaction.
+var migrationHooks = map[int]func(tx *gorm.DB) error{
+	7:  backfillWordKeys,
+	8:  backfillPhoneticKeys,
+	9:  backfillWordLetters,
+	14: backfillSearchTerms,
+	15: backfillLetterCounts,
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// schemaMigration est une ligne de la table schema_migrations.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applique et annule les migrations embarquées sur une base SQLite.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	err = db.Exec("CREATE TABLE IF NOT EXISTS `schema_migrations` (`version` integer PRIMARY KEY, `name` text NOT NULL, `applied_at` datetime NOT NULL)").Error
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+		direction := ""
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s : suffixe .up.sql ou .down.sql attendu", base)
+		}
+
+		prefix, name, found := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s : nom NNNN_nom attendu", base)
+		}
+
+		content, err := migrationFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s : fichiers up et down requis", m.Version, m.Name)
+		}
+		m.afterUp = migrationHooks[m.Version]
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %04d manquante", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// LatestVersion renvoie la version la plus récente connue de ce binaire.
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion renvoie la version la plus haute appliquée à la base.
+func (m *Migrator) CurrentVersion(ctx context.Context) (int, error) {
+	var version int
+	err := m.db.WithContext(ctx).Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// CheckCompatible refuse une base migrée par un binaire plus récent.
+func (m *Migrator) CheckCompatible(ctx context.Context) error {
+	current, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current > m.LatestVersion() {
+		return fmt.Errorf("%w : version %d, ce binaire connaît jusqu'à la version %d", ErrSchemaAhead, current, m.LatestVersion())
+	}
+	return nil
+}
+
+// checkSchemaCurrent vérifie, sans rien écrire, que la base est exactement à
+// la dernière version connue de ce binaire.
+func checkSchemaCurrent(db *gorm.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	version := 0
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		if err := db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+			return err
+		}
+	}
+	latest := migrations[len(migrations)-1].Version
+	switch {
+	case version < latest:
+		return fmt.Errorf("%w : version %d, ce binaire attend la version %d", ErrSchemaBehind, version, latest)
+	case version > latest:
+		return fmt.Errorf("%w : version %d, ce binaire connaît jusqu'à la version %d", ErrSchemaAhead, version, latest)
+	}
+	return nil
+}
+
+// Up applique les migrations en attente, au plus steps (toutes si steps <= 0).
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.CheckCompatible(ctx); err != nil {
+		return nil, err
+	}
+	current, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if steps > 0 && len(applied) == steps {
+			break
+		}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			if migration.afterUp != nil {
+				if err := migration.afterUp(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %04d_%s : %w", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// Down annule les steps dernières migrations appliquées (une seule si steps <= 0).
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.CheckCompatible(ctx); err != nil {
+		return nil, err
+	}
+	if steps <= 0 {
+		steps = 1
+	}
+
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := m.migrations[i]
+		var count int64
+		if err := m.db.WithContext(ctx).Model(&schemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return reverted, err
+		}
+		if count == 0 {
+			continue
+		}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("annulation de %04d_%s : %w", migration.Version, migration.Name, err)
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// Status renvoie l'état de chaque migration connue, suivi des versions inconnues appliquées à la base.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var rows []schemaMigration
+	if err := m.db.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		row, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{Version: migration.Version, Name: migration.Name, Applied: ok, AppliedAt: row.AppliedAt})
+		delete(applied, migration.Version)
+	}
+	for _, row := range rows {
+		if _, unknown := applied[row.Version]; unknown {
+			statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, Applied: true, AppliedAt: row.AppliedAt})
+		}
+	}
+	return statuses, nil
+}
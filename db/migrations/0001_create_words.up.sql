@@ -0,0 +1,10 @@
+-- IF NOT EXISTS : reprend les bases créées auparavant par AutoMigrate.
+CREATE TABLE IF NOT EXISTS `words` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`word` text NOT NULL UNIQUE,
+	`definition` text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_words_deleted_at` ON `words`(`deleted_at`);
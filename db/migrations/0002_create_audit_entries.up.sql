@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `audit_entries` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`username` text,
+	`action` text NOT NULL,
+	`word` text NOT NULL,
+	`before` text,
+	`after` text,
+	`client_ip` text,
+	`request_id` text
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_entries_created_at` ON `audit_entries`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_audit_entries_username` ON `audit_entries`(`username`);
+CREATE INDEX IF NOT EXISTS `idx_audit_entries_word` ON `audit_entries`(`word`);
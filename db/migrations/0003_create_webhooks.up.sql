@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS `webhooks` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`created_by` text,
+	`url` text NOT NULL,
+	`secret` text NOT NULL,
+	`event_types` text
+);
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`webhook_id` integer NOT NULL,
+	`event_id` integer NOT NULL,
+	`event_type` text NOT NULL,
+	`payload` text,
+	`attempt` integer NOT NULL,
+	`status` text NOT NULL,
+	`status_code` integer,
+	`error` text,
+	`duration_ms` integer
+);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_webhook_id` ON `webhook_deliveries`(`webhook_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_status` ON `webhook_deliveries`(`status`);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `word_relations` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`word_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`related_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`type` text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_word_relations_unique` ON `word_relations`(`word_id`, `related_id`, `type`);
+CREATE INDEX IF NOT EXISTS `idx_word_relations_related_id` ON `word_relations`(`related_id`);
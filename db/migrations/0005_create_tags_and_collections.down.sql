@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `collection_words`;
+DROP TABLE IF EXISTS `collections`;
+DROP TABLE IF EXISTS `word_tags`;
+DROP TABLE IF EXISTS `tags`;
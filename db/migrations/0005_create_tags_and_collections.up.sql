@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS `tags` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`name` text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_tags_name` ON `tags`(`name`);
+CREATE TABLE IF NOT EXISTS `word_tags` (
+	`word_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`tag_id` integer NOT NULL REFERENCES `tags`(`id`) ON DELETE CASCADE,
+	`created_at` datetime,
+	PRIMARY KEY (`word_id`, `tag_id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_word_tags_tag_id` ON `word_tags`(`tag_id`);
+CREATE TABLE IF NOT EXISTS `collections` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`name` text NOT NULL,
+	`description` text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_collections_name` ON `collections`(`name`);
+CREATE TABLE IF NOT EXISTS `collection_words` (
+	`collection_id` integer NOT NULL REFERENCES `collections`(`id`) ON DELETE CASCADE,
+	`word_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`position` integer NOT NULL,
+	PRIMARY KEY (`collection_id`, `word_id`)
+);
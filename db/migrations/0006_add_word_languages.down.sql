@@ -0,0 +1,18 @@
+-- Seules les entrées en français sont conservées, avec ce qui s'y rattache.
+DROP TABLE IF EXISTS `word_translations`;
+CREATE TABLE `words_without_lang` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`word` text NOT NULL UNIQUE,
+	`definition` text NOT NULL
+);
+INSERT INTO `words_without_lang` (`id`, `created_at`, `updated_at`, `deleted_at`, `word`, `definition`)
+	SELECT `id`, `created_at`, `updated_at`, `deleted_at`, `word`, `definition` FROM `words` WHERE `lang` = 'fr';
+DROP TABLE `words`;
+ALTER TABLE `words_without_lang` RENAME TO `words`;
+CREATE INDEX `idx_words_deleted_at` ON `words`(`deleted_at`);
+DELETE FROM `word_relations` WHERE `word_id` NOT IN (SELECT `id` FROM `words`) OR `related_id` NOT IN (SELECT `id` FROM `words`);
+DELETE FROM `word_tags` WHERE `word_id` NOT IN (SELECT `id` FROM `words`);
+DELETE FROM `collection_words` WHERE `word_id` NOT IN (SELECT `id` FROM `words`);
@@ -0,0 +1,26 @@
+-- SQLite ne sait pas supprimer une contrainte UNIQUE : la table words est
+-- reconstruite pour que l'unicité porte sur le mot et sa langue.
+CREATE TABLE `words_with_lang` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`word` text NOT NULL,
+	`lang` text NOT NULL DEFAULT 'fr',
+	`definition` text NOT NULL
+);
+INSERT INTO `words_with_lang` (`id`, `created_at`, `updated_at`, `deleted_at`, `word`, `lang`, `definition`)
+	SELECT `id`, `created_at`, `updated_at`, `deleted_at`, `word`, 'fr', `definition` FROM `words`;
+DROP TABLE `words`;
+ALTER TABLE `words_with_lang` RENAME TO `words`;
+CREATE UNIQUE INDEX `idx_words_word_lang` ON `words`(`word`, `lang`);
+CREATE INDEX `idx_words_lang` ON `words`(`lang`);
+CREATE INDEX `idx_words_deleted_at` ON `words`(`deleted_at`);
+CREATE TABLE IF NOT EXISTS `word_translations` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`word_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`translated_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_word_translations_unique` ON `word_translations`(`word_id`, `translated_id`);
+CREATE INDEX IF NOT EXISTS `idx_word_translations_translated_id` ON `word_translations`(`translated_id`);
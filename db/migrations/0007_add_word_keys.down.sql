@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_words_key_lang`;
+ALTER TABLE `words` DROP COLUMN `word_key`;
+CREATE UNIQUE INDEX `idx_words_word_lang` ON `words`(`word`, `lang`);
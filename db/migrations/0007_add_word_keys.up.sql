@@ -0,0 +1,7 @@
+-- La clé de recherche (sans casse ni accents) porte désormais l'unicité avec
+-- la langue. Elle est provisoirement distincte pour chaque ligne : la migration
+-- la calcule ensuite en Go et refuse les mots qui se confondraient.
+ALTER TABLE `words` ADD COLUMN `word_key` text NOT NULL DEFAULT '';
+UPDATE `words` SET `word_key` = char(1) || `id`;
+DROP INDEX IF EXISTS `idx_words_word_lang`;
+CREATE UNIQUE INDEX `idx_words_key_lang` ON `words`(`word_key`, `lang`);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_words_lang_phonetic_alt`;
+DROP INDEX IF EXISTS `idx_words_lang_phonetic_key`;
+ALTER TABLE `words` DROP COLUMN `phonetic_alt`;
+ALTER TABLE `words` DROP COLUMN `phonetic_key`;
@@ -0,0 +1,6 @@
+-- Clés de prononciation des mots (voir analysis.PhoneticKeys), calculées
+-- ensuite en Go pour les mots existants.
+ALTER TABLE `words` ADD COLUMN `phonetic_key` text NOT NULL DEFAULT '';
+ALTER TABLE `words` ADD COLUMN `phonetic_alt` text NOT NULL DEFAULT '';
+CREATE INDEX `idx_words_lang_phonetic_key` ON `words`(`lang`, `phonetic_key`);
+CREATE INDEX `idx_words_lang_phonetic_alt` ON `words`(`lang`, `phonetic_alt`);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_words_sorted_letters`;
+DROP INDEX IF EXISTS `idx_words_letters`;
+ALTER TABLE `words` DROP COLUMN `sorted_letters`;
+ALTER TABLE `words` DROP COLUMN `letters`;
@@ -0,0 +1,10 @@
+-- Lettres des mots, telles quelles et triées (voir interfaces.WordLetters),
+-- pour les motifs et les anagrammes ; calculées ensuite en Go pour les mots
+-- existants.
+ALTER TABLE `words` ADD COLUMN `letters` text NOT NULL DEFAULT '';
+ALTER TABLE `words` ADD COLUMN `sorted_letters` text NOT NULL DEFAULT '';
+CREATE INDEX `idx_words_letters` ON `words`(`letters`);
+CREATE INDEX `idx_words_sorted_letters` ON `words`(`sorted_letters`, `lang`);
+-- Sans statistiques, SQLite préfère l'index de deleted_at, commun à toutes
+-- les requêtes, à ceux des lettres.
+ANALYZE `words`;
@@ -0,0 +1 @@
+ALTER TABLE `words` DROP COLUMN `part_of_speech`;
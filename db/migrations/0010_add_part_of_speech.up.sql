@@ -0,0 +1,2 @@
+-- Nature grammaticale facultative de l'entrée (voir config.ValidationConfig.RequiredFields).
+ALTER TABLE `words` ADD COLUMN `part_of_speech` text NOT NULL DEFAULT '';
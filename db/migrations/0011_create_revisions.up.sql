@@ -0,0 +1,11 @@
+-- Une ligne par ajout, modification ou suppression d'entrée, écrite dans la
+-- transaction de la modification. AUTOINCREMENT : un identifiant publié n'est jamais réutilisé.
+CREATE TABLE IF NOT EXISTS `revisions` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime NOT NULL,
+	`type` text NOT NULL,
+	`word` text NOT NULL,
+	`lang` text NOT NULL,
+	`definition` text NOT NULL DEFAULT '',
+	`username` text NOT NULL DEFAULT ''
+);
@@ -0,0 +1,14 @@
+-- Une ligne par événement à livrer à un webhook, écrite dans la transaction de
+-- la modification et supprimée une fois la livraison réussie ou abandonnée.
+CREATE TABLE IF NOT EXISTS `webhook_outbox` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime NOT NULL,
+	`webhook_id` integer NOT NULL,
+	`event_id` integer NOT NULL,
+	`event_type` text NOT NULL,
+	`payload` text NOT NULL,
+	`attempts` integer NOT NULL DEFAULT 0,
+	`next_attempt_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_webhook_outbox_webhook_event` ON `webhook_outbox`(`webhook_id`, `event_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_outbox_next_attempt_at` ON `webhook_outbox`(`next_attempt_at`);
@@ -0,0 +1,12 @@
+-- Réponses des requêtes portant un en-tête Idempotency-Key, conservées jusqu'à
+-- expires_at. status vaut 0 tant que la première requête est en cours.
+CREATE TABLE IF NOT EXISTS `idempotency_keys` (
+	`key` text PRIMARY KEY,
+	`request_hash` text NOT NULL,
+	`status` integer NOT NULL DEFAULT 0,
+	`header` text NOT NULL DEFAULT '',
+	`body` blob,
+	`created_at` datetime NOT NULL,
+	`expires_at` datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_idempotency_keys_expires_at` ON `idempotency_keys`(`expires_at`);
@@ -0,0 +1,11 @@
+-- Termes des mots et des définitions (voir search.Postings), remplacés dans la
+-- transaction de chaque modification d'entrée ; calculés ensuite en Go pour
+-- les mots existants.
+CREATE TABLE IF NOT EXISTS `search_terms` (
+	`word_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`field` text NOT NULL,
+	`term` text NOT NULL,
+	`lang` text NOT NULL,
+	PRIMARY KEY (`word_id`, `field`, `term`)
+);
+CREATE INDEX IF NOT EXISTS `idx_search_terms_lang_term` ON `search_terms`(`lang`, `term`);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_words_letter_count`;
+DROP TABLE IF EXISTS `word_letters`;
@@ -0,0 +1,14 @@
+-- Nombre d'occurrences de chaque lettre des mots (voir interfaces.WordLetters),
+-- remplacé dans la transaction de chaque modification d'entrée ; calculé
+-- ensuite en Go pour les mots existants. Les mots qui contiennent une lettre
+-- sont lus dans la clé, par ordre d'identifiant, plutôt qu'en parcourant words.
+CREATE TABLE IF NOT EXISTS `word_letters` (
+	`letter` text NOT NULL,
+	`word_id` integer NOT NULL REFERENCES `words`(`id`) ON DELETE CASCADE,
+	`count` integer NOT NULL,
+	PRIMARY KEY (`letter`, `word_id`)
+) WITHOUT ROWID;
+CREATE INDEX IF NOT EXISTS `idx_word_letters_word_id` ON `word_letters`(`word_id`);
+-- Longueur des mots, pour ne lire que ceux qui ont celle d'un motif.
+CREATE INDEX IF NOT EXISTS `idx_words_letter_count` ON `words`(length(`letters`), `lang`);
+ANALYZE `words`;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_audit_entries_lang`;
+ALTER TABLE `audit_entries` DROP COLUMN `lang`;
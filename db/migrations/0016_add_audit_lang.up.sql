@@ -0,0 +1,3 @@
+-- Langue de l'entrée concernée ; vide pour les entrées d'audit antérieures.
+ALTER TABLE `audit_entries` ADD COLUMN `lang` text NOT NULL DEFAULT '';
+CREATE INDEX `idx_audit_entries_lang` ON `audit_entries`(`lang`);
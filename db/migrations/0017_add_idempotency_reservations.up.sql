@@ -0,0 +1,5 @@
+-- Fin de la réservation d'une requête en cours (status 0) : passé ce délai,
+-- la requête est tenue pour interrompue et une nouvelle tentative reprend la
+-- clé. Les réservations existantes sont reprises dès la prochaine tentative.
+ALTER TABLE `idempotency_keys` ADD COLUMN `reserved_until` datetime;
+UPDATE `idempotency_keys` SET `reserved_until` = `created_at`;
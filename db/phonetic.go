@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"tp2/analysis"
+	"tp2/dictionary"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+// soundKeys renvoie les clés phonétiques non vides de word dans la langue lang.
+func soundKeys(lang, word string) []string {
+	var keys []string
+	key, alt := analysis.PhoneticKeys(lang, word)
+	for _, k := range []string{key, alt} {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// soundsLike indique si l'une des clés d'une entrée est parmi keys.
+func soundsLike(keys []string, key, alt string) bool {
+	for _, k := range keys {
+		if k == key || (alt != "" && k == alt) {
+			return true
+		}
+	}
+	return false
+}
+
+// backfillPhoneticKeys calcule les clés phonétiques des mots existants.
+func backfillPhoneticKeys(tx *gorm.DB) error {
+	var words []dictionary.Word
+	if err := tx.Select("id, word, lang").Order("id").Find(&words).Error; err != nil {
+		return err
+	}
+	for _, w := range words {
+		w.PhoneticKey, w.PhoneticAlt = analysis.PhoneticKeys(w.Lang, w.Word)
+		update := map[string]any{"phonetic_key": w.PhoneticKey, "phonetic_alt": w.PhoneticAlt}
+		if err := tx.Table("words").Where("id = ?", w.ID).Updates(update).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GormWordRepository) ListWordsBySound(ctx context.Context, word, lang string) ([]interfaces.Word, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	langs, err := entryLangs(db, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chaque langue a ses propres clés : une condition par langue.
+	var conditions []string
+	var args []any
+	for _, l := range langs {
+		if keys := soundKeys(l, word); len(keys) > 0 {
+			conditions = append(conditions, "(lang = ? AND (phonetic_key IN ? OR phonetic_alt IN ?))")
+			args = append(args, l, keys, keys)
+		}
+	}
+	result := []interfaces.Word{}
+	if len(conditions) == 0 {
+		return result, nil
+	}
+
+	var words []dictionary.Word
+	if err := db.Where(strings.Join(conditions, " OR "), args...).Order("id").Find(&words).Error; err != nil {
+		return nil, err
+	}
+	for _, w := range words {
+		result = append(result, fromRecord(w))
+	}
+	return result, nil
+}
+
+func (r *storeRepository) ListWordsBySound(ctx context.Context, word, lang string) ([]interfaces.Word, error) {
+	keys := make(map[string][]string)
+	words := []interfaces.Word{}
+	s := r.store()
+	s.view(func() {
+		for _, w := range s.sortedWords() {
+			if lang != "" && w.lang() != lang {
+				continue
+			}
+			if _, ok := keys[w.lang()]; !ok {
+				keys[w.lang()] = soundKeys(w.lang(), word)
+			}
+			if soundsLike(keys[w.lang()], w.PhoneticKey, w.PhoneticAlt) {
+				words = append(words, toWord(w))
+			}
+		}
+	})
+	return words, nil
+}
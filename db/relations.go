@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tp2/dictionary"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionRelate   = "relate"
+	AuditActionUnrelate = "unrelate"
+)
+
+var symmetricRelations = map[string]bool{
+	interfaces.RelationSynonym: true,
+	interfaces.RelationAntonym: true,
+	interfaces.RelationSeeAlso: true,
+}
+
+// normalizeRelation renvoie la forme stockée d'une relation : un hyponyme est
+// enregistré comme l'hyperonyme inverse et les relations symétriques dans
+// l'ordre de leurs clés, pour qu'un même lien n'existe qu'une fois.
+func normalizeRelation(word, relationType, target string) (string, string, string, error) {
+	if interfaces.WordKey(word) == interfaces.WordKey(target) {
+		return "", "", "", fmt.Errorf("%w : un mot ne peut pas être relié à lui-même", interfaces.ErrInvalidRelation)
+	}
+	switch {
+	case relationType == interfaces.RelationHyponym:
+		return target, interfaces.RelationHypernym, word, nil
+	case relationType == interfaces.RelationHypernym:
+		return word, relationType, target, nil
+	case symmetricRelations[relationType]:
+		if interfaces.WordKey(target) < interfaces.WordKey(word) {
+			word, target = target, word
+		}
+		return word, relationType, target, nil
+	default:
+		return "", "", "", fmt.Errorf("%w : type inconnu %q", interfaces.ErrInvalidRelation, relationType)
+	}
+}
+
+// orientRelation présente une relation stockée du point de vue de word.
+func orientRelation(word, source, relationType, target string) interfaces.Relation {
+	if interfaces.WordKey(source) == interfaces.WordKey(word) {
+		return interfaces.Relation{Type: relationType, Target: target}
+	}
+	if relationType == interfaces.RelationHypernym {
+		relationType = interfaces.RelationHyponym
+	}
+	return interfaces.Relation{Type: relationType, Target: source}
+}
+
+// RelationRecord est une ligne de la table de jointure word_relations.
+type RelationRecord struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	WordID    uint   `gorm:"not null"`
+	RelatedID uint   `gorm:"not null"`
+	Type      string `gorm:"not null"`
+}
+
+func (RelationRecord) TableName() string {
+	return "word_relations"
+}
+
+func (g *GormWordRepository) AddRelation(ctx context.Context, word, lang, relationType, target string) error {
+	source, relationType, target, err := normalizeRelation(word, relationType, target)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		sourceID, targetID, err := relationWordIDs(tx, lang, source, target)
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&RelationRecord{WordID: sourceID, RelatedID: targetID, Type: relationType}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return interfaces.ErrRelationExists
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionRelate, source, lang, "", relationType+":"+target)
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) RemoveRelation(ctx context.Context, word, lang, relationType, target string) error {
+	source, relationType, target, err := normalizeRelation(word, relationType, target)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		sourceID, targetID, err := relationWordIDs(tx, lang, source, target)
+		if err != nil {
+			return err
+		}
+		result := tx.Where("word_id = ? AND related_id = ? AND type = ?", sourceID, targetID, relationType).Delete(&RelationRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return interfaces.ErrRelationNotFound
+		}
+		return recordAudit(ctx, tx, AuditActionUnrelate, source, lang, relationType+":"+target, "")
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) ListRelations(ctx context.Context, word, lang string) ([]interfaces.Relation, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := findWord(db, word, lang)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var rows []struct {
+		Source string
+		Type   string
+		Target string
+	}
+	err = db.Table("word_relations AS r").
+		Select("s.word AS source, r.type AS type, t.word AS target").
+		Joins("JOIN words s ON s.id = r.word_id").
+		Joins("JOIN words t ON t.id = r.related_id").
+		Where("r.word_id = ? OR r.related_id = ?", existing.ID, existing.ID).
+		Order("r.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	relations := make([]interfaces.Relation, len(rows))
+	for i, row := range rows {
+		relations[i] = orientRelation(word, row.Source, row.Type, row.Target)
+	}
+	return relations, nil
+}
+
+func (g *GormWordRepository) ListRelationEdges(ctx context.Context, lang string, words []string) ([]interfaces.RelationEdge, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := db.Table("word_relations AS r").
+		Select("r.type AS type, s.word AS source, s.definition AS source_definition, t.word AS target, t.definition AS target_definition").
+		Joins("JOIN words s ON s.id = r.word_id").
+		Joins("JOIN words t ON t.id = r.related_id").
+		Where("s.lang = ?", lang)
+	if len(words) > 0 {
+		keys := make([]string, len(words))
+		for i, word := range words {
+			keys[i] = interfaces.WordKey(word)
+		}
+		ids := db.Model(&dictionary.Word{}).Select("id").Where("word_key IN ? AND lang = ?", keys, lang)
+		query = query.Where("r.word_id IN (?) OR r.related_id IN (?)", ids, ids)
+	}
+
+	var rows []struct {
+		Type             string
+		Source           string
+		SourceDefinition string
+		Target           string
+		TargetDefinition string
+	}
+	if err := query.Order("r.id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	edges := make([]interfaces.RelationEdge, len(rows))
+	for i, row := range rows {
+		edges[i] = interfaces.RelationEdge{
+			Word:   interfaces.Word{Word: row.Source, Lang: lang, Definition: row.SourceDefinition},
+			Type:   row.Type,
+			Target: interfaces.Word{Word: row.Target, Lang: lang, Definition: row.TargetDefinition},
+		}
+	}
+	return edges, nil
+}
+
+func (g *GormWordRepository) OrphanRelations(ctx context.Context) ([]interfaces.OrphanRelation, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orphans := []interfaces.OrphanRelation{}
+	err = db.Table("word_relations AS r").
+		Select("COALESCE(s.lang, t.lang, '') AS lang, COALESCE(s.word, '') AS word, r.type AS type, COALESCE(t.word, '') AS target").
+		Joins("LEFT JOIN words s ON s.id = r.word_id").
+		Joins("LEFT JOIN words t ON t.id = r.related_id").
+		Where("s.id IS NULL OR t.id IS NULL").
+		Order("r.id").
+		Scan(&orphans).Error
+	return orphans, err
+}
+
+func (g *GormWordRepository) DeleteOrphanRelations(ctx context.Context) (int, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.Where("word_id NOT IN (SELECT id FROM words) OR related_id NOT IN (SELECT id FROM words)").Delete(&RelationRecord{})
+	return int(result.RowsAffected), translateError(result.Error)
+}
+
+func relationWordIDs(tx *gorm.DB, lang, source, target string) (uint, uint, error) {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return 0, 0, err
+	}
+	var words []dictionary.Word
+	sourceKey, targetKey := interfaces.WordKey(source), interfaces.WordKey(target)
+	if err := tx.Where("word_key IN ? AND lang = ?", []string{sourceKey, targetKey}, lang).Find(&words).Error; err != nil {
+		return 0, 0, err
+	}
+	var sourceID, targetID uint
+	for _, w := range words {
+		switch w.Key {
+		case sourceKey:
+			sourceID = w.ID
+		case targetKey:
+			targetID = w.ID
+		}
+	}
+	if sourceID == 0 || targetID == 0 {
+		return 0, 0, interfaces.ErrWordNotFound
+	}
+	return sourceID, targetID, nil
+}
+
+// deleteWordRelations supprime les relations d'un mot avant sa suppression.
+func deleteWordRelations(tx *gorm.DB, wordID uint) error {
+	return tx.Where("word_id = ? OR related_id = ?", wordID, wordID).Delete(&RelationRecord{}).Error
+}
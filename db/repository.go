@@ -0,0 +1,30 @@
+package db
+
+import (
+	"fmt"
+	"tp2/interfaces"
+)
+
+// Pilotes de stockage acceptés par storage.driver.
+const (
+	DriverSQLite = "sqlite"
+	DriverMemory = "memory"
+	DriverJSON   = "json"
+	DriverBolt   = "bolt"
+)
+
+// NewWordRepository renvoie le dépôt correspondant au pilote, à initialiser avec InitializeDB.
+func NewWordRepository(driver string) (interfaces.WordRepository, error) {
+	switch driver {
+	case DriverSQLite, "":
+		return &GormWordRepository{}, nil
+	case DriverMemory:
+		return &MemoryWordRepository{}, nil
+	case DriverJSON:
+		return &JSONFileWordRepository{}, nil
+	case DriverBolt:
+		return &BoltWordRepository{}, nil
+	default:
+		return nil, fmt.Errorf("pilote de stockage inconnu : %q", driver)
+	}
+}
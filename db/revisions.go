@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"time"
+	"tp2/interfaces"
+	"tp2/requestctx"
+
+	"gorm.io/gorm"
+)
+
+// RevisionRecord est une ligne de la table revisions, ajoutée dans la
+// transaction de chaque modification d'entrée.
+type RevisionRecord struct {
+	ID         uint64 `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	Type       string `gorm:"not null"`
+	Word       string `gorm:"not null"`
+	Lang       string `gorm:"not null"`
+	Definition string `gorm:"not null"`
+	Username   string `gorm:"not null"`
+}
+
+func (RevisionRecord) TableName() string {
+	return "revisions"
+}
+
+// recordRevision enregistre la modification de l'entrée dans la transaction tx,
+// avec l'utilisateur du contexte, et la met dans la boîte d'envoi des webhooks.
+func recordRevision(ctx context.Context, tx *gorm.DB, revisionType string, entry interfaces.Word) error {
+	revision := RevisionRecord{
+		CreatedAt:  time.Now().UTC(),
+		Type:       revisionType,
+		Word:       entry.Word,
+		Lang:       entry.Lang,
+		Definition: entry.Definition,
+		Username:   requestctx.User(ctx),
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return err
+	}
+	return enqueueDeliveries(tx, revision)
+}
+
+func (g *GormWordRepository) ListRevisions(ctx context.Context, afterID uint64, limit int) ([]interfaces.Revision, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := db.Where("id > ?", afterID).Order("id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var records []RevisionRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	revisions := make([]interfaces.Revision, len(records))
+	for i, r := range records {
+		revisions[i] = interfaces.Revision{
+			ID:         r.ID,
+			Type:       r.Type,
+			Word:       r.Word,
+			Lang:       r.Lang,
+			Definition: r.Definition,
+			User:       r.Username,
+			CreatedAt:  r.CreatedAt,
+		}
+	}
+	return revisions, nil
+}
+
+func (g *GormWordRepository) LastRevisionID(ctx context.Context) (uint64, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var id uint64
+	err = db.Model(&RevisionRecord{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
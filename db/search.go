@@ -0,0 +1,264 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"strings"
+	"tp2/dictionary"
+	"tp2/interfaces"
+	"tp2/search"
+
+	"gorm.io/gorm"
+)
+
+// SearchTermRecord est une ligne de la table search_terms : un terme du mot
+// ou de la définition d'une entrée (voir search.Postings), remplacé dans la
+// transaction de chaque modification de l'entrée.
+type SearchTermRecord struct {
+	WordID uint   `gorm:"primaryKey"`
+	Field  string `gorm:"primaryKey"`
+	Term   string `gorm:"primaryKey"`
+	Lang   string `gorm:"not null"`
+}
+
+func (SearchTermRecord) TableName() string {
+	return "search_terms"
+}
+
+// indexSearchTerms remplace dans tx les termes de l'entrée d'identifiant id.
+func indexSearchTerms(tx *gorm.DB, id uint, entry interfaces.Word) error {
+	if err := deleteSearchTerms(tx, id); err != nil {
+		return err
+	}
+	postings := search.Postings(entry)
+	if len(postings) == 0 {
+		return nil
+	}
+	records := make([]SearchTermRecord, len(postings))
+	for i, p := range postings {
+		records[i] = SearchTermRecord{WordID: id, Field: p.Field, Term: p.Term, Lang: entry.Lang}
+	}
+	return tx.Create(&records).Error
+}
+
+func deleteSearchTerms(tx *gorm.DB, id uint) error {
+	return tx.Where("word_id = ?", id).Delete(&SearchTermRecord{}).Error
+}
+
+// backfillSearchTerms indexe les mots existants.
+func backfillSearchTerms(tx *gorm.DB) error {
+	var words []dictionary.Word
+	if err := tx.Select("id, word, lang, definition").Order("id").Find(&words).Error; err != nil {
+		return err
+	}
+	for _, w := range words {
+		if err := indexSearchTerms(tx, w.ID, fromRecord(w)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryLangs renvoie lang, ou les langues des entrées enregistrées s'il est
+// vide. Chaque langue est lue par une recherche dans un index qui commence
+// par lang, sans parcourir la table.
+func entryLangs(db *gorm.DB, lang string) ([]string, error) {
+	if lang != "" {
+		return []string{lang}, nil
+	}
+	var langs []string
+	last := ""
+	for {
+		var next sql.NullString
+		if err := db.Raw("SELECT MIN(lang) FROM words WHERE lang > ?", last).Scan(&next).Error; err != nil {
+			return nil, err
+		}
+		if !next.Valid {
+			return langs, nil
+		}
+		last = next.String
+		langs = append(langs, last)
+	}
+}
+
+// globPrefix renvoie le motif GLOB des textes qui commencent par prefix.
+func globPrefix(prefix string) string {
+	var b strings.Builder
+	for _, r := range prefix {
+		if strings.ContainsRune("*?[", r) {
+			b.WriteByte('[')
+			b.WriteRune(r)
+			b.WriteByte(']')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('*')
+	return b.String()
+}
+
+// SearchWords ne lit que les termes de la requête dans search_terms, indexée
+// sur (lang, term), et l'entrée de même clé ; le score est celui de search.Index.
+func (g *GormWordRepository) SearchWords(ctx context.Context, query, lang, tag string, limit int) ([]interfaces.SearchHit, error) {
+	if tag != "" {
+		var err error
+		if tag, err = interfaces.NormalizeTag(tag); err != nil {
+			return nil, err
+		}
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	langs, err := entryLangs(db, lang)
+	if err != nil {
+		return nil, err
+	}
+	hits := []interfaces.SearchHit{}
+	if len(langs) == 0 {
+		return hits, nil
+	}
+
+	// Chaque langue analyse la requête avec son raciniseur : une condition par langue.
+	var conditions []string
+	var args []any
+	for _, l := range langs {
+		if terms := search.QueryTerms(l, query); len(terms) > 0 {
+			conditions = append(conditions, "(search_terms.lang = ? AND search_terms.term IN ?)")
+			args = append(args, l, terms)
+		}
+	}
+
+	// Les entrées retenues ont un terme de la requête ou la même clé qu'elle :
+	// leurs identifiants sont lus dans les index avant les entrées elles-mêmes.
+	key := interfaces.WordKey(query)
+	candidates := db.Raw("SELECT id FROM words WHERE word_key = ? AND lang IN ?", key, langs)
+	score := db.Raw("CASE WHEN w.word_key = ? THEN ? ELSE 0 END", key, search.ExactScore)
+	if len(conditions) > 0 {
+		matches := strings.Join(conditions, " OR ")
+		candidates = db.Raw("SELECT word_id FROM search_terms WHERE "+matches+" UNION SELECT id FROM words WHERE word_key = ? AND lang IN ?",
+			append(args, key, langs)...)
+		termScore := db.Model(&SearchTermRecord{}).
+			Select("SUM(CASE field WHEN ? THEN ? ELSE ? END)", search.FieldWord, search.HeadwordScore, search.DefinitionScore).
+			Where("word_id = w.id").
+			Where(matches, args...)
+		score = db.Raw("CASE WHEN w.word_key = ? THEN ? ELSE 0 END + COALESCE((?), 0)", key, search.ExactScore, termScore)
+	}
+	found := db.Table("words AS w").Where("w.id IN (?) AND w.deleted_at IS NULL", candidates)
+	if tag != "" {
+		found = found.Where("w.id IN (?)", taggedWordIDs(db, tag))
+	}
+	found = found.Select("w.word, w.lang, w.definition, (?) AS score", score).Order("score DESC, w.lang, w.word_key")
+	if limit > 0 {
+		found = found.Limit(limit)
+	}
+	if err := found.Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+func (g *GormWordRepository) SuggestWords(ctx context.Context, query, lang string, limit int) ([]interfaces.EntryRef, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	langs, err := entryLangs(db, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []interfaces.EntryRef{}
+	seen := make(map[interfaces.EntryRef]bool)
+	add := func(words []dictionary.Word) {
+		for _, w := range words {
+			if ref := (interfaces.EntryRef{Word: w.Word, Lang: w.Lang}); !seen[ref] {
+				seen[ref] = true
+				suggestions = append(suggestions, ref)
+			}
+		}
+	}
+
+	key := interfaces.WordKey(query)
+	var exact []dictionary.Word
+	if err := db.Where("word_key = ? AND lang IN ?", key, langs).Order("lang").Find(&exact).Error; err != nil {
+		return nil, err
+	}
+	add(exact)
+
+	// Les autres formes du même lemme ont le même premier terme, puis les
+	// mêmes termes ; elles sont peu nombreuses et triées ici pour que la
+	// requête parte de l'index des termes.
+	for _, l := range langs {
+		terms := search.HeadwordTerms(l, query)
+		if len(terms) == 0 {
+			continue
+		}
+		var candidates []dictionary.Word
+		err := db.Where("id IN (?)", db.Model(&SearchTermRecord{}).Select("word_id").
+			Where("lang = ? AND term = ? AND field = ?", l, terms[0], search.FieldWord)).
+			Find(&candidates).Error
+		if err != nil {
+			return nil, err
+		}
+		same := candidates[:0]
+		for _, w := range candidates {
+			if slices.Equal(search.HeadwordTerms(l, w.Word), terms) {
+				same = append(same, w)
+			}
+		}
+		sort.Slice(same, func(i, j int) bool { return same[i].Key < same[j].Key })
+		add(same)
+	}
+
+	if key != "" {
+		prefixed := db.Where("word_key GLOB ?", globPrefix(key)).Order("length(word), lang, word_key")
+		if lang != "" {
+			prefixed = prefixed.Where("lang = ?", lang)
+		}
+		if limit > 0 {
+			prefixed = prefixed.Limit(limit + len(suggestions))
+		}
+		var words []dictionary.Word
+		if err := prefixed.Find(&words).Error; err != nil {
+			return nil, err
+		}
+		add(words)
+	}
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+func (r *storeRepository) SearchWords(ctx context.Context, query, lang, tag string, limit int) ([]interfaces.SearchHit, error) {
+	s := r.store()
+	var keep func(w interfaces.Word) bool
+	if tag != "" {
+		tag, err := interfaces.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		keep = func(w interfaces.Word) bool {
+			_, ok := s.tags.get(tagKey(entryKey(w.Word, w.Lang), tag))
+			return ok
+		}
+	}
+	var hits []interfaces.SearchHit
+	s.view(func() {
+		hits = s.searchIndex.Search(lang, query, limit, keep)
+	})
+	return hits, nil
+}
+
+func (r *storeRepository) SuggestWords(ctx context.Context, query, lang string, limit int) ([]interfaces.EntryRef, error) {
+	s := r.store()
+	var suggestions []interfaces.EntryRef
+	s.view(func() {
+		suggestions = s.searchIndex.Suggest(lang, query, limit)
+	})
+	return suggestions, nil
+}
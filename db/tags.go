@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"time"
+	"tp2/dictionary"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	AuditActionTag   = "tag"
+	AuditActionUntag = "untag"
+)
+
+type TagRecord struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"not null"`
+}
+
+func (TagRecord) TableName() string {
+	return "tags"
+}
+
+// WordTagRecord est une ligne de la table de jointure word_tags.
+type WordTagRecord struct {
+	WordID    uint `gorm:"primaryKey"`
+	TagID     uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+func (WordTagRecord) TableName() string {
+	return "word_tags"
+}
+
+func (g *GormWordRepository) TagWord(ctx context.Context, word, lang, tag string) error {
+	tag, err := interfaces.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		wordID, err := wordID(tx, word, lang)
+		if err != nil {
+			return err
+		}
+		record := TagRecord{Name: tag}
+		if err := tx.Where("name = ?", tag).FirstOrCreate(&record).Error; err != nil {
+			return err
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&WordTagRecord{WordID: wordID, TagID: record.ID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return recordAudit(ctx, tx, AuditActionTag, word, lang, "", tag)
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) UntagWord(ctx context.Context, word, lang, tag string) error {
+	tag, err := interfaces.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		wordID, err := wordID(tx, word, lang)
+		if err != nil {
+			return err
+		}
+		result := tx.Where("word_id = ? AND tag_id IN (?)", wordID, tx.Model(&TagRecord{}).Select("id").Where("name = ?", tag)).
+			Delete(&WordTagRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return interfaces.ErrTagNotFound
+		}
+		return recordAudit(ctx, tx, AuditActionUntag, word, lang, tag, "")
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) WordTags(ctx context.Context, word, lang string) ([]string, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := wordID(db, word, lang)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	tags := []string{}
+	err = db.Table("word_tags AS wt").
+		Joins("JOIN tags t ON t.id = wt.tag_id").
+		Where("wt.word_id = ?", id).
+		Order("t.name").
+		Pluck("t.name", &tags).Error
+	return tags, err
+}
+
+func (g *GormWordRepository) ListTags(ctx context.Context) ([]interfaces.TagCount, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := []interfaces.TagCount{}
+	err = db.Table("word_tags AS wt").
+		Select("t.name AS tag, COUNT(*) AS words").
+		Joins("JOIN tags t ON t.id = wt.tag_id").
+		Group("t.name").
+		Order("t.name").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (g *GormWordRepository) ListWordsByTags(ctx context.Context, tags []string) ([]interfaces.Word, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := db.Order("id")
+	for _, tag := range tags {
+		tag, err := interfaces.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id IN (?)", taggedWordIDs(db, tag))
+	}
+
+	var words []dictionary.Word
+	if err := query.Find(&words).Error; err != nil {
+		return nil, err
+	}
+	result := make([]interfaces.Word, len(words))
+	for i, w := range words {
+		result[i] = fromRecord(w)
+	}
+	return result, nil
+}
+
+// taggedWordIDs renvoie la sous-requête des identifiants des mots qui portent
+// l'étiquette normalisée tag.
+func taggedWordIDs(db *gorm.DB, tag string) *gorm.DB {
+	return db.Table("word_tags AS wt").
+		Select("wt.word_id").
+		Joins("JOIN tags t ON t.id = wt.tag_id").
+		Where("t.name = ?", tag)
+}
+
+func wordID(tx *gorm.DB, word, lang string) (uint, error) {
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return 0, err
+	}
+	var existing dictionary.Word
+	if err := tx.Select("id").Where("word_key = ? AND lang = ?", interfaces.WordKey(word), lang).First(&existing).Error; err != nil {
+		return 0, err
+	}
+	return existing.ID, nil
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionTranslate   = "translate"
+	AuditActionUntranslate = "untranslate"
+)
+
+// normalizeTranslation vérifie les langues des deux entrées et les range dans
+// l'ordre (langue, mot), pour qu'une traduction ne soit stockée qu'une fois.
+func normalizeTranslation(entry, translation interfaces.EntryRef) (interfaces.EntryRef, interfaces.EntryRef, error) {
+	var err error
+	if entry.Lang, err = interfaces.NormalizeLang(entry.Lang); err != nil {
+		return entry, translation, err
+	}
+	if translation.Lang, err = interfaces.NormalizeLang(translation.Lang); err != nil {
+		return entry, translation, err
+	}
+	if entry.Lang == translation.Lang {
+		return entry, translation, fmt.Errorf("%w : une traduction relie deux langues différentes", interfaces.ErrInvalidTranslation)
+	}
+	if translation.Lang < entry.Lang {
+		entry, translation = translation, entry
+	}
+	return entry, translation, nil
+}
+
+// TranslationRecord est une ligne de la table de jointure word_translations.
+type TranslationRecord struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	WordID       uint `gorm:"not null"`
+	TranslatedID uint `gorm:"not null"`
+}
+
+func (TranslationRecord) TableName() string {
+	return "word_translations"
+}
+
+func (g *GormWordRepository) AddTranslation(ctx context.Context, entry, translation interfaces.EntryRef) error {
+	entry, translation, err := normalizeTranslation(entry, translation)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		entryID, translationID, err := translationWordIDs(tx, entry, translation)
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&TranslationRecord{WordID: entryID, TranslatedID: translationID}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return interfaces.ErrTranslationExists
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionTranslate, entry.Word, entry.Lang, "", translation.Lang+":"+translation.Word)
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) RemoveTranslation(ctx context.Context, entry, translation interfaces.EntryRef) error {
+	entry, translation, err := normalizeTranslation(entry, translation)
+	if err != nil {
+		return err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		entryID, translationID, err := translationWordIDs(tx, entry, translation)
+		if err != nil {
+			return err
+		}
+		result := tx.Where("word_id = ? AND translated_id = ?", entryID, translationID).Delete(&TranslationRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return interfaces.ErrTranslationNotFound
+		}
+		return recordAudit(ctx, tx, AuditActionUntranslate, entry.Word, entry.Lang, translation.Lang+":"+translation.Word, "")
+	})
+	return translateError(err)
+}
+
+func (g *GormWordRepository) ListTranslations(ctx context.Context, entry interfaces.EntryRef) ([]interfaces.EntryRef, error) {
+	lang, err := interfaces.NormalizeLang(entry.Lang)
+	if err != nil {
+		return nil, err
+	}
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := wordID(db, entry.Word, lang)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	translations := []interfaces.EntryRef{}
+	err = db.Table("word_translations AS t").
+		Select("w.word AS word, w.lang AS lang").
+		Joins("JOIN words w ON w.id = CASE WHEN t.word_id = ? THEN t.translated_id ELSE t.word_id END", id).
+		Where("t.word_id = ? OR t.translated_id = ?", id, id).
+		Order("t.id").
+		Scan(&translations).Error
+	return translations, err
+}
+
+func translationWordIDs(tx *gorm.DB, entry, translation interfaces.EntryRef) (uint, uint, error) {
+	entryID, err := wordID(tx, entry.Word, entry.Lang)
+	if err != nil {
+		return 0, 0, err
+	}
+	translationID, err := wordID(tx, translation.Word, translation.Lang)
+	if err != nil {
+		return 0, 0, err
+	}
+	return entryID, translationID, nil
+}
+
+// deleteWordTranslations supprime les traductions d'une entrée avant sa suppression.
+func deleteWordTranslations(tx *gorm.DB, wordID uint) error {
+	return tx.Where("word_id = ? OR translated_id = ?", wordID, wordID).Delete(&TranslationRecord{}).Error
+}
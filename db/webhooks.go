@@ -0,0 +1,281 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"tp2/dictionary"
+	"tp2/interfaces"
+	"tp2/requestctx"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRecord est une ligne de la table webhooks. EventTypes est une liste séparée par des virgules.
+type WebhookRecord struct {
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	CreatedBy  string
+	URL        string `gorm:"not null"`
+	Secret     string `gorm:"not null"`
+	EventTypes string
+}
+
+func (WebhookRecord) TableName() string {
+	return "webhooks"
+}
+
+// DeliveryRecord est une ligne de la table webhook_deliveries, une par tentative.
+type DeliveryRecord struct {
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	WebhookID  uint   `gorm:"index;not null"`
+	EventID    uint64 `gorm:"not null"`
+	EventType  string `gorm:"not null"`
+	Payload    string
+	Attempt    int    `gorm:"not null"`
+	Status     string `gorm:"index;not null"`
+	StatusCode int
+	Error      string
+	DurationMS int64
+}
+
+func (DeliveryRecord) TableName() string {
+	return "webhook_deliveries"
+}
+
+// OutboxRecord est une ligne de la table webhook_outbox : un événement à livrer
+// à un webhook, enregistré dans la transaction de la modification. Les dates
+// sont en UTC pour être comparées telles qu'enregistrées.
+type OutboxRecord struct {
+	ID            uint64 `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	WebhookID     uint   `gorm:"not null"`
+	EventID       uint64 `gorm:"not null"`
+	EventType     string `gorm:"not null"`
+	Payload       string `gorm:"not null"`
+	Attempts      int    `gorm:"not null"`
+	NextAttemptAt time.Time
+}
+
+func (OutboxRecord) TableName() string {
+	return "webhook_outbox"
+}
+
+// enqueueDeliveries ajoute la révision à la boîte d'envoi de chaque webhook
+// abonné à son type, dans la transaction tx de la modification.
+func enqueueDeliveries(tx *gorm.DB, revision RevisionRecord) error {
+	var webhooks []WebhookRecord
+	if err := tx.Order("id").Find(&webhooks).Error; err != nil {
+		return err
+	}
+	event := dictionary.Event{
+		ID:         revision.ID,
+		Type:       revision.Type,
+		Word:       revision.Word,
+		Lang:       revision.Lang,
+		Definition: revision.Definition,
+		User:       revision.Username,
+		Time:       revision.CreatedAt.UTC(),
+	}
+	var payload []byte
+	for _, w := range webhooks {
+		if !(dictionary.EventFilter{Types: w.toWebhook().EventTypes}).Match(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		err := tx.Create(&OutboxRecord{
+			CreatedAt:     event.Time,
+			WebhookID:     w.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			NextAttemptAt: event.Time,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GormWordRepository) CreateWebhook(ctx context.Context, webhook interfaces.Webhook) (interfaces.Webhook, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return interfaces.Webhook{}, err
+	}
+	record := WebhookRecord{
+		CreatedBy:  requestctx.User(ctx),
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		EventTypes: strings.Join(webhook.EventTypes, ","),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return interfaces.Webhook{}, err
+	}
+	return record.toWebhook(), nil
+}
+
+func (g *GormWordRepository) ListWebhooks(ctx context.Context) ([]interfaces.Webhook, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var records []WebhookRecord
+	if err := db.Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	webhooks := make([]interfaces.Webhook, len(records))
+	for i, r := range records {
+		webhooks[i] = r.toWebhook()
+	}
+	return webhooks, nil
+}
+
+func (g *GormWordRepository) DeleteWebhook(ctx context.Context, id uint) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	// Les livraisons en attente partent avec le webhook.
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&WebhookRecord{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return interfaces.ErrWebhookNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&OutboxRecord{}).Error
+	})
+}
+
+func (g *GormWordRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]interfaces.PendingDelivery, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now = now.UTC()
+	var records []OutboxRecord
+	err = db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("next_attempt_at <= ?", now).Order("id")
+		if limit > 0 {
+			query = query.Limit(limit)
+		}
+		if err := query.Find(&records).Error; err != nil || len(records) == 0 {
+			return err
+		}
+		ids := make([]uint64, len(records))
+		for i, r := range records {
+			ids[i] = r.ID
+		}
+		return tx.Model(&OutboxRecord{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]interfaces.PendingDelivery, len(records))
+	for i, r := range records {
+		pending[i] = interfaces.PendingDelivery{
+			ID:        r.ID,
+			WebhookID: r.WebhookID,
+			EventID:   r.EventID,
+			EventType: r.EventType,
+			Payload:   r.Payload,
+			Attempts:  r.Attempts,
+		}
+	}
+	return pending, nil
+}
+
+func (g *GormWordRepository) RecordDelivery(ctx context.Context, pending interfaces.PendingDelivery, delivery interfaces.WebhookDelivery, retryAt time.Time) error {
+	db, err := g.session(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&DeliveryRecord{
+			WebhookID:  delivery.WebhookID,
+			EventID:    delivery.EventID,
+			EventType:  delivery.EventType,
+			Payload:    delivery.Payload,
+			Attempt:    delivery.Attempt,
+			Status:     delivery.Status,
+			StatusCode: delivery.StatusCode,
+			Error:      delivery.Error,
+			DurationMS: delivery.DurationMS,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if delivery.Status != interfaces.DeliveryFailed {
+			return tx.Delete(&OutboxRecord{}, pending.ID).Error
+		}
+		return tx.Model(&OutboxRecord{}).Where("id = ?", pending.ID).Updates(map[string]interface{}{
+			"attempts":        delivery.Attempt,
+			"next_attempt_at": retryAt.UTC(),
+		}).Error
+	})
+}
+
+func (g *GormWordRepository) ListDeliveries(ctx context.Context, filter interfaces.DeliveryFilter) ([]interfaces.WebhookDelivery, error) {
+	db, err := g.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := db.Model(&DeliveryRecord{}).Order("id DESC")
+	if filter.WebhookID != 0 {
+		query = query.Where("webhook_id = ?", filter.WebhookID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var records []DeliveryRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]interfaces.WebhookDelivery, len(records))
+	for i, r := range records {
+		deliveries[i] = interfaces.WebhookDelivery{
+			ID:         r.ID,
+			CreatedAt:  r.CreatedAt,
+			WebhookID:  r.WebhookID,
+			EventID:    r.EventID,
+			EventType:  r.EventType,
+			Payload:    r.Payload,
+			Attempt:    r.Attempt,
+			Status:     r.Status,
+			StatusCode: r.StatusCode,
+			Error:      r.Error,
+			DurationMS: r.DurationMS,
+		}
+	}
+	return deliveries, nil
+}
+
+func (r WebhookRecord) toWebhook() interfaces.Webhook {
+	webhook := interfaces.Webhook{
+		ID:         r.ID,
+		CreatedAt:  r.CreatedAt,
+		CreatedBy:  r.CreatedBy,
+		URL:        r.URL,
+		Secret:     r.Secret,
+		EventTypes: []string{},
+	}
+	if r.EventTypes != "" {
+		webhook.EventTypes = strings.Split(r.EventTypes, ",")
+	}
+	return webhook
+}
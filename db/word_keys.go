@@ -0,0 +1,82 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"tp2/analysis"
+	"tp2/dictionary"
+	"tp2/interfaces"
+
+	"gorm.io/gorm"
+)
+
+// ErrWordKeyCollision signale des mots existants qui ne se distinguent que par
+// la casse ou les accents : ils doivent être renommés ou supprimés avant la migration.
+var ErrWordKeyCollision = errors.New("mots en double une fois la casse et les accents ignorés")
+
+// keyedWord est un mot à ranger sous sa clé de recherche.
+type keyedWord struct {
+	Word string
+	Lang string
+}
+
+// groupByKey regroupe les mots par clé de recherche et langue, et renvoie
+// ErrWordKeyCollision si plusieurs mots partagent une même clé.
+func groupByKey(words []keyedWord) (map[interfaces.EntryRef]keyedWord, error) {
+	groups := make(map[interfaces.EntryRef][]string)
+	byKey := make(map[interfaces.EntryRef]keyedWord, len(words))
+	for _, w := range words {
+		ref := interfaces.EntryRef{Word: interfaces.WordKey(w.Word), Lang: w.Lang}
+		groups[ref] = append(groups[ref], w.Word)
+		byKey[ref] = w
+	}
+
+	var collisions []string
+	for ref, group := range groups {
+		if len(group) > 1 {
+			collisions = append(collisions, fmt.Sprintf("%s (%s)", strings.Join(group, ", "), ref.Lang))
+		}
+	}
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return nil, fmt.Errorf("%w : %s ; renommez ou supprimez les doublons puis relancez la migration", ErrWordKeyCollision, strings.Join(collisions, " ; "))
+	}
+	return byKey, nil
+}
+
+// backfillWordKeys normalise les mots existants et calcule leur clé de recherche.
+func backfillWordKeys(tx *gorm.DB) error {
+	var rows []struct {
+		ID   uint
+		Word string
+		Lang string
+	}
+	if err := tx.Table("words").Select("id, word, lang").Order("id").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	words := make([]keyedWord, len(rows))
+	for i, row := range rows {
+		words[i] = keyedWord{Word: row.Word, Lang: row.Lang}
+	}
+	if _, err := groupByKey(words); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		update := map[string]any{"word": interfaces.NormalizeWord(row.Word), "word_key": interfaces.WordKey(row.Word)}
+		if err := tx.Table("words").Where("id = ?", row.ID).Updates(update).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDerivedKeys calcule d'après son mot les colonnes de recherche d'une
+// entrée autres que sa clé : clés phonétiques et lettres.
+func setDerivedKeys(w *dictionary.Word) {
+	w.PhoneticKey, w.PhoneticAlt = analysis.PhoneticKeys(w.Lang, w.Word)
+	w.Letters, w.SortedLetters = interfaces.WordLetters(w.Word), interfaces.SortedLetters(w.Word)
+}
@@ -1,10 +1,14 @@
 package dictionary
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
+	"log/slog"
 	"os"
 	"sync"
+	"time"
+	"tp2/graph"
 	"tp2/interfaces"
 
 	"gorm.io/gorm"
@@ -12,8 +16,17 @@ import (
 
 type Word struct {
 	gorm.Model `gorm:"soft_delete:false"`
-	Word       string `gorm:"unique;not null"`
+	Word       string `gorm:"not null"`
+	Key        string `gorm:"column:word_key;uniqueIndex:idx_words_key_lang;not null"` // interfaces.WordKey(Word)
+	Lang       string `gorm:"uniqueIndex:idx_words_key_lang;not null;default:fr"`
 	Definition string `gorm:"not null"`
+	// Clés de prononciation du mot dans sa langue (voir analysis.PhoneticKeys).
+	PhoneticKey string `gorm:"column:phonetic_key;not null"`
+	PhoneticAlt string `gorm:"column:phonetic_alt;not null"`
+	// Lettres du mot, telles quelles et triées (voir interfaces.WordLetters).
+	Letters       string `gorm:"column:letters;not null"`
+	SortedLetters string `gorm:"column:sorted_letters;not null"`
+	PartOfSpeech  string `gorm:"column:part_of_speech;not null"` // nature grammaticale, facultative
 }
 
 type Dictionary struct {
@@ -21,10 +34,14 @@ type Dictionary struct {
 	words      []Word
 	addCh      chan Word // Canal pour ajouter un mot de manière asynchrone
 	editCh     chan Word
-	removeCh   chan string               // Canal pour supprimer un mot de manière asynchrone
+	removeCh   chan Word                 // Canal pour supprimer un mot de manière asynchrone
 	mu         sync.Mutex                // Mutex pour éviter les problèmes de concurrence
+	fileMu     sync.Mutex                // Protège l'écriture du fichier, distinct de mu pour ne pas bloquer la goroutine de traitement
 	responseCh chan struct{}             // Canal pour signaler la fin d'une opération asynchrone
+	pingCh     chan chan struct{}        // Canal pour vérifier que la goroutine de traitement répond
 	wordRepo   interfaces.WordRepository // Ajouter le champ wordRepo à la structure Dictionary
+	events     *EventBus                 // Diffuse les modifications réussies
+	validator  *Validator                // Contrôle les entrées avant leur enregistrement
 }
 
 func (w Word) String() string {
@@ -38,9 +55,12 @@ func New(filename string, wordRepository interfaces.WordRepository) *Dictionary
 		words:      make([]Word, 0),
 		addCh:      make(chan Word),
 		editCh:     make(chan Word),
-		removeCh:   make(chan string),
+		removeCh:   make(chan Word),
 		responseCh: make(chan struct{}),
+		pingCh:     make(chan chan struct{}),
 		wordRepo:   wordRepository,
+		events:     NewEventBus(wordRepository),
+		validator:  DefaultValidator(),
 	}
 	go d.processChannels() // Lance la gestion asynchrone des canaux
 	d.chargerFichier()     // Charge le dico depuis le fichier
@@ -52,28 +72,95 @@ func (d *Dictionary) processChannels() {
 	for {
 		select {
 		case word := <-d.addCh:
-			d.AddAsync(word.Word, word.Definition) // Ajoute de manière asynchrone un nouveau mot
-			<-d.responseCh                         // Attend la fin de l'opération
+			d.AddAsync(context.Background(), word.Word, word.Lang, word.Definition) // Ajoute de manière asynchrone un nouveau mot
+			<-d.responseCh                                                          // Attend la fin de l'opération
 		case word := <-d.editCh:
-			d.EditAsync(word.Word, word.Definition) // Modifie de manière asynchrone un nouveau mot
-			<-d.responseCh                          // Attend la fin de l'opération
+			d.EditAsync(context.Background(), word.Word, word.Lang, word.Definition) // Modifie de manière asynchrone un nouveau mot
+			<-d.responseCh                                                           // Attend la fin de l'opération
 		case word := <-d.removeCh:
-			d.RemoveAsync(word) // Supprime de manière asynchrone un mot
-			<-d.responseCh      // Attend la fin de l'opération
+			d.RemoveAsync(context.Background(), word.Word, word.Lang) // Supprime de manière asynchrone un mot
+			<-d.responseCh                                            // Attend la fin de l'opération
 		case <-d.responseCh:
 			d.enregistrerFichier() // Enregistre le dico dans le fichier après une opération
+		case reply := <-d.pingCh:
+			close(reply) // Signale que la goroutine est toujours active
 		}
 	}
 }
 
-func (d *Dictionary) AddAsync(word string, definition string) error {
+// WorkerAlive indique si la goroutine de traitement des canaux répond avant le délai imparti.
+func (d *Dictionary) WorkerAlive(timeout time.Duration) bool {
+	reply := make(chan struct{})
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case d.pingCh <- reply:
+	case <-timer.C:
+		return false
+	}
+
+	select {
+	case <-reply:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Events renvoie le bus sur lequel sont publiées les modifications du dictionnaire.
+func (d *Dictionary) Events() *EventBus {
+	return d.events
+}
+
+// publish diffuse les révisions que le dépôt a enregistrées avec une
+// modification réussie.
+func (d *Dictionary) publish(ctx context.Context) {
+	if err := d.events.Notify(ctx); err != nil {
+		slog.WarnContext(ctx, "diffusion des révisions impossible", "error", err)
+	}
+}
+
+// SetValidator remplace les règles de validation des entrées, celles de
+// config.Default() par défaut.
+func (d *Dictionary) SetValidator(v *Validator) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.validator = v
+}
+
+// Ping vérifie que le stockage du dictionnaire est joignable.
+func (d *Dictionary) Ping(ctx context.Context) error {
+	return d.wordRepo.Ping(ctx)
+}
+
+func (d *Dictionary) AddAsync(ctx context.Context, word, lang, definition string) error {
+	return d.AddEntryAsync(ctx, Entry{Word: word, Lang: lang, Definition: definition})
+}
+
+// AddEntryAsync ajoute une entrée dans sa langue après l'avoir validée ; une
+// entrée invalide est refusée avec un *ValidationError.
+func (d *Dictionary) AddEntryAsync(ctx context.Context, entry Entry) error {
 	// Mutex pour synchroniser l'accès à d.mu
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	if err := d.wordRepo.AddWordToDB(word, definition); err != nil {
+	lang, err := interfaces.NormalizeLang(entry.Lang)
+	if err != nil {
 		return err
 	}
+	entry.Lang = lang
+	if err := d.validator.Validate(entry); err != nil {
+		slog.WarnContext(ctx, "entrée refusée", "word", entry.Word, "error", err)
+		return err
+	}
+	word, definition := interfaces.NormalizeWord(entry.Word), entry.Definition
+	if err := d.wordRepo.AddWordToDB(ctx, interfaces.Word{Word: word, Lang: lang, Definition: definition, PartOfSpeech: entry.PartOfSpeech}); err != nil {
+		slog.WarnContext(ctx, "échec de l'ajout du mot", "word", word, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "mot ajouté", "word", word)
+	d.publish(ctx)
 	d.responseCh <- struct{}{}
 	return nil
 }
@@ -83,50 +170,374 @@ func (d *Dictionary) ResponseChannel() <-chan struct{} {
 	return d.responseCh
 }
 
-func (d *Dictionary) EditAsync(word string, newDefinition string) error {
+func (d *Dictionary) EditAsync(ctx context.Context, word, lang, newDefinition string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	existingWord, err := d.wordRepo.GetWordFromDB(word)
+	existingWord, err := d.wordRepo.GetWordFromDB(ctx, word, lang)
+	if err != nil {
+		return err
+	}
+	err = d.validator.ValidateDefinition(Entry{Word: existingWord.Word, Lang: existingWord.Lang, Definition: newDefinition})
 	if err != nil {
+		slog.WarnContext(ctx, "définition refusée", "word", word, "error", err)
 		return err
 	}
 
 	existingWord.Definition = newDefinition
 
-	if err := d.wordRepo.UpdateWordInDB(existingWord.Word, existingWord.Definition); err != nil {
+	if err := d.wordRepo.UpdateWordInDB(ctx, existingWord.Word, existingWord.Lang, existingWord.Definition); err != nil {
+		slog.WarnContext(ctx, "échec de la mise à jour du mot", "word", word, "error", err)
 		d.responseCh <- struct{}{}
 		return err
 	}
+	slog.DebugContext(ctx, "définition mise à jour", "word", word)
+	d.publish(ctx)
 	d.responseCh <- struct{}{}
 
 	return nil
 }
 
-func (d *Dictionary) RemoveAsync(word string) error {
+func (d *Dictionary) RemoveAsync(ctx context.Context, word, lang string) error {
 	// Mutex pour synchroniser l'accès à d.mu
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	if !d.wordExists(word) {
-		return errors.New("Le mot n'existe pas dans le dictionnaire")
+	lang, err := interfaces.NormalizeLang(lang)
+	if err != nil {
+		return err
+	}
+	// L'événement porte le mot tel qu'enregistré, pas tel qu'il a été saisi.
+	existing, err := d.wordRepo.GetWordFromDB(ctx, word, lang)
+	if err != nil {
+		return err
 	}
 
-	if err := d.wordRepo.DeleteWordFromDB(word); err != nil {
+	if err := d.wordRepo.DeleteWordFromDB(ctx, existing.Word, existing.Lang); err != nil {
+		slog.WarnContext(ctx, "échec de la suppression du mot", "word", word, "error", err)
 		d.responseCh <- struct{}{}
 		return err
 	}
+	slog.DebugContext(ctx, "mot supprimé", "word", existing.Word)
+	d.publish(ctx)
+	d.responseCh <- struct{}{}
+	return nil
+}
+
+// ApplyBatch valide les entrées, dont la langue est obligatoire, exécute les opérations valides en une
+// transaction puis publie un événement par opération réussie, une fois le lot validé.
+func (d *Dictionary) ApplyBatch(ctx context.Context, ops []interfaces.BatchOperation, atomic bool) ([]error, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Les opérations invalides ne sont pas transmises au dépôt ; en mode
+	// atomique, une seule suffit à annuler le lot.
+	errs := make([]error, len(ops))
+	var valid []interfaces.BatchOperation
+	var validIndexes []int
+	for i, op := range ops {
+		if errs[i] = d.validateOperation(ctx, op); errs[i] == nil {
+			valid = append(valid, op)
+			validIndexes = append(validIndexes, i)
+		}
+	}
+	if atomic && len(valid) < len(ops) {
+		slog.WarnContext(ctx, "lot refusé", "operations", len(ops), "invalid", len(ops)-len(valid))
+		return errs, interfaces.ErrBatchRolledBack
+	}
+
+	results, err := d.wordRepo.ApplyBatch(ctx, valid, atomic)
+	for j, result := range results {
+		errs[validIndexes[j]] = result.Err
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "lot annulé", "operations", len(ops), "error", err)
+		return errs, err
+	}
+
+	d.publish(ctx)
+	slog.DebugContext(ctx, "lot appliqué", "operations", len(ops))
 	d.responseCh <- struct{}{}
+	return errs, nil
+}
+
+// validateOperation contrôle l'entrée d'un ajout ou la nouvelle définition
+// d'une modification ; les autres opérations sont laissées au dépôt.
+func (d *Dictionary) validateOperation(ctx context.Context, op interfaces.BatchOperation) error {
+	lang, err := interfaces.NormalizeLang(op.Lang)
+	if err != nil {
+		return err
+	}
+	entry := Entry{Word: op.Word, Lang: lang, Definition: op.Definition, PartOfSpeech: op.PartOfSpeech}
+	switch op.Op {
+	case interfaces.BatchAdd:
+		return d.validator.Validate(entry)
+	case interfaces.BatchDefine:
+		return d.validator.ValidateDefinition(entry)
+	default:
+		return nil
+	}
+}
+
+// WordDetail regroupe une entrée, ses relations, ses étiquettes et ses traductions.
+type WordDetail struct {
+	Word         string                `json:"word"`
+	Lang         string                `json:"lang"`
+	Definition   string                `json:"definition"`
+	PartOfSpeech string                `json:"part_of_speech,omitempty"`
+	Relations    []interfaces.Relation `json:"relations"`
+	Tags         []string              `json:"tags"`
+	Translations []interfaces.EntryRef `json:"translations"`
+}
+
+// Detail renvoie l'entrée du mot dans la langue lang avec ses relations.
+func (d *Dictionary) Detail(ctx context.Context, word, lang string) (WordDetail, error) {
+	w, err := d.wordRepo.GetWordFromDB(ctx, word, lang)
+	if err != nil {
+		return WordDetail{}, err
+	}
+	translations, err := d.Translations(ctx, word, w.Lang)
+	if err != nil {
+		return WordDetail{}, err
+	}
+	relations, err := d.Relations(ctx, word, w.Lang)
+	if err != nil {
+		return WordDetail{}, err
+	}
+	tags, err := d.Tags(ctx, word, w.Lang)
+	if err != nil {
+		return WordDetail{}, err
+	}
+	return WordDetail{
+		Word:         w.Word,
+		Lang:         w.Lang,
+		Definition:   w.Definition,
+		PartOfSpeech: w.PartOfSpeech,
+		Relations:    relations,
+		Tags:         tags,
+		Translations: translations,
+	}, nil
+}
+
+// Lookup renvoie l'entrée du mot dans la première des langues préférées où il
+// existe, puis en DefaultLang, puis dans n'importe quelle langue.
+func (d *Dictionary) Lookup(ctx context.Context, word string, preferred []string) (WordDetail, error) {
+	langs := append(append([]string{}, preferred...), interfaces.DefaultLang)
+	for _, lang := range langs {
+		detail, err := d.Detail(ctx, word, lang)
+		if !errors.Is(err, interfaces.ErrWordNotFound) {
+			return detail, err
+		}
+	}
+
+	words, err := d.wordRepo.ListWordsByKey(ctx, word)
+	if err != nil {
+		return WordDetail{}, err
+	}
+	if len(words) == 0 {
+		return WordDetail{}, interfaces.ErrWordNotFound
+	}
+	return d.Detail(ctx, word, words[0].Lang)
+}
+
+// Translations renvoie les traductions de l'entrée du mot dans la langue lang, jamais nil.
+func (d *Dictionary) Translations(ctx context.Context, word, lang string) ([]interfaces.EntryRef, error) {
+	translations, err := d.wordRepo.ListTranslations(ctx, interfaces.EntryRef{Word: word, Lang: lang})
+	if err != nil {
+		return nil, err
+	}
+	if translations == nil {
+		translations = []interfaces.EntryRef{}
+	}
+	return translations, nil
+}
+
+// AddTranslation relie l'entrée du mot dans la langue lang à une entrée d'une autre langue.
+func (d *Dictionary) AddTranslation(ctx context.Context, word, lang string, translation interfaces.EntryRef) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry := interfaces.EntryRef{Word: word, Lang: lang}
+	if err := d.wordRepo.AddTranslation(ctx, entry, translation); err != nil {
+		slog.WarnContext(ctx, "échec de l'ajout de la traduction", "word", word, "lang", entry.Lang, "translation", translation.Word, "translation_lang", translation.Lang, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "traduction ajoutée", "word", word, "lang", entry.Lang, "translation", translation.Word, "translation_lang", translation.Lang)
+	return nil
+}
+
+func (d *Dictionary) RemoveTranslation(ctx context.Context, word, lang string, translation interfaces.EntryRef) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry := interfaces.EntryRef{Word: word, Lang: lang}
+	if err := d.wordRepo.RemoveTranslation(ctx, entry, translation); err != nil {
+		slog.WarnContext(ctx, "échec de la suppression de la traduction", "word", word, "lang", entry.Lang, "translation", translation.Word, "translation_lang", translation.Lang, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "traduction supprimée", "word", word, "lang", entry.Lang, "translation", translation.Word, "translation_lang", translation.Lang)
+	return nil
+}
+
+// Relations renvoie les relations de l'entrée du mot dans la langue lang, jamais nil.
+func (d *Dictionary) Relations(ctx context.Context, word, lang string) ([]interfaces.Relation, error) {
+	relations, err := d.wordRepo.ListRelations(ctx, word, lang)
+	if err != nil {
+		return nil, err
+	}
+	if relations == nil {
+		relations = []interfaces.Relation{}
+	}
+	return relations, nil
+}
+
+// Graph charge le graphe des mots de la langue lang et de leurs relations.
+func (d *Dictionary) Graph(ctx context.Context, lang string) (*graph.Graph, error) {
+	return graph.Load(ctx, d.wordRepo, lang)
+}
+
+// NeighbourhoodGraph charge le graphe des mots à au plus depth relations de
+// l'entrée du mot dans la langue lang.
+func (d *Dictionary) NeighbourhoodGraph(ctx context.Context, word, lang string, depth int) (*graph.Graph, error) {
+	return graph.LoadNeighbourhood(ctx, d.wordRepo, word, lang, depth)
+}
+
+func (d *Dictionary) AddRelation(ctx context.Context, word, lang, relationType, target string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.wordRepo.AddRelation(ctx, word, lang, relationType, target); err != nil {
+		slog.WarnContext(ctx, "échec de l'ajout de la relation", "word", word, "type", relationType, "target", target, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "relation ajoutée", "word", word, "type", relationType, "target", target)
+	return nil
+}
+
+func (d *Dictionary) RemoveRelation(ctx context.Context, word, lang, relationType, target string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.wordRepo.RemoveRelation(ctx, word, lang, relationType, target); err != nil {
+		slog.WarnContext(ctx, "échec de la suppression de la relation", "word", word, "type", relationType, "target", target, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "relation supprimée", "word", word, "type", relationType, "target", target)
+	return nil
+}
+
+// Tags renvoie les étiquettes de l'entrée du mot dans la langue lang, jamais nil.
+func (d *Dictionary) Tags(ctx context.Context, word, lang string) ([]string, error) {
+	tags, err := d.wordRepo.WordTags(ctx, word, lang)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+// AllTags renvoie les étiquettes utilisées avec leur nombre de mots.
+func (d *Dictionary) AllTags(ctx context.Context) ([]interfaces.TagCount, error) {
+	return d.wordRepo.ListTags(ctx)
+}
+
+func (d *Dictionary) TagWord(ctx context.Context, word, lang, tag string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.wordRepo.TagWord(ctx, word, lang, tag); err != nil {
+		slog.WarnContext(ctx, "échec de l'étiquetage", "word", word, "tag", tag, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "mot étiqueté", "word", word, "tag", tag)
 	return nil
 }
 
-func (d *Dictionary) wordExists(word string) bool {
-	_, err := d.wordRepo.GetWordFromDB(word)
-	return err == nil
+func (d *Dictionary) UntagWord(ctx context.Context, word, lang, tag string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.wordRepo.UntagWord(ctx, word, lang, tag); err != nil {
+		slog.WarnContext(ctx, "échec du retrait de l'étiquette", "word", word, "tag", tag, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "étiquette retirée", "word", word, "tag", tag)
+	return nil
 }
 
-func (d *Dictionary) List() ([]Word, error) {
-	wordsFromDB, err := d.wordRepo.ListWordsFromDB()
+// ListByTags renvoie les mots portant toutes les étiquettes données.
+func (d *Dictionary) ListByTags(ctx context.Context, tags []string) ([]Word, error) {
+	wordsFromDB, err := d.wordRepo.ListWordsByTags(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]Word, len(wordsFromDB))
+	for i, w := range wordsFromDB {
+		words[i] = Word{Word: w.Word, Lang: w.Lang, Definition: w.Definition, PartOfSpeech: w.PartOfSpeech}
+	}
+	return words, nil
+}
+
+// FilterLang ne garde que les mots de la langue lang ; une langue vide garde tout.
+func FilterLang(words []Word, lang string) []Word {
+	if lang == "" {
+		return words
+	}
+	filtered := make([]Word, 0, len(words))
+	for _, w := range words {
+		if w.Lang == lang {
+			filtered = append(filtered, w)
+		}
+	}
+	return filtered
+}
+
+func (d *Dictionary) Collections(ctx context.Context) ([]interfaces.Collection, error) {
+	collections, err := d.wordRepo.ListCollections(ctx)
+	if collections == nil && err == nil {
+		collections = []interfaces.Collection{}
+	}
+	return collections, err
+}
+
+func (d *Dictionary) Collection(ctx context.Context, name string) (interfaces.Collection, error) {
+	return d.wordRepo.GetCollection(ctx, name)
+}
+
+// SaveCollection crée la collection des mots de la langue lang, ou la
+// remplace si replace est vrai.
+func (d *Dictionary) SaveCollection(ctx context.Context, collection interfaces.Collection, lang string, replace bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	save := d.wordRepo.CreateCollection
+	if replace {
+		save = d.wordRepo.UpdateCollection
+	}
+	if err := save(ctx, collection, lang); err != nil {
+		slog.WarnContext(ctx, "échec de l'enregistrement de la collection", "collection", collection.Name, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "collection enregistrée", "collection", collection.Name, "words", len(collection.Words))
+	return nil
+}
+
+func (d *Dictionary) DeleteCollection(ctx context.Context, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.wordRepo.DeleteCollection(ctx, name); err != nil {
+		slog.WarnContext(ctx, "échec de la suppression de la collection", "collection", name, "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "collection supprimée", "collection", name)
+	return nil
+}
+
+func (d *Dictionary) List(ctx context.Context) ([]Word, error) {
+	wordsFromDB, err := d.wordRepo.ListWordsFromDB(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +545,7 @@ func (d *Dictionary) List() ([]Word, error) {
 	// Convertir []interfaces.Word en []Word
 	words := make([]Word, len(wordsFromDB))
 	for i, w := range wordsFromDB {
-		words[i] = Word{Word: w.Word, Definition: w.Definition}
+		words[i] = Word{Word: w.Word, Lang: w.Lang, Definition: w.Definition, PartOfSpeech: w.PartOfSpeech}
 	}
 
 	return words, nil
@@ -168,8 +579,8 @@ func (d *Dictionary) chargerFichier() error {
 
 // enregistrerFichier enregistre le dico dans le fichier CSV
 func (d *Dictionary) enregistrerFichier() error {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+	d.fileMu.Lock()
+	defer d.fileMu.Unlock()
 
 	file, err := os.Create(d.filename)
 	if err != nil {
@@ -0,0 +1,190 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"tp2/interfaces"
+)
+
+// Types d'événements publiés après chaque modification réussie du dictionnaire,
+// enregistrés par le dépôt dans ses révisions.
+const (
+	EventWordAdded   = "word.added"
+	EventWordUpdated = "word.updated"
+	EventWordRemoved = "word.removed"
+)
+
+const (
+	replayLimit           = 1000 // Nombre maximal d'événements rejoués à la reprise
+	subscriptionQueueSize = 64   // Événements en attente par abonné avant déconnexion
+)
+
+// ErrEventsExpired signale que la reprise est impossible, l'identifiant étant
+// inconnu ou trop ancien : le client doit recharger la liste complète des mots.
+var ErrEventsExpired = errors.New("événements expirés, rechargez la liste des mots")
+
+// Event décrit une modification du dictionnaire. ID est strictement croissant.
+type Event struct {
+	ID         uint64    `json:"id"`
+	Type       string    `json:"type"`
+	Word       string    `json:"word"`
+	Lang       string    `json:"lang"`
+	Definition string    `json:"definition,omitempty"`
+	User       string    `json:"user,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// EventFilter restreint les événements reçus par un abonné. Les champs vides acceptent tout.
+type EventFilter struct {
+	Types  []string
+	Prefix string
+	Lang   string
+}
+
+// Match indique si l'événement passe le filtre.
+func (f EventFilter) Match(e Event) bool {
+	if f.Prefix != "" && !strings.HasPrefix(e.Word, f.Prefix) {
+		return false
+	}
+	if f.Lang != "" && f.Lang != e.Lang {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// EventBus diffuse aux abonnés les révisions enregistrées par le dépôt et
+// rejoue celles qu'un client a manquées, y compris après un redémarrage.
+type EventBus struct {
+	mu          sync.Mutex
+	revisions   interfaces.WordRepository
+	lastID      uint64 // Dernière révision diffusée
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription reçoit les événements d'un abonné sur C. C est fermé si
+// l'abonné ne suit pas le rythme ; il peut alors se réabonner depuis son dernier ID.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter EventFilter
+	bus    *EventBus
+}
+
+// NewEventBus crée un bus qui diffusera les révisions postérieures à la
+// dernière déjà enregistrée dans revisions.
+func NewEventBus(revisions interfaces.WordRepository) *EventBus {
+	lastID, err := revisions.LastRevisionID(context.Background())
+	if err != nil {
+		slog.Warn("lecture de la dernière révision impossible", "error", err)
+	}
+	return &EventBus{
+		revisions:   revisions,
+		lastID:      lastID,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Notify diffuse les révisions enregistrées depuis le dernier appel.
+func (b *EventBus) Notify(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	revisions, err := b.revisions.ListRevisions(ctx, b.lastID, 0)
+	if err != nil {
+		return err
+	}
+	for _, r := range revisions {
+		b.lastID = r.ID
+		e := eventOf(r)
+		for sub := range b.subscribers {
+			if !sub.filter.Match(e) {
+				continue
+			}
+			select {
+			case sub.ch <- e:
+			default:
+				b.drop(sub)
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe abonne un client aux événements correspondant au filtre. Si
+// lastID est non nul, les révisions postérieures déjà diffusées sont
+// renvoyées pour être transmises avant celles de la souscription.
+func (b *EventBus) Subscribe(ctx context.Context, lastID uint64, filter EventFilter) (*Subscription, []Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	if lastID > b.lastID {
+		// Identifiant inconnu du dépôt, d'une autre base par exemple.
+		return nil, nil, ErrEventsExpired
+	}
+	if lastID > 0 && lastID < b.lastID {
+		revisions, err := b.revisions.ListRevisions(ctx, lastID, replayLimit+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(revisions) > replayLimit {
+			return nil, nil, ErrEventsExpired
+		}
+		for _, r := range revisions {
+			// Les révisions pas encore diffusées le seront par Notify.
+			if e := eventOf(r); e.ID <= b.lastID && filter.Match(e) {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriptionQueueSize)
+	sub := &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+	b.subscribers[sub] = struct{}{}
+	return sub, missed, nil
+}
+
+// LastID renvoie l'identifiant du dernier événement diffusé.
+func (b *EventBus) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// Close désabonne le client. Elle peut être appelée plusieurs fois.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+func (b *EventBus) drop(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+func eventOf(r interfaces.Revision) Event {
+	return Event{
+		ID:         r.ID,
+		Type:       r.Type,
+		Word:       r.Word,
+		Lang:       r.Lang,
+		Definition: r.Definition,
+		User:       r.User,
+		Time:       r.CreatedAt.UTC(),
+	}
+}
@@ -0,0 +1,49 @@
+package dictionary
+
+import (
+	"context"
+	"tp2/interfaces"
+)
+
+// Search renvoie au plus limit entrées dont le mot ou la définition contient
+// un terme de query ou une de ses formes fléchies. Sans lang, toutes les
+// langues sont parcourues ; avec tag, seules les entrées qui portent l'étiquette.
+func (d *Dictionary) Search(ctx context.Context, query, lang, tag string, limit int) ([]interfaces.SearchHit, error) {
+	return d.wordRepo.SearchWords(ctx, query, lang, tag, limit)
+}
+
+// Suggest renvoie au plus limit entrées proches de query : même mot, autre
+// forme du même lemme ou mot commençant par query.
+func (d *Dictionary) Suggest(ctx context.Context, query, lang string, limit int) ([]interfaces.EntryRef, error) {
+	return d.wordRepo.SuggestWords(ctx, query, lang, limit)
+}
+
+// SoundsLike renvoie au plus limit entrées (toutes si limit <= 0) qui se
+// prononcent comme query, dans la langue lang ou dans toutes si elle est vide.
+func (d *Dictionary) SoundsLike(ctx context.Context, query, lang string, limit int) ([]interfaces.Word, error) {
+	words, err := d.wordRepo.ListWordsBySound(ctx, query, lang)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(words) > limit {
+		words = words[:limit]
+	}
+	return words, nil
+}
+
+// MatchWords renvoie au plus limit entrées dont les lettres suivent le motif,
+// où « ? » remplace une lettre et « * » zéro ou plusieurs : « s?mf* ».
+func (d *Dictionary) MatchWords(ctx context.Context, pattern, lang string, limit int) ([]interfaces.Word, error) {
+	return d.wordRepo.MatchWords(ctx, pattern, lang, limit)
+}
+
+// Anagrams renvoie au plus limit entrées formées exactement des lettres données.
+func (d *Dictionary) Anagrams(ctx context.Context, letters, lang string, limit int) ([]interfaces.Word, error) {
+	return d.wordRepo.ListAnagrams(ctx, letters, lang, limit)
+}
+
+// WordsContaining renvoie au plus limit entrées qui contiennent toutes les
+// lettres données.
+func (d *Dictionary) WordsContaining(ctx context.Context, letters, lang string, limit int) ([]interfaces.Word, error) {
+	return d.wordRepo.ListWordsContaining(ctx, letters, lang, limit)
+}
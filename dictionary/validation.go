@@ -0,0 +1,253 @@
+package dictionary
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"tp2/config"
+	"tp2/i18n"
+	"tp2/interfaces"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Champs d'une entrée contrôlés par le Validator.
+const (
+	FieldWord         = "word"
+	FieldDefinition   = "definition"
+	FieldPartOfSpeech = "part_of_speech"
+)
+
+// anyKey désigne, dans les réglages, toutes les langues ou natures non citées.
+const anyKey = "*"
+
+// Entry est une entrée soumise au Validator. PartOfSpeech, facultative,
+// choisit les champs obligatoires et est enregistrée avec l'entrée. Le mot
+// est toujours obligatoire.
+type Entry struct {
+	Word         string
+	Lang         string
+	Definition   string
+	PartOfSpeech string
+}
+
+// FieldError est le défaut d'un champ : Code le désigne pour les clients et
+// Message le décrit dans la langue de celui qui le lit.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message *i18n.Error
+}
+
+func (e FieldError) Error() string {
+	return e.Message.Error()
+}
+
+// Localize renvoie le message dans la langue de p.
+func (e FieldError) Localize(p i18n.Printer) string {
+	return e.Message.Localize(p)
+}
+
+// ValidationError regroupe tous les défauts d'une entrée.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Localize(i18n.For(i18n.Default()))
+}
+
+// Localize renvoie les messages de chaque champ dans la langue de p.
+func (e *ValidationError) Localize(p i18n.Printer) string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Localize(p)
+	}
+	return strings.Join(messages, " ; ")
+}
+
+func (e *ValidationError) add(field, code, key string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: &i18n.Error{Key: key, Args: args}})
+}
+
+// charset est l'ensemble des caractères autorisés dans les mots d'une langue.
+type charset struct {
+	tables []*unicode.RangeTable
+	runes  string
+}
+
+func (c charset) allows(r rune) bool {
+	return unicode.In(r, c.tables...) || strings.ContainsRune(c.runes, r)
+}
+
+// Validator contrôle les entrées avant leur enregistrement, quelle que soit
+// leur origine : API, console ou lot.
+type Validator struct {
+	minWordLength, maxWordLength             int
+	minDefinitionLength, maxDefinitionLength int
+	charsets                                 map[string]charset
+	forbidden                                map[string]map[string]bool
+	required                                 map[string]map[string]bool
+}
+
+// NewValidator construit un Validator à partir de la configuration. Il refuse
+// une classe de caractères ou un champ obligatoire inconnus.
+func NewValidator(cfg config.ValidationConfig) (*Validator, error) {
+	v := &Validator{
+		minWordLength:       cfg.MinWordLength,
+		maxWordLength:       cfg.MaxWordLength,
+		minDefinitionLength: cfg.MinDefinitionLength,
+		maxDefinitionLength: cfg.MaxDefinitionLength,
+		charsets:            make(map[string]charset, len(cfg.Charsets)),
+		forbidden:           make(map[string]map[string]bool, len(cfg.ForbiddenWords)),
+		required:            make(map[string]map[string]bool, len(cfg.RequiredFields)),
+	}
+
+	for lang, classes := range cfg.Charsets {
+		var c charset
+		for _, class := range classes {
+			if table, ok := unicode.Categories[class]; ok {
+				c.tables = append(c.tables, table)
+			} else if table, ok := unicode.Scripts[class]; ok {
+				c.tables = append(c.tables, table)
+			} else if utf8.RuneCountInString(class) == 1 || !isClassName(class) {
+				c.runes += class
+			} else {
+				return nil, fmt.Errorf("validation.charsets.%s : classe Unicode inconnue %q", lang, class)
+			}
+		}
+		v.charsets[lang] = c
+	}
+
+	for lang, words := range cfg.ForbiddenWords {
+		keys := make(map[string]bool, len(words))
+		for _, word := range words {
+			keys[interfaces.WordKey(word)] = true
+		}
+		v.forbidden[lang] = keys
+	}
+
+	for partOfSpeech, fields := range cfg.RequiredFields {
+		required := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			switch field {
+			case FieldWord, FieldDefinition, FieldPartOfSpeech:
+				required[field] = true
+			default:
+				return nil, fmt.Errorf("validation.required_fields.%s : champ inconnu %q", partOfSpeech, field)
+			}
+		}
+		v.required[partOfSpeech] = required
+	}
+	return v, nil
+}
+
+// isClassName indique si class ressemble à un nom de classe Unicode plutôt
+// qu'à une liste de caractères : des lettres ASCII, la première en majuscule.
+func isClassName(class string) bool {
+	for i, r := range class {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) || (i == 0 && !unicode.IsUpper(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultValidator renvoie le Validator des réglages par défaut.
+func DefaultValidator() *Validator {
+	v, err := NewValidator(config.Default().Validation)
+	if err != nil {
+		panic(err) // les réglages par défaut sont valides
+	}
+	return v
+}
+
+// Validate contrôle tous les champs d'une nouvelle entrée. Elle renvoie un
+// *ValidationError listant chaque défaut, ou nil.
+func (v *Validator) Validate(e Entry) error {
+	return v.check(e, true)
+}
+
+// ValidateDefinition contrôle une nouvelle définition pour une entrée
+// existante, sans revenir sur son mot.
+func (v *Validator) ValidateDefinition(e Entry) error {
+	return v.check(e, false)
+}
+
+func (v *Validator) check(e Entry, checkWord bool) error {
+	report := &ValidationError{}
+	required, known := v.required[e.PartOfSpeech]
+	if e.PartOfSpeech == "" || !known {
+		if e.PartOfSpeech != "" {
+			report.add(FieldPartOfSpeech, "unknown", "app.validation.unknown_part_of_speech", e.PartOfSpeech, strings.Join(v.partsOfSpeech(), ", "))
+		}
+		required = v.required[anyKey]
+	}
+	if required[FieldPartOfSpeech] && e.PartOfSpeech == "" {
+		report.add(FieldPartOfSpeech, "required", "app.validation.part_of_speech_required")
+	}
+
+	if checkWord {
+		word := interfaces.NormalizeWord(e.Word)
+		switch length := interfaces.WordLength(word); {
+		case length == 0:
+			report.add(FieldWord, "required", "app.validation.word_required")
+		case length < v.minWordLength || length > v.maxWordLength:
+			report.add(FieldWord, "length", "app.validation.word_length", v.minWordLength, v.maxWordLength)
+		}
+		if r, ok := v.disallowedRune(word, e.Lang); ok {
+			report.add(FieldWord, "charset", "app.validation.word_charset", string(r), e.Lang)
+		}
+		if v.isForbidden(word, e.Lang) {
+			report.add(FieldWord, "forbidden", "app.validation.word_forbidden", word, e.Lang)
+		}
+	}
+
+	switch length := interfaces.WordLength(strings.TrimSpace(e.Definition)); {
+	case length == 0:
+		if required[FieldDefinition] {
+			report.add(FieldDefinition, "required", "app.validation.definition_required")
+		}
+	case length < v.minDefinitionLength || length > v.maxDefinitionLength:
+		report.add(FieldDefinition, "length", "app.validation.definition_length", v.minDefinitionLength, v.maxDefinitionLength)
+	}
+
+	if len(report.Fields) > 0 {
+		return report
+	}
+	return nil
+}
+
+// disallowedRune renvoie le premier caractère du mot, déjà en NFC, absent du
+// jeu de caractères de sa langue.
+func (v *Validator) disallowedRune(word, lang string) (rune, bool) {
+	c, ok := v.charsets[lang]
+	if !ok {
+		if c, ok = v.charsets[anyKey]; !ok {
+			return 0, false
+		}
+	}
+	for _, r := range word {
+		if !c.allows(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+func (v *Validator) isForbidden(word, lang string) bool {
+	key := interfaces.WordKey(word)
+	return v.forbidden[lang][key] || v.forbidden[anyKey][key]
+}
+
+// partsOfSpeech renvoie les natures grammaticales connues, triées.
+func (v *Validator) partsOfSpeech() []string {
+	var result []string
+	for partOfSpeech := range v.required {
+		if partOfSpeech != anyKey {
+			result = append(result, partOfSpeech)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
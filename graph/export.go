@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"tp2/interfaces"
+)
+
+// WriteDOT écrit le graphe au format Graphviz DOT. Les relations symétriques
+// sont dessinées sans flèche, l'hyperonymie du mot vers le terme plus général.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph dico {")
+	for _, word := range g.words {
+		fmt.Fprintf(bw, "\t%s [tooltip=%s];\n", dotQuote(word), dotQuote(g.definitions[word]))
+	}
+	for _, edge := range g.edges {
+		attributes := "label=" + dotQuote(edge.Type)
+		if edge.Type != interfaces.RelationHypernym {
+			attributes += ", dir=none"
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), attributes)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// WriteGraphML écrit le graphe au format GraphML, avec la définition de chaque
+// mot et le type de chaque relation.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="definition" for="node" attr.name="definition" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="type" for="edge" attr.name="type" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <graph id="dico" edgedefault="undirected">`)
+	for _, word := range g.words {
+		fmt.Fprintf(bw, "    <node id=\"%s\"><data key=\"definition\">%s</data></node>\n", xmlEscape(word), xmlEscape(g.definitions[word]))
+	}
+	for i, edge := range g.edges {
+		directed := edge.Type == interfaces.RelationHypernym
+		fmt.Fprintf(bw, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\" directed=\"%t\"><data key=\"type\">%s</data></edge>\n",
+			i, xmlEscape(edge.From), xmlEscape(edge.To), directed, xmlEscape(edge.Type))
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,301 @@
+// Package graph explore le réseau formé par les relations entre les mots :
+// plus court chemin, voisinage, composantes connexes et export DOT/GraphML.
+package graph
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"tp2/interfaces"
+)
+
+var ErrNoPath = errors.New("aucun chemin entre ces mots")
+
+// Edge est une relation telle qu'elle est stockée : From hypernym To signifie que To est plus général.
+type Edge struct {
+	From string `json:"from"`
+	Type string `json:"type"`
+	To   string `json:"to"`
+}
+
+// Step est une étape d'un chemin : Word est atteint depuis l'étape précédente par Relation.
+type Step struct {
+	Word     string `json:"word"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// Neighbour est un mot à Distance relations du mot de départ.
+type Neighbour struct {
+	Word     string `json:"word"`
+	Distance int    `json:"distance"`
+}
+
+// Graph est un instantané des mots et de leurs relations. Les parcours ignorent
+// le sens des relations ; les mots de départ sont cherchés par leur clé (voir
+// interfaces.WordKey), sans tenir compte de la casse ni des accents.
+type Graph struct {
+	words       []string
+	keys        map[string]string
+	definitions map[string]string
+	adjacency   map[string][]interfaces.Relation
+	edges       []Edge
+}
+
+// Load construit le graphe à partir des mots du dépôt dans la langue lang et
+// de leurs relations, en deux requêtes.
+func Load(ctx context.Context, repo interfaces.WordRepository, lang string) (*Graph, error) {
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := repo.ListRelationEdges(ctx, lang, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGraph(len(words))
+	for _, w := range words {
+		if w.Lang == lang {
+			g.addWord(w)
+		}
+	}
+	for _, edge := range edges {
+		g.addEdge(edge)
+	}
+	return g, nil
+}
+
+// LoadNeighbourhood construit le graphe des mots à au plus depth relations de
+// word dans la langue lang et des relations entre eux, sans charger le reste du dictionnaire : le
+// parcours en largeur lit les relations d'un niveau entier par requête.
+func LoadNeighbourhood(ctx context.Context, repo interfaces.WordRepository, word, lang string, depth int) (*Graph, error) {
+	start, err := repo.GetWordFromDB(ctx, word, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGraph(1)
+	g.addWord(start)
+	frontier := []string{start.Word}
+	// Le dernier niveau n'est pas étendu, mais ses relations internes sont gardées.
+	for distance := 0; distance <= depth && len(frontier) > 0; distance++ {
+		edges, err := repo.ListRelationEdges(ctx, start.Lang, frontier)
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for _, edge := range edges {
+			for _, w := range []interfaces.Word{edge.Word, edge.Target} {
+				if !g.Has(w.Word) && distance < depth {
+					g.addWord(w)
+					next = append(next, w.Word)
+				}
+			}
+			if g.Has(edge.Word.Word) && g.Has(edge.Target.Word) && !g.hasEdge(edge) {
+				g.addEdge(edge)
+			}
+		}
+		frontier = next
+	}
+	return g, nil
+}
+
+func newGraph(size int) *Graph {
+	return &Graph{
+		keys:        make(map[string]string, size),
+		definitions: make(map[string]string, size),
+		adjacency:   make(map[string][]interfaces.Relation, size),
+	}
+}
+
+func (g *Graph) addWord(w interfaces.Word) {
+	g.words = append(g.words, w.Word)
+	g.keys[interfaces.WordKey(w.Word)] = w.Word
+	g.definitions[w.Word] = w.Definition
+}
+
+// addEdge ajoute une relation enregistrée et la rend parcourable depuis ses deux mots.
+func (g *Graph) addEdge(edge interfaces.RelationEdge) {
+	from, to := edge.Word.Word, edge.Target.Word
+	g.edges = append(g.edges, Edge{From: from, Type: edge.Type, To: to})
+	g.adjacency[from] = append(g.adjacency[from], interfaces.Relation{Type: edge.Type, Target: to})
+	reverse := edge.Type
+	if reverse == interfaces.RelationHypernym {
+		reverse = interfaces.RelationHyponym
+	}
+	g.adjacency[to] = append(g.adjacency[to], interfaces.Relation{Type: reverse, Target: from})
+}
+
+// hasEdge indique si la relation a déjà été ajoutée, lorsqu'elle relie deux mots d'un même niveau.
+func (g *Graph) hasEdge(edge interfaces.RelationEdge) bool {
+	for _, rel := range g.adjacency[edge.Word.Word] {
+		if rel.Type == edge.Type && rel.Target == edge.Target.Word {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve renvoie le mot du graphe qui a la même clé que word.
+func (g *Graph) Resolve(word string) (string, bool) {
+	resolved, ok := g.keys[interfaces.WordKey(word)]
+	return resolved, ok
+}
+
+// Has indique si le mot fait partie du graphe.
+func (g *Graph) Has(word string) bool {
+	_, ok := g.Resolve(word)
+	return ok
+}
+
+// ShortestPath renvoie le plus court chemin de from à to, from compris.
+func (g *Graph) ShortestPath(from, to string) ([]Step, error) {
+	from, fromFound := g.Resolve(from)
+	to, toFound := g.Resolve(to)
+	if !fromFound || !toFound {
+		return nil, interfaces.ErrWordNotFound
+	}
+
+	previous := map[string]Step{from: {}}
+	queue := []string{from}
+	for len(queue) > 0 && queue[0] != to {
+		word := queue[0]
+		queue = queue[1:]
+		for _, rel := range g.adjacency[word] {
+			if _, seen := previous[rel.Target]; seen {
+				continue
+			}
+			previous[rel.Target] = Step{Word: word, Relation: rel.Type}
+			queue = append(queue, rel.Target)
+		}
+	}
+	if _, reached := previous[to]; !reached {
+		return nil, ErrNoPath
+	}
+
+	var path []Step
+	for word := to; word != from; word = previous[word].Word {
+		path = append(path, Step{Word: word, Relation: previous[word].Relation})
+	}
+	path = append(path, Step{Word: from})
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// Neighbourhood renvoie les mots à au plus depth relations de word, word exclu,
+// par distance puis par ordre alphabétique.
+func (g *Graph) Neighbourhood(word string, depth int) ([]Neighbour, error) {
+	word, found := g.Resolve(word)
+	if !found {
+		return nil, interfaces.ErrWordNotFound
+	}
+
+	distances := map[string]int{word: 0}
+	frontier := []string{word}
+	var neighbours []Neighbour
+	for distance := 1; distance <= depth && len(frontier) > 0; distance++ {
+		var next []string
+		for _, w := range frontier {
+			for _, rel := range g.adjacency[w] {
+				if _, seen := distances[rel.Target]; seen {
+					continue
+				}
+				distances[rel.Target] = distance
+				next = append(next, rel.Target)
+				neighbours = append(neighbours, Neighbour{Word: rel.Target, Distance: distance})
+			}
+		}
+		frontier = next
+	}
+
+	sort.Slice(neighbours, func(i, j int) bool {
+		if neighbours[i].Distance != neighbours[j].Distance {
+			return neighbours[i].Distance < neighbours[j].Distance
+		}
+		return neighbours[i].Word < neighbours[j].Word
+	})
+	return neighbours, nil
+}
+
+// Components renvoie les composantes connexes, de la plus grande à la plus
+// petite, chacune triée par ordre alphabétique. Un mot sans relation forme sa propre composante.
+func (g *Graph) Components() [][]string {
+	seen := make(map[string]bool, len(g.words))
+	var components [][]string
+	for _, word := range g.words {
+		if seen[word] {
+			continue
+		}
+		component := g.reachable(word, seen)
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	sort.SliceStable(components, func(i, j int) bool {
+		return len(components[i]) > len(components[j])
+	})
+	return components
+}
+
+// Component renvoie la composante connexe de word, triée par ordre alphabétique.
+func (g *Graph) Component(word string) ([]string, error) {
+	word, found := g.Resolve(word)
+	if !found {
+		return nil, interfaces.ErrWordNotFound
+	}
+	component := g.reachable(word, make(map[string]bool))
+	sort.Strings(component)
+	return component, nil
+}
+
+func (g *Graph) reachable(start string, seen map[string]bool) []string {
+	seen[start] = true
+	stack := []string{start}
+	var component []string
+	for len(stack) > 0 {
+		word := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		component = append(component, word)
+		for _, rel := range g.adjacency[word] {
+			if !seen[rel.Target] {
+				seen[rel.Target] = true
+				stack = append(stack, rel.Target)
+			}
+		}
+	}
+	return component
+}
+
+// Subgraph renvoie le graphe restreint aux mots donnés et aux relations entre eux.
+func (g *Graph) Subgraph(words []string) *Graph {
+	keep := make(map[string]bool, len(words))
+	sub := newGraph(len(words))
+	for _, word := range words {
+		if word, ok := g.Resolve(word); ok && !keep[word] {
+			keep[word] = true
+			sub.words = append(sub.words, word)
+			sub.keys[interfaces.WordKey(word)] = word
+			sub.definitions[word] = g.definitions[word]
+		}
+	}
+	for _, word := range sub.words {
+		for _, rel := range g.adjacency[word] {
+			if keep[rel.Target] {
+				sub.adjacency[word] = append(sub.adjacency[word], rel)
+			}
+		}
+	}
+	for _, edge := range g.edges {
+		if keep[edge.From] && keep[edge.To] {
+			sub.edges = append(sub.edges, edge)
+		}
+	}
+	return sub
+}
+
+// Edges renvoie les relations du graphe, chacune une seule fois.
+func (g *Graph) Edges() []Edge {
+	return g.edges
+}
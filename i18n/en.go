@@ -0,0 +1,219 @@
+package i18n
+
+var en = map[string]string{
+	// Messages communs à la console et à l'API.
+	"app.welcome":       "Welcome to the dico!",
+	"app.word_added":    "The word '%s' with the definition '%s' has been added.",
+	"app.word_defined":  "The definition of the word '%s' has been updated.",
+	"app.word_tagged":   "The word '%s' is tagged '%s'.",
+	"app.word_untagged": "The tag '%s' has been removed from the word '%s'.",
+	"app.no_words":      "No words in the dico.",
+	"app.list_failed":   "Error while listing the words: %v",
+	"app.tags_failed":   "Error while reading the tags: %v",
+
+	// Validation des entrées.
+	"app.validation.word_required":           "The word is required",
+	"app.validation.definition_required":     "The definition is required",
+	"app.validation.part_of_speech_required": "The part of speech is required",
+	"app.validation.unknown_part_of_speech":  "Unknown part of speech: %q (known: %s)",
+	"app.validation.word_length":             "The word must be between %d and %d characters long",
+	"app.validation.definition_length":       "The definition must be between %d and %d characters long",
+	"app.validation.word_charset":            "The word contains the character %q, which is not allowed in %s",
+	"app.validation.word_forbidden":          "The word '%s' is forbidden in %s",
+
+	// Rapport de qualité (lint).
+	"app.lint.near_duplicate":          "Near-duplicates: %s",
+	"app.lint.near_duplicate_fix":      "Keep '%s' and merge or delete: %s",
+	"app.lint.shared_definition":       "Same definition for %s: '%s'",
+	"app.lint.shared_definition_fix":   "Write a definition specific to each word or delete the duplicates",
+	"app.lint.missing_sense":           "The word '%s' has no definition",
+	"app.lint.missing_sense_fix":       "Define '%s' or delete the entry",
+	"app.lint.short_word":              "Word too short: '%s' (%d characters, at least %d)",
+	"app.lint.short_word_fix":          "Complete or delete '%s'",
+	"app.lint.empty_word":              "Entry without a word",
+	"app.lint.empty_word_fix":          "Delete the entry without a word",
+	"app.lint.short_definition":        "Definition too short for '%s': '%s' (at least %d characters)",
+	"app.lint.short_definition_fix":    "Write a definition of at least %d characters",
+	"app.lint.repeated_word":           "Repeated letter in the word '%s'",
+	"app.lint.repeated_word_fix":       "Replace the word with '%s'",
+	"app.lint.repeated_definition":     "Repeated letter in the definition of '%s': '%s'",
+	"app.lint.repeated_definition_fix": "Correct the definition to '%s'",
+	"app.lint.orphan_relation":         "Orphan %s relation: '%s' is linked to a deleted entry",
+	"app.lint.orphan_relations":        "Orphan %s relation: both of its entries have been deleted",
+	"app.lint.orphan_relation_fix":     "Delete orphan relations with \"lint -prune-orphans\"",
+	"app.lint.total.one":               "%d issue — entries checked: %d",
+	"app.lint.total.other":             "%d issues — entries checked: %d",
+
+	// Command-line subcommands.
+	"cli.backup.sqlite_only":         "backups only apply to the %s driver (configured driver: %s)",
+	"cli.backup.created":             "Backup created: %s",
+	"cli.restore.sqlite_only":        "restoring only applies to the %s driver (configured driver: %s)",
+	"cli.restore.usage":              "usage: restore <backup file>",
+	"cli.restore.done":               "Database %s restored from %s.",
+	"cli.restore.previous_kept":      "Previous database kept as %s",
+	"cli.migrate.sqlite_only":        "migrations only apply to the %s driver (configured driver: %s)",
+	"cli.migrate.usage":              "usage: migrate up [n] | down [n] | status",
+	"cli.migrate.invalid_steps":      "invalid number of migrations: %q",
+	"cli.migrate.applied":            "Applied: %04d_%s",
+	"cli.migrate.none_pending":       "No pending migration.",
+	"cli.migrate.reverted":           "Reverted: %04d_%s",
+	"cli.migrate.none_to_revert":     "No migration to revert.",
+	"cli.migrate.status_header":      "VERSION\tNAME\tSTATE\tAPPLIED AT",
+	"cli.migrate.state_pending":      "pending",
+	"cli.migrate.state_applied":      "applied",
+	"cli.migrate.state_unknown":      "unknown to this binary",
+	"cli.migrate.unknown_subcommand": "unknown migrate subcommand: %q",
+	"cli.graph.usage":                "usage: graph [-lang code] export [-format dot|graphml] [-o file] [-word word -depth n] [-tag a,b] | path <word> <target> | components",
+	"cli.graph.path_usage":           "usage: graph path <word> <target>",
+	"cli.graph.component.one":        "%d (%d word): %s",
+	"cli.graph.component.other":      "%d (%d words): %s",
+	"cli.graph.unknown_subcommand":   "unknown subcommand: %q (export, path or components)",
+	"cli.graph.unknown_format":       "unknown format: %q (dot or graphml)",
+	"cli.lint.unknown_format":        "unknown format: %q (text or json)",
+	"cli.lint.prune_needs_storage":   "-prune-orphans only applies to the storage, not to -file",
+	"cli.lint.prune_unsupported":     "the %s storage cannot delete orphan relations",
+	"cli.lint.orphans_deleted.one":   "%d orphan relation deleted",
+	"cli.lint.orphans_deleted.other": "%d orphan relations deleted",
+
+	// main.go
+	"main.unknown_mode":        "Unknown mode. Choose the mode:\n1. Console\n2. API",
+	"main.read_error":          "Error reading user input: %v",
+	"main.invalid_mode":        "Invalid choice. Exiting.",
+	"main.menu":                "|| Dico MENU ||\nList: 1\nAdd: 2,  Define: 3\nRemove: 4, Quit: 5\nTag: 6, By tag: 7\nLanguage: 8\nChoose ...",
+	"main.goodbye":             "Goodbye!",
+	"main.invalid_menu_choice": "Invalid choice. Please enter a valid number.",
+	"main.server_starting":     "Starting server on %s",
+	"main.unexpected_args":     "Unexpected arguments after mode %s: %s (options go before the mode)",
+
+	// Console.
+	"console.prompt_lang":           "Enter a language code (fr, en...), empty for all: ",
+	"console.all_langs":             "All languages are shown.",
+	"console.invalid_lang":          "Error: %v",
+	"console.lang_chosen":           "Working language: %s.",
+	"console.prompt_new_word":       "Enter the new word: ",
+	"console.prompt_new_definition": "Enter the new definition: ",
+	"console.prompt_word":           "Enter the word: ",
+	"console.add_failed":            "Error while adding the word '%s': %v",
+	"console.define_failed":         "Error while updating the word '%s': %v",
+	"console.invalid_entry":         "Entry rejected:",
+	"console.field_error":           "  - %v",
+	"console.prompt_remove":         "Enter the word to remove: ",
+	"console.remove_failed":         "Error while removing the word '%s': %v",
+	"console.word_removed":          "The word '%s' has been removed.",
+	"console.list_header":           "Words in the dico:",
+	"console.tags_line":             "    tags: %s",
+	"console.translations_line":     "    translations: %s",
+	"console.relation_line":         "    %s: %s",
+	"console.relation.synonym":      "synonyms",
+	"console.relation.antonym":      "antonyms",
+	"console.relation.hypernym":     "hypernyms",
+	"console.relation.hyponym":      "hyponyms",
+	"console.relation.see_also":     "see also",
+	"console.prompt_tags":           "Enter comma-separated tags (-tag to remove): ",
+	"console.untag_failed":          "Error while removing the tag '%s': %v",
+	"console.tag_failed":            "Error while tagging with '%s': %v",
+	"console.no_tags":               "No tags in the dico.",
+	"console.tags_header":           "Tags:",
+	"console.tag_words.one":         "    %s (%d word)",
+	"console.tag_words.other":       "    %s (%d words)",
+	"console.prompt_browse_tags":    "Enter one or more comma-separated tags: ",
+	"console.browse_failed":         "Error while searching by tag: %v",
+	"console.no_tagged_words":       "No word has these tags.",
+
+	// API : requêtes invalides.
+	"api.wrong_method":              "Wrong request method: %s, expected %s. Route: %s",
+	"api.unknown_route":             "Unknown route or method: %s %s",
+	"api.decode_body":               "Error decoding request body: %v. Route: %s",
+	"api.unreadable_body":           "Unreadable request body.",
+	"api.invalid_body":              "Invalid request body",
+	"api.relation_keys_expected":    "Keys type and target expected in request body. Route: %s",
+	"api.translation_keys_expected": "Keys word and lang expected in request body. Route: %s",
+	"api.tag_key_expected":          "Key tag expected in request body. Route: %s",
+	"api.word_missing_in_url":       "Please provide a word in the URL.",
+	"api.validation_error":          "Validation error: %v",
+	"api.invalid_lang":              "Invalid lang parameter: %v",
+	"api.invalid_tag":               "Invalid tag parameter: %v",
+	"api.invalid_since":             "Invalid since parameter: %v",
+	"api.invalid_until":             "Invalid until parameter: %v",
+	"api.invalid_limit":             "Invalid limit parameter.",
+	"api.invalid_depth":             "Invalid depth parameter: expected between 1 and %d.",
+	"api.invalid_format":            "Invalid format parameter: json, dot or graphml expected.",
+	"api.invalid_event_id":          "Invalid event ID: %q",
+	"api.invalid_webhook_id":        "Invalid webhook ID. Route: %s",
+	"api.invalid_webhook_id_param":  "Invalid webhook_id parameter.",
+	"api.webhook_url_invalid":       "url must be an absolute http or https address",
+	"api.webhook_event_unknown":     "unknown event type: %q",
+	"api.batch_size":                "The batch must contain between 1 and %d operations.",
+	"api.batch_missing_word":        "Missing key word",
+	"api.batch_unknown_op":          "Unknown operation: %q (add, define or remove expected)",
+
+	// API : authentification.
+	"api.token_required":       "Unauthorized. An authentication token is required.",
+	"api.token_invalid":        "Unauthorized. Invalid authentication token.",
+	"api.admin_only":           "Forbidden. This route is restricted to administrators.",
+	"api.read_body_failed":     "Error while reading the request body.",
+	"api.credentials_required": "Username and password required.",
+	"api.invalid_credentials":  "Wrong username or password for user: %s",
+	"api.token_failed":         "Error while generating the authentication token for user: %s",
+
+	// API : Idempotency-Key.
+	"api.idempotency_key_too_long": "Idempotency-Key header too long.",
+	"api.idempotency_key_reused":   "Idempotency-Key already used for another request.",
+	"api.idempotency_in_progress":  "A request with this Idempotency-Key is already in progress.",
+	"api.idempotency_failed":       "Could not check the Idempotency-Key: %v",
+	"api.body_too_large":           "Request body too large (%d bytes at most).",
+
+	// API : mots, relations, étiquettes, traductions et collections.
+	"api.add_failed":                "Error while adding the word: %v",
+	"api.define_failed":             "Error while updating the definition in the database: %v",
+	"api.remove_failed":             "Error while removing the word from the database: %v",
+	"api.word_removed":              "Removed the word %s",
+	"api.read_word_failed":          "Error while reading the word: %v",
+	"api.read_relations_failed":     "Error while reading the relations: %v",
+	"api.add_relation_failed":       "Error while adding the relation: %v",
+	"api.relation_added":            "The %s relation between '%s' and '%s' has been added.",
+	"api.remove_relation_failed":    "Error while removing the relation: %v",
+	"api.relation_removed":          "The %s relation between '%s' and '%s' has been removed.",
+	"api.tag_failed":                "Error while tagging: %v",
+	"api.untag_failed":              "Error while removing the tag: %v",
+	"api.read_translations_failed":  "Error while reading the translations: %v",
+	"api.add_translation_failed":    "Error while adding the translation: %v",
+	"api.translation_added":         "'%s' (%s) is a translation of '%s'.",
+	"api.remove_translation_failed": "Error while removing the translation: %v",
+	"api.translation_removed":       "The translation '%s' (%s) of '%s' has been removed.",
+	"api.read_collections_failed":   "Error while reading the collections: %v",
+	"api.create_collection_failed":  "Error while creating the collection: %v",
+	"api.read_collection_failed":    "Error while reading the collection: %v",
+	"api.update_collection_failed":  "Error while updating the collection: %v",
+	"api.delete_collection_failed":  "Error while deleting the collection: %v",
+	"api.collection_deleted":        "The collection '%s' has been deleted.",
+	"api.batch_failed":              "Error while applying the batch: %v",
+
+	// API : graphe.
+	"api.graph_failed":     "Error while loading the graph: %v",
+	"api.path_failed":      "Error while searching for the path: %v",
+	"api.component_failed": "Error while computing the component: %v",
+	"api.traverse_failed":  "Error while traversing the graph: %v",
+
+	// API : événements, sondes et administration.
+	"api.streaming_unsupported":   "Streaming is not supported by this server.",
+	"api.client_too_slow":         "client too slow",
+	"api.worker_unresponsive":     "the processing goroutine is not responding",
+	"api.backup_failed":           "Error while backing up: %v",
+	"api.backup_prune_failed":     "Backup %s created, but pruning failed: %v",
+	"api.backup_stat_failed":      "Error while reading the backup: %v",
+	"api.audit_failed":            "Error while reading the audit log: %v",
+	"api.read_webhooks_failed":    "Error while reading the webhooks: %v",
+	"api.create_webhook_failed":   "Error while creating the webhook: %v",
+	"api.webhook_not_found":       "Webhook %d not found.",
+	"api.events_expired":          "Events expired, reload the word list.",
+	"api.events_subscribe_failed": "Error while subscribing to events: %v",
+	"api.delete_webhook_failed":   "Error while deleting the webhook: %v",
+	"api.webhook_deleted":         "The webhook %d has been deleted.",
+	"api.read_deliveries_failed":  "Error while reading the deliveries: %v",
+	"api.lint_failed":             "Error while analysing the dictionary: %v",
+	"api.invalid_lint_format":     "Invalid format parameter: json or text expected.",
+	"api.query_missing":           "Please provide a search in the q parameter.",
+	"api.search_failed":           "Error while searching: %v",
+	"api.invalid_pattern":         "Invalid pattern or letters: %v",
+}
@@ -0,0 +1,220 @@
+package i18n
+
+// fr est le catalogue de référence : toute clé doit y figurer.
+var fr = map[string]string{
+	// Messages communs à la console et à l'API.
+	"app.welcome":       "Bienvenue dans le dico !",
+	"app.word_added":    "Le mot '%s' avec la définition '%s' a été ajouté.",
+	"app.word_defined":  "La définition pour le mot '%s' a été mise à jour.",
+	"app.word_tagged":   "Le mot '%s' porte l'étiquette '%s'.",
+	"app.word_untagged": "L'étiquette '%s' a été retirée du mot '%s'.",
+	"app.no_words":      "Aucun mot dans le dico.",
+	"app.list_failed":   "Erreur lors de la récupération de la liste des mots : %v",
+	"app.tags_failed":   "Erreur lors de la lecture des étiquettes : %v",
+
+	// Validation des entrées.
+	"app.validation.word_required":           "Le mot est obligatoire",
+	"app.validation.definition_required":     "La définition est obligatoire",
+	"app.validation.part_of_speech_required": "La nature grammaticale est obligatoire",
+	"app.validation.unknown_part_of_speech":  "Nature grammaticale inconnue : %q (connues : %s)",
+	"app.validation.word_length":             "La longueur du mot doit être entre %d et %d caractères",
+	"app.validation.definition_length":       "La longueur de la définition doit être entre %d et %d caractères",
+	"app.validation.word_charset":            "Le mot contient le caractère %q, non autorisé en %s",
+	"app.validation.word_forbidden":          "Le mot '%s' est interdit en %s",
+
+	// Rapport de qualité (lint).
+	"app.lint.near_duplicate":          "Quasi-doublons : %s",
+	"app.lint.near_duplicate_fix":      "Garder '%s' et fusionner ou supprimer : %s",
+	"app.lint.shared_definition":       "Même définition pour %s : '%s'",
+	"app.lint.shared_definition_fix":   "Rédiger une définition propre à chaque mot ou supprimer les doublons",
+	"app.lint.missing_sense":           "Le mot '%s' n'a pas de définition",
+	"app.lint.missing_sense_fix":       "Définir '%s' ou supprimer l'entrée",
+	"app.lint.short_word":              "Mot trop court : '%s' (%d caractères, %d au moins)",
+	"app.lint.short_word_fix":          "Compléter ou supprimer '%s'",
+	"app.lint.empty_word":              "Entrée sans mot",
+	"app.lint.empty_word_fix":          "Supprimer l'entrée sans mot",
+	"app.lint.short_definition":        "Définition trop courte pour '%s' : '%s' (%d caractères au moins)",
+	"app.lint.short_definition_fix":    "Rédiger une définition d'au moins %d caractères",
+	"app.lint.repeated_word":           "Lettre répétée dans le mot '%s'",
+	"app.lint.repeated_word_fix":       "Remplacer le mot par '%s'",
+	"app.lint.repeated_definition":     "Lettre répétée dans la définition de '%s' : '%s'",
+	"app.lint.repeated_definition_fix": "Corriger la définition en '%s'",
+	"app.lint.orphan_relation":         "Relation %s orpheline : '%s' est relié à une entrée supprimée",
+	"app.lint.orphan_relations":        "Relation %s orpheline : ses deux entrées ont été supprimées",
+	"app.lint.orphan_relation_fix":     "Supprimer les relations orphelines avec « lint -prune-orphans »",
+	"app.lint.total.one":               "%d anomalie — entrées analysées : %d",
+	"app.lint.total.other":             "%d anomalies — entrées analysées : %d",
+
+	// Sous-commandes de la ligne de commande.
+	"cli.backup.sqlite_only":         "la sauvegarde ne concerne que le pilote %s (pilote configuré : %s)",
+	"cli.backup.created":             "Sauvegarde créée : %s",
+	"cli.restore.sqlite_only":        "la restauration ne concerne que le pilote %s (pilote configuré : %s)",
+	"cli.restore.usage":              "usage : restore <fichier de sauvegarde>",
+	"cli.restore.done":               "Base %s restaurée depuis %s.",
+	"cli.restore.previous_kept":      "Ancienne base conservée sous %s",
+	"cli.migrate.sqlite_only":        "les migrations ne concernent que le pilote %s (pilote configuré : %s)",
+	"cli.migrate.usage":              "usage : migrate up [n] | down [n] | status",
+	"cli.migrate.invalid_steps":      "nombre de migrations invalide : %q",
+	"cli.migrate.applied":            "Appliquée : %04d_%s",
+	"cli.migrate.none_pending":       "Aucune migration en attente.",
+	"cli.migrate.reverted":           "Annulée : %04d_%s",
+	"cli.migrate.none_to_revert":     "Aucune migration à annuler.",
+	"cli.migrate.status_header":      "VERSION\tNOM\tÉTAT\tAPPLIQUÉE LE",
+	"cli.migrate.state_pending":      "en attente",
+	"cli.migrate.state_applied":      "appliquée",
+	"cli.migrate.state_unknown":      "inconnue de ce binaire",
+	"cli.migrate.unknown_subcommand": "sous-commande migrate inconnue : %q",
+	"cli.graph.usage":                "usage : graph [-lang code] export [-format dot|graphml] [-o fichier] [-word mot -depth n] [-tag a,b] | path <mot> <cible> | components",
+	"cli.graph.path_usage":           "usage : graph path <mot> <cible>",
+	"cli.graph.component.one":        "%d (%d mot) : %s",
+	"cli.graph.component.other":      "%d (%d mots) : %s",
+	"cli.graph.unknown_subcommand":   "sous-commande inconnue : %q (export, path ou components)",
+	"cli.graph.unknown_format":       "format inconnu : %q (dot ou graphml)",
+	"cli.lint.unknown_format":        "format inconnu : %q (text ou json)",
+	"cli.lint.prune_needs_storage":   "-prune-orphans ne s'applique qu'au stockage, pas à -file",
+	"cli.lint.prune_unsupported":     "le stockage %s ne permet pas de supprimer les relations orphelines",
+	"cli.lint.orphans_deleted.one":   "%d relation orpheline supprimée",
+	"cli.lint.orphans_deleted.other": "%d relations orphelines supprimées",
+
+	// main.go
+	"main.unknown_mode":        "Mode non reconnu. Choisissez le mode :\n1. Console\n2. API",
+	"main.read_error":          "Erreur de lecture de l'entrée utilisateur : %v",
+	"main.invalid_mode":        "Choix invalide. Terminé.",
+	"main.menu":                "|| MENU Dico ||\nVoir : 1\nAjouter : 2,  Définir : 3\nSupprimer : 4, Sortir : 5\nÉtiqueter : 6, Par étiquette : 7\nLangue : 8\nChoisissez ...",
+	"main.goodbye":             "Au revoir !",
+	"main.invalid_menu_choice": "Choix invalide. Veuillez entrer un numéro valide.",
+	"main.server_starting":     "Démarrage du serveur sur %s",
+	"main.unexpected_args":     "Arguments inattendus après le mode %s : %s (les options se placent avant le mode)",
+
+	// Console.
+	"console.prompt_lang":           "Entrez un code de langue (fr, en...), vide pour toutes : ",
+	"console.all_langs":             "Toutes les langues sont affichées.",
+	"console.invalid_lang":          "Erreur : %v",
+	"console.lang_chosen":           "Langue de travail : %s.",
+	"console.prompt_new_word":       "Entrez le nouveau mot : ",
+	"console.prompt_new_definition": "Entrez la nouvelle définition : ",
+	"console.prompt_word":           "Entrez le mot : ",
+	"console.add_failed":            "Erreur lors de l'ajout du mot '%s' : %v",
+	"console.define_failed":         "Erreur lors de la mise à jour du mot '%s' : %v",
+	"console.invalid_entry":         "Entrée refusée :",
+	"console.field_error":           "  - %v",
+	"console.prompt_remove":         "Écrivez le mot à supprimer : ",
+	"console.remove_failed":         "Erreur lors de la suppression du mot '%s' : %v",
+	"console.word_removed":          "Le mot '%s' a été supprimé avec succès.",
+	"console.list_header":           "Liste des mots du dico :",
+	"console.tags_line":             "    étiquettes : %s",
+	"console.translations_line":     "    traductions : %s",
+	"console.relation_line":         "    %s : %s",
+	"console.relation.synonym":      "synonymes",
+	"console.relation.antonym":      "antonymes",
+	"console.relation.hypernym":     "hyperonymes",
+	"console.relation.hyponym":      "hyponymes",
+	"console.relation.see_also":     "voir aussi",
+	"console.prompt_tags":           "Entrez les étiquettes séparées par des virgules (-étiquette pour retirer) : ",
+	"console.untag_failed":          "Erreur lors du retrait de l'étiquette '%s' : %v",
+	"console.tag_failed":            "Erreur lors de l'étiquetage avec '%s' : %v",
+	"console.no_tags":               "Aucune étiquette dans le dico.",
+	"console.tags_header":           "Étiquettes :",
+	"console.tag_words.one":         "    %s (%d mot)",
+	"console.tag_words.other":       "    %s (%d mots)",
+	"console.prompt_browse_tags":    "Entrez une ou plusieurs étiquettes séparées par des virgules : ",
+	"console.browse_failed":         "Erreur lors de la recherche par étiquette : %v",
+	"console.no_tagged_words":       "Aucun mot ne porte ces étiquettes.",
+
+	// API : requêtes invalides.
+	"api.wrong_method":              "Mauvaise méthode de requête : %s, %s attendu. Route: %s",
+	"api.unknown_route":             "Route ou méthode inconnue : %s %s",
+	"api.decode_body":               "Erreur lors de la lecture du corps de la requête : %v. Route: %s",
+	"api.unreadable_body":           "Corps de la demande illisible.",
+	"api.invalid_body":              "Corps de la demande non valide",
+	"api.relation_keys_expected":    "Clés type et target attendues dans le corps de la requête. Route: %s",
+	"api.translation_keys_expected": "Clés word et lang attendues dans le corps de la requête. Route: %s",
+	"api.tag_key_expected":          "Clé tag attendue dans le corps de la requête. Route: %s",
+	"api.word_missing_in_url":       "Veuillez saisir un mot dans l'URL.",
+	"api.validation_error":          "Erreur de validation : %v",
+	"api.invalid_lang":              "Paramètre lang invalide : %v",
+	"api.invalid_tag":               "Paramètre tag invalide : %v",
+	"api.invalid_since":             "Paramètre since invalide : %v",
+	"api.invalid_until":             "Paramètre until invalide : %v",
+	"api.invalid_limit":             "Paramètre limit invalide.",
+	"api.invalid_depth":             "Paramètre depth invalide : entre 1 et %d attendu.",
+	"api.invalid_format":            "Paramètre format invalide : json, dot ou graphml attendu.",
+	"api.invalid_event_id":          "Identifiant d'événement invalide : %q",
+	"api.invalid_webhook_id":        "Identifiant de webhook invalide. Route: %s",
+	"api.invalid_webhook_id_param":  "Paramètre webhook_id invalide.",
+	"api.webhook_url_invalid":       "url doit être une adresse http ou https absolue",
+	"api.webhook_event_unknown":     "type d'événement inconnu : %q",
+	"api.batch_size":                "Le lot doit contenir entre 1 et %d opérations.",
+	"api.batch_missing_word":        "Clé word manquante",
+	"api.batch_unknown_op":          "Opération inconnue : %q (add, define ou remove attendu)",
+
+	// API : authentification.
+	"api.token_required":       "Accès non autorisé. Le jeton d'authentification est requis.",
+	"api.token_invalid":        "Accès non autorisé. Jeton d'authentification invalide.",
+	"api.admin_only":           "Accès refusé. Cette route est réservée aux administrateurs.",
+	"api.read_body_failed":     "Erreur lors de la lecture du corps de la requête.",
+	"api.credentials_required": "Nom d'utilisateur et mot de passe requis.",
+	"api.invalid_credentials":  "Nom d'utilisateur ou mot de passe incorrect pour l'utilisateur : %s",
+	"api.token_failed":         "Erreur lors de la génération du jeton d'authentification pour l'utilisateur : %s",
+
+	// API : Idempotency-Key.
+	"api.idempotency_key_too_long": "En-tête Idempotency-Key trop long.",
+	"api.idempotency_key_reused":   "Idempotency-Key déjà utilisée pour une autre requête.",
+	"api.idempotency_in_progress":  "Une requête avec cette Idempotency-Key est déjà en cours.",
+	"api.idempotency_failed":       "Impossible de vérifier l'Idempotency-Key : %v",
+	"api.body_too_large":           "Corps de la demande trop volumineux (%d octets au plus).",
+
+	// API : mots, relations, étiquettes, traductions et collections.
+	"api.add_failed":                "Erreur lors de l'ajout du mot : %v",
+	"api.define_failed":             "Erreur lors de la mise à jour de la définition dans la base de données : %v",
+	"api.remove_failed":             "Erreur lors de la suppression du mot dans la base de données : %v",
+	"api.word_removed":              "Suppression du mot %s",
+	"api.read_word_failed":          "Erreur lors de la lecture du mot : %v",
+	"api.read_relations_failed":     "Erreur lors de la lecture des relations : %v",
+	"api.add_relation_failed":       "Erreur lors de l'ajout de la relation : %v",
+	"api.relation_added":            "La relation %s entre '%s' et '%s' a été ajoutée.",
+	"api.remove_relation_failed":    "Erreur lors de la suppression de la relation : %v",
+	"api.relation_removed":          "La relation %s entre '%s' et '%s' a été supprimée.",
+	"api.tag_failed":                "Erreur lors de l'étiquetage : %v",
+	"api.untag_failed":              "Erreur lors du retrait de l'étiquette : %v",
+	"api.read_translations_failed":  "Erreur lors de la lecture des traductions : %v",
+	"api.add_translation_failed":    "Erreur lors de l'ajout de la traduction : %v",
+	"api.translation_added":         "'%s' (%s) est une traduction de '%s'.",
+	"api.remove_translation_failed": "Erreur lors de la suppression de la traduction : %v",
+	"api.translation_removed":       "La traduction '%s' (%s) de '%s' a été supprimée.",
+	"api.read_collections_failed":   "Erreur lors de la lecture des collections : %v",
+	"api.create_collection_failed":  "Erreur lors de la création de la collection : %v",
+	"api.read_collection_failed":    "Erreur lors de la lecture de la collection : %v",
+	"api.update_collection_failed":  "Erreur lors de la modification de la collection : %v",
+	"api.delete_collection_failed":  "Erreur lors de la suppression de la collection : %v",
+	"api.collection_deleted":        "La collection '%s' a été supprimée.",
+	"api.batch_failed":              "Erreur lors de l'application du lot : %v",
+
+	// API : graphe.
+	"api.graph_failed":     "Erreur lors du chargement du graphe : %v",
+	"api.path_failed":      "Erreur lors de la recherche du chemin : %v",
+	"api.component_failed": "Erreur lors du calcul de la composante : %v",
+	"api.traverse_failed":  "Erreur lors du parcours du graphe : %v",
+
+	// API : événements, sondes et administration.
+	"api.streaming_unsupported":   "Le streaming n'est pas pris en charge par ce serveur.",
+	"api.client_too_slow":         "client trop lent",
+	"api.worker_unresponsive":     "la goroutine de traitement ne répond pas",
+	"api.backup_failed":           "Erreur lors de la sauvegarde : %v",
+	"api.backup_prune_failed":     "Sauvegarde %s créée, mais erreur lors de la purge : %v",
+	"api.backup_stat_failed":      "Erreur lors de la lecture de la sauvegarde : %v",
+	"api.audit_failed":            "Erreur lors de la lecture du journal d'audit : %v",
+	"api.read_webhooks_failed":    "Erreur lors de la lecture des webhooks : %v",
+	"api.create_webhook_failed":   "Erreur lors de la création du webhook : %v",
+	"api.webhook_not_found":       "Le webhook %d est introuvable.",
+	"api.events_expired":          "Événements expirés, rechargez la liste des mots.",
+	"api.events_subscribe_failed": "Erreur lors de l'abonnement aux événements : %v",
+	"api.delete_webhook_failed":   "Erreur lors de la suppression du webhook : %v",
+	"api.webhook_deleted":         "Le webhook %d a été supprimé.",
+	"api.read_deliveries_failed":  "Erreur lors de la lecture des livraisons : %v",
+	"api.lint_failed":             "Erreur lors de l'analyse du dictionnaire : %v",
+	"api.invalid_lint_format":     "Paramètre format invalide : json ou text attendu.",
+	"api.query_missing":           "Veuillez saisir une recherche dans le paramètre q.",
+	"api.search_failed":           "Erreur lors de la recherche : %v",
+	"api.invalid_pattern":         "Motif ou lettres invalides : %v",
+}
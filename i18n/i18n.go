@@ -0,0 +1,268 @@
+// Package i18n regroupe les messages affichés par la console et l'API dans
+// un catalogue par langue. Les messages sont désignés par une clé et mis en
+// forme avec fmt ; une clé absente d'un catalogue retombe sur le français.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale est la langue des messages lorsqu'aucune autre n'est choisie.
+const DefaultLocale = "fr"
+
+var bundles = map[string]map[string]string{
+	"fr": fr,
+	"en": en,
+}
+
+// pluralRules indique, pour chaque langue, si n appelle la forme « one ».
+var pluralRules = map[string]func(n int) bool{
+	"fr": func(n int) bool { return n == 0 || n == 1 },
+	"en": func(n int) bool { return n == 1 },
+}
+
+var defaultLocale = DefaultLocale
+
+// Supported renvoie les langues disposant d'un catalogue, triées.
+func Supported() []string {
+	locales := make([]string, 0, len(bundles))
+	for locale := range bundles {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// IsSupported indique si locale dispose d'un catalogue.
+func IsSupported(locale string) bool {
+	_, ok := bundles[locale]
+	return ok
+}
+
+// SetDefault choisit la langue des messages de la console et des logs, et
+// celle de l'API lorsque le client n'en demande aucune disponible.
+func SetDefault(locale string) error {
+	if !IsSupported(locale) {
+		return fmt.Errorf("langue des messages non prise en charge : %q (%s)", locale, strings.Join(Supported(), ", "))
+	}
+	defaultLocale = locale
+	return nil
+}
+
+// Default renvoie la langue choisie avec SetDefault.
+func Default() string {
+	return defaultLocale
+}
+
+// Resolve choisit la langue des messages : celle de la configuration si elle
+// est renseignée, sinon celle des variables LC_ALL, LC_MESSAGES ou LANG si
+// elle est prise en charge, sinon DefaultLocale.
+func Resolve(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if locale := parseEnvLocale(value); IsSupported(locale) {
+				return locale
+			}
+			return DefaultLocale
+		}
+	}
+	return DefaultLocale
+}
+
+// parseEnvLocale extrait la langue d'une valeur POSIX comme « en_US.UTF-8 ».
+func parseEnvLocale(value string) string {
+	value, _, _ = strings.Cut(value, ".")
+	value, _, _ = strings.Cut(value, "@")
+	value, _, _ = strings.Cut(value, "_")
+	return strings.ToLower(value)
+}
+
+// Match renvoie la première langue prise en charge parmi preferred (de la plus
+// à la moins préférée), sinon la langue par défaut.
+func Match(preferred []string) string {
+	for _, locale := range preferred {
+		if IsSupported(locale) {
+			return locale
+		}
+	}
+	return defaultLocale
+}
+
+// Printer met en forme les messages dans une langue.
+type Printer struct {
+	locale string
+}
+
+// For renvoie le Printer de locale, ou celui de la langue par défaut si elle
+// n'est pas prise en charge.
+func For(locale string) Printer {
+	if !IsSupported(locale) {
+		locale = defaultLocale
+	}
+	return Printer{locale: locale}
+}
+
+// Locale renvoie la langue du Printer.
+func (p Printer) Locale() string {
+	return p.locale
+}
+
+// T met en forme le message key avec args. Les arguments qui implémentent
+// Localizer, comme *Error, sont eux aussi traduits.
+func (p Printer) T(key string, args ...any) string {
+	return p.format(p.lookup(key), args)
+}
+
+// N met en forme le message key au singulier ou au pluriel selon n, qui ne
+// sert qu'à choisir la forme : il doit figurer dans args pour être affiché.
+func (p Printer) N(key string, n int, args ...any) string {
+	form := key + ".other"
+	if pluralRules[p.locale](n) {
+		form = key + ".one"
+	}
+	return p.format(p.lookup(form), args)
+}
+
+// Localizer est une erreur qui sait se traduire, comme *Error ou un rapport
+// regroupant plusieurs messages du catalogue.
+type Localizer interface {
+	Localize(p Printer) string
+}
+
+// Error renvoie le texte de err dans la langue du Printer lorsqu'il vient du catalogue.
+func (p Printer) Error(err error) string {
+	if l, ok := err.(Localizer); ok {
+		return l.Localize(p)
+	}
+	return err.Error()
+}
+
+func (p Printer) lookup(key string) string {
+	if message, ok := bundles[p.locale][key]; ok {
+		return message
+	}
+	if message, ok := bundles[DefaultLocale][key]; ok {
+		return message
+	}
+	return key
+}
+
+func (p Printer) format(message string, args []any) string {
+	if len(args) == 0 {
+		return message
+	}
+	translated := make([]any, len(args))
+	for i, arg := range args {
+		if l, ok := arg.(Localizer); ok {
+			arg = l.Localize(p)
+		}
+		translated[i] = arg
+	}
+	return fmt.Sprintf(message, translated...)
+}
+
+// T met en forme le message key dans la langue par défaut.
+func T(key string, args ...any) string {
+	return For(defaultLocale).T(key, args...)
+}
+
+// N met en forme le message pluriel key dans la langue par défaut.
+func N(key string, n int, args ...any) string {
+	return For(defaultLocale).N(key, n, args...)
+}
+
+// Error est une erreur dont le texte vient du catalogue, afin d'être traduite
+// dans la langue du client qui la reçoit.
+type Error struct {
+	Key  string
+	Args []any
+}
+
+// Errorf renvoie une erreur portant le message key du catalogue.
+func Errorf(key string, args ...any) error {
+	return &Error{Key: key, Args: args}
+}
+
+// Error renvoie le message dans la langue par défaut.
+func (e *Error) Error() string {
+	return T(e.Key, e.Args...)
+}
+
+// Localize renvoie le message dans la langue de p.
+func (e *Error) Localize(p Printer) string {
+	return p.T(e.Key, e.Args...)
+}
+
+// Has indique si le message key existe dans la langue par défaut, y compris
+// sous ses formes plurielles.
+func Has(key string) bool {
+	fr := bundles[DefaultLocale]
+	_, ok := fr[key]
+	_, plural := fr[key+".other"]
+	return ok || plural
+}
+
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// Check vérifie que chaque catalogue contient exactement les clés du
+// catalogue français, que chaque message pluriel a ses deux formes et que les
+// traductions utilisent les mêmes verbes de mise en forme.
+func Check() error {
+	var problems []string
+	reference := bundles[DefaultLocale]
+	for _, locale := range Supported() {
+		bundle := bundles[locale]
+		for key, message := range reference {
+			translated, ok := bundle[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s : clé %q absente", locale, key))
+				continue
+			}
+			if !sameVerbs(message, translated) {
+				problems = append(problems, fmt.Sprintf("%s : %q n'a pas les mêmes arguments qu'en %s", locale, key, DefaultLocale))
+			}
+		}
+		for key := range bundle {
+			if _, ok := reference[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s : clé %q inconnue en %s", locale, key, DefaultLocale))
+			}
+			if base, ok := strings.CutSuffix(key, ".one"); ok {
+				if _, ok := bundle[base+".other"]; !ok {
+					problems = append(problems, fmt.Sprintf("%s : forme %q absente", locale, base+".other"))
+				}
+			}
+			if base, ok := strings.CutSuffix(key, ".other"); ok {
+				if _, ok := bundle[base+".one"]; !ok {
+					problems = append(problems, fmt.Sprintf("%s : forme %q absente", locale, base+".one"))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("catalogue incomplet : %s", strings.Join(problems, " ; "))
+	}
+	return nil
+}
+
+// sameVerbs compare les verbes de deux messages, indépendamment de leur ordre.
+func sameVerbs(a, b string) bool {
+	verbs := func(message string) []string {
+		var result []string
+		for _, verb := range verbPattern.FindAllString(message, -1) {
+			if verb != "%%" {
+				result = append(result, verb[len(verb)-1:])
+			}
+		}
+		sort.Strings(result)
+		return result
+	}
+	return strings.Join(verbs(a), "") == strings.Join(verbs(b), "")
+}
@@ -1,16 +1,398 @@
 package interfaces
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Erreurs communes à toutes les implémentations de WordRepository.
+var (
+	ErrWordNotFound = errors.New("le mot n'existe pas dans le dictionnaire")
+	ErrWordExists   = errors.New("le mot existe déjà dans le dictionnaire")
+)
+
+// Word est une entrée : un mot, sa langue, sa définition dans cette langue et
+// sa nature grammaticale, facultative. Un même mot peut exister dans plusieurs langues.
 type Word struct {
+	Word         string `json:"word"`
+	Lang         string `json:"lang"`
+	Definition   string `json:"definition"`
+	PartOfSpeech string `json:"part_of_speech,omitempty"`
+}
+
+// SearchHit est une entrée trouvée par SearchWords.
+type SearchHit struct {
 	Word       string `json:"word"`
+	Lang       string `json:"lang"`
 	Definition string `json:"definition"`
+	Score      int    `json:"score"`
 }
 
+// WordRepository stocke les entrées. Une entrée est désignée par son mot et sa
+// langue ; une langue vide ou invalide est refusée avec ErrInvalidLang. Les
+// listes renvoient toutes les langues.
 type WordRepository interface {
 	InitializeDB(dbPath string) error
 	CloseDB()
-	ListWordsFromDB() ([]Word, error)
-	AddWordToDB(word, definition string) error
-	DeleteWordFromDB(word string) error
-	UpdateWordInDB(word, newDefinition string) error
-	GetWordFromDB(word string) (Word, error)
+	Ping(ctx context.Context) error
+	ListWordsFromDB(ctx context.Context) ([]Word, error)
+	AddWordToDB(ctx context.Context, entry Word) error
+	DeleteWordFromDB(ctx context.Context, word, lang string) error
+	UpdateWordInDB(ctx context.Context, word, lang, newDefinition string) error
+	GetWordFromDB(ctx context.Context, word, lang string) (Word, error)
+	// ListWordsByKey renvoie, dans l'ordre d'insertion, les entrées de toutes
+	// les langues dont le mot a la même clé que word (voir WordKey).
+	ListWordsByKey(ctx context.Context, word string) ([]Word, error)
+	// ListWordsBySound renvoie, dans l'ordre d'insertion, les entrées de la
+	// langue lang (toutes si lang est vide) qui se prononcent comme word dans
+	// leur langue, d'après les clés phonétiques enregistrées avec chaque mot.
+	ListWordsBySound(ctx context.Context, word, lang string) ([]Word, error)
+	// SearchWords renvoie au plus limit entrées (toutes si limit <= 0) dont le
+	// mot ou la définition contient un terme de query ou une de ses formes
+	// fléchies (voir search.Postings), de la plus à la moins pertinente. Sans
+	// lang, la requête est analysée dans chaque langue ; avec tag, seules les
+	// entrées qui portent l'étiquette sont retenues (ErrInvalidTag si elle est invalide).
+	SearchWords(ctx context.Context, query, lang, tag string, limit int) ([]SearchHit, error)
+	// SuggestWords renvoie au plus limit entrées proches de query : l'entrée
+	// elle-même, celles dont le mot a les mêmes termes, puis celles dont le
+	// mot commence par query, des plus courtes aux plus longues.
+	SuggestWords(ctx context.Context, query, lang string, limit int) ([]EntryRef, error)
+	// Les recherches pour les jeux de lettres portent sur les lettres des mots
+	// (voir WordLetters), dans la langue lang (toutes si elle est vide). Elles
+	// renvoient au plus limit entrées (toutes si limit <= 0) dans l'ordre
+	// d'insertion, ou ErrInvalidPattern si le motif ou les lettres sont invalides.
+	//
+	// MatchWords cherche un motif où « ? » remplace une lettre et « * » zéro ou
+	// plusieurs (voir NormalizePattern) ; ListAnagrams renvoie les mots formés
+	// exactement des lettres données, le mot lui-même compris ;
+	// ListWordsContaining ceux qui contiennent toutes les lettres données,
+	// autant de fois qu'elles y figurent.
+	MatchWords(ctx context.Context, pattern, lang string, limit int) ([]Word, error)
+	ListAnagrams(ctx context.Context, letters, lang string, limit int) ([]Word, error)
+	ListWordsContaining(ctx context.Context, letters, lang string, limit int) ([]Word, error)
+	// ApplyBatch exécute les opérations dans une seule transaction et renvoie
+	// le résultat de chacune. Une opération en échec est ignorée ; si atomic
+	// est vrai, tout le lot est annulé et ErrBatchRolledBack renvoyée.
+	ApplyBatch(ctx context.Context, ops []BatchOperation, atomic bool) ([]BatchResult, error)
+	// ListRevisions renvoie dans l'ordre au plus limit révisions d'identifiant
+	// supérieur à afterID ; LastRevisionID renvoie celui de la dernière, 0 s'il n'y en a pas.
+	ListRevisions(ctx context.Context, afterID uint64, limit int) ([]Revision, error)
+	LastRevisionID(ctx context.Context) (uint64, error)
+	// AddRelation relie deux mots existants ; RemoveRelation supprime le lien.
+	// Les relations d'un mot sont supprimées avec lui.
+	AddRelation(ctx context.Context, word, lang, relationType, target string) error
+	RemoveRelation(ctx context.Context, word, lang, relationType, target string) error
+	// ListRelations renvoie les relations du point de vue de word, dans l'ordre de création.
+	ListRelations(ctx context.Context, word, lang string) ([]Relation, error)
+	// ListRelationEdges renvoie en une requête, dans l'ordre de création, les
+	// relations entre entrées de la langue lang qui touchent l'un des mots
+	// donnés, ou toutes celles de la langue si words est vide.
+	ListRelationEdges(ctx context.Context, lang string, words []string) ([]RelationEdge, error)
+
+	// TagWord étiquette un mot ; étiqueter deux fois ne fait rien. Les étiquettes
+	// sont normalisées en minuscules (voir NormalizeTag).
+	TagWord(ctx context.Context, word, lang, tag string) error
+	UntagWord(ctx context.Context, word, lang, tag string) error
+	// WordTags renvoie les étiquettes d'un mot par ordre alphabétique.
+	WordTags(ctx context.Context, word, lang string) ([]string, error)
+	// ListTags renvoie les étiquettes utilisées et leur nombre de mots, par ordre alphabétique.
+	ListTags(ctx context.Context) ([]TagCount, error)
+	// ListWordsByTags renvoie, dans l'ordre d'insertion, les mots portant toutes les étiquettes.
+	ListWordsByTags(ctx context.Context, tags []string) ([]Word, error)
+
+	// Les collections sont des listes ordonnées de mots existants de la langue
+	// lang ; un mot supprimé est retiré des collections.
+	CreateCollection(ctx context.Context, collection Collection, lang string) error
+	UpdateCollection(ctx context.Context, collection Collection, lang string) error
+	DeleteCollection(ctx context.Context, name string) error
+	GetCollection(ctx context.Context, name string) (Collection, error)
+	ListCollections(ctx context.Context) ([]Collection, error)
+
+	// AddTranslation relie deux entrées de langues différentes ; le lien vaut
+	// dans les deux sens et disparaît avec l'une des entrées.
+	AddTranslation(ctx context.Context, entry, translation EntryRef) error
+	RemoveTranslation(ctx context.Context, entry, translation EntryRef) error
+	// ListTranslations renvoie les traductions d'une entrée, dans l'ordre de création.
+	ListTranslations(ctx context.Context, entry EntryRef) ([]EntryRef, error)
+}
+
+var (
+	ErrTranslationExists   = errors.New("la traduction existe déjà")
+	ErrTranslationNotFound = errors.New("la traduction n'existe pas")
+	ErrInvalidTranslation  = errors.New("traduction invalide")
+)
+
+const maxTagLength = 50
+
+var (
+	ErrTagNotFound        = errors.New("le mot ne porte pas cette étiquette")
+	ErrInvalidTag         = errors.New("étiquette invalide")
+	ErrCollectionNotFound = errors.New("la collection n'existe pas")
+	ErrCollectionExists   = errors.New("la collection existe déjà")
+	ErrInvalidCollection  = errors.New("collection invalide")
+)
+
+// NormalizeTag met une étiquette en minuscules sans espaces autour. Une
+// étiquette vide, trop longue ou contenant une virgule ou une barre oblique est refusée.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || len(tag) > maxTagLength || strings.ContainsAny(tag, ",/") {
+		return "", fmt.Errorf("%w : %q", ErrInvalidTag, tag)
+	}
+	return tag, nil
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Words int    `json:"words"`
+}
+
+// Collection est une liste ordonnée et nommée de mots.
+type Collection struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Words       []string `json:"words"`
+}
+
+// Validate vérifie le nom de la collection et l'absence de doublons dans ses
+// mots, comparés par WordKey.
+func (c Collection) Validate() error {
+	name := strings.TrimSpace(c.Name)
+	if name == "" || name != c.Name || strings.Contains(name, "/") {
+		return fmt.Errorf("%w : nom %q", ErrInvalidCollection, c.Name)
+	}
+	seen := make(map[string]bool, len(c.Words))
+	for _, word := range c.Words {
+		key := WordKey(word)
+		if seen[key] {
+			return fmt.Errorf("%w : le mot %q apparaît deux fois", ErrInvalidCollection, word)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Types de relations entre mots. Synonymes, antonymes et renvois sont
+// symétriques ; « a hypernym b » signifie que b est un terme plus général que a,
+// et se lit « b hyponym a » depuis b.
+const (
+	RelationSynonym  = "synonym"
+	RelationAntonym  = "antonym"
+	RelationHypernym = "hypernym"
+	RelationHyponym  = "hyponym"
+	RelationSeeAlso  = "see-also"
+)
+
+var (
+	ErrRelationExists   = errors.New("la relation existe déjà")
+	ErrRelationNotFound = errors.New("la relation n'existe pas")
+	ErrInvalidRelation  = errors.New("relation invalide")
+)
+
+// Relation relie un mot à Target.
+type Relation struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// RelationEdge est une relation sous sa forme enregistrée entre deux entrées
+// de même langue : un hyponyme est enregistré comme l'hyperonyme inverse et
+// une relation symétrique une seule fois.
+type RelationEdge struct {
+	Word   Word
+	Type   string
+	Target Word
+}
+
+// BatchResult est le résultat d'une opération d'un lot : son erreur, ou
+// l'entrée telle qu'enregistrée (telle qu'elle l'était avant un retrait).
+type BatchResult struct {
+	Entry Word
+	Err   error
+}
+
+// Opérations acceptées par ApplyBatch.
+const (
+	BatchAdd    = "add"
+	BatchDefine = "define"
+	BatchRemove = "remove"
+)
+
+var (
+	ErrBatchRolledBack = errors.New("lot annulé : au moins une opération a échoué")
+	ErrUnknownBatchOp  = errors.New("opération de lot inconnue")
+)
+
+// BatchOperation s'applique à l'entrée Word de la langue Lang.
+type BatchOperation struct {
+	Op         string `json:"op"`
+	Word       string `json:"word"`
+	Lang       string `json:"lang,omitempty"`
+	Definition string `json:"definition,omitempty"`
+
+	// PartOfSpeech est enregistrée avec un ajout et choisit ses champs obligatoires.
+	PartOfSpeech string `json:"part_of_speech,omitempty"`
+}
+
+// Revision est l'ajout, la modification ou la suppression d'une entrée (Type
+// vaut word.added, word.updated ou word.removed), enregistrée par le dépôt dans
+// la même transaction que la modification. ID est strictement croissant et
+// survit aux redémarrages ; Definition est vide pour une suppression.
+type Revision struct {
+	ID         uint64
+	Type       string
+	Word       string
+	Lang       string
+	Definition string
+	User       string
+	CreatedAt  time.Time
+}
+
+// AuditEntry décrit une modification du dictionnaire : qui, quoi, quand et depuis où.
+type AuditEntry struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Username  string    `json:"username"`
+	Action    string    `json:"action"`
+	Word      string    `json:"word"`
+	Lang      string    `json:"lang,omitempty"`
+	Before    string    `json:"before,omitempty"`
+	After     string    `json:"after,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// AuditFilter restreint la lecture du journal d'audit. Les champs vides sont ignorés.
+type AuditFilter struct {
+	Username string
+	Action   string
+	Word     string
+	Lang     string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// AuditRepository donne accès au journal d'audit des modifications.
+type AuditRepository interface {
+	ListAuditEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+// BackupRepository prend une sauvegarde cohérente du stockage pendant qu'il est utilisé.
+type BackupRepository interface {
+	Backup(ctx context.Context, dest string) error
+}
+
+// IdempotentResponse est la réponse enregistrée pour une clé Idempotency-Key.
+// Status vaut 0 tant que la première requête est en cours.
+type IdempotentResponse struct {
+	RequestHash string
+	Status      int
+	Header      map[string][]string
+	Body        []byte
+}
+
+// IdempotencyRepository conserve les réponses des requêtes portant un en-tête
+// Idempotency-Key jusqu'à leur expiration.
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey renvoie la réponse enregistrée sous key si elle
+	// n'a pas expiré à now ; sinon elle réserve key jusqu'à expiresAt pour
+	// une requête en cours et renvoie false. Une réservation sans réponse
+	// passé reservedUntil est tenue pour abandonnée (requête interrompue) :
+	// elle est reprise par la nouvelle requête.
+	ReserveIdempotencyKey(ctx context.Context, key, requestHash string, now, reservedUntil, expiresAt time.Time) (IdempotentResponse, bool, error)
+	// CompleteIdempotencyKey enregistre la réponse de la requête en cours ;
+	// ReleaseIdempotencyKey abandonne la réservation.
+	CompleteIdempotencyKey(ctx context.Context, key string, response IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	// PurgeIdempotencyKeys supprime les clés expirées à now et renvoie leur nombre.
+	PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int, error)
 }
+
+// OrphanRelation est une relation stockée dont l'un des mots, ou les deux,
+// n'existe plus : le mot disparu est vide.
+type OrphanRelation struct {
+	Lang   string `json:"lang"`
+	Word   string `json:"word"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// IntegrityRepository repère et supprime les relations orphelines, que
+// ListRelations ne montre pas.
+type IntegrityRepository interface {
+	OrphanRelations(ctx context.Context) ([]OrphanRelation, error)
+	DeleteOrphanRelations(ctx context.Context) (int, error)
+}
+
+// Statuts d'une tentative de livraison de webhook. DeliveryDead marque la
+// dernière tentative en échec : la livraison est abandonnée (lettre morte).
+const (
+	DeliverySucceeded = "delivered"
+	DeliveryFailed    = "failed"
+	DeliveryDead      = "dead"
+)
+
+// Webhook est un abonnement d'un outil externe aux modifications du dictionnaire.
+// EventTypes vide abonne à tous les événements.
+type Webhook struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+}
+
+// WebhookDelivery est une tentative de livraison d'un événement à un webhook.
+type WebhookDelivery struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	WebhookID  uint      `json:"webhook_id"`
+	EventID    uint64    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Payload    string    `json:"payload"`
+	Attempt    int       `json:"attempt"`
+	Status     string    `json:"status"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+type DeliveryFilter struct {
+	WebhookID uint
+	Status    string
+	Limit     int
+}
+
+// PendingDelivery est un événement de la boîte d'envoi, en attente de livraison
+// à un webhook. Il est enregistré dans la transaction de la modification ;
+// Attempts compte les tentatives déjà faites.
+type PendingDelivery struct {
+	ID        uint64
+	WebhookID uint
+	EventID   uint64
+	EventType string
+	Payload   string
+	Attempts  int
+}
+
+// WebhookRepository stocke les abonnements aux webhooks, la boîte d'envoi des
+// événements à livrer et le journal des livraisons.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error)
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	// ClaimDeliveries réserve pour lease au plus limit livraisons dues à now :
+	// elles ne sont pas rendues à un autre appel avant l'expiration de la réservation.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]PendingDelivery, error)
+	// RecordDelivery journalise une tentative. Une livraison DeliveryFailed
+	// est replanifiée à retryAt, les autres sont retirées de la boîte d'envoi.
+	RecordDelivery(ctx context.Context, pending PendingDelivery, delivery WebhookDelivery, retryAt time.Time) error
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]WebhookDelivery, error)
+}
+
+var ErrWebhookNotFound = errors.New("webhook introuvable")
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultLang est la langue que l'API et la console choisissent quand la
+// requête n'en précise aucune, et celle des entrées créées avant l'ajout des langues.
+const DefaultLang = "fr"
+
+var ErrInvalidLang = errors.New("code de langue invalide")
+
+// NormalizeLang ramène un code de langue à sa sous-étiquette principale en
+// minuscules (« en-US » devient « en ») et vérifie qu'elle compte 2 ou 3 lettres.
+func NormalizeLang(lang string) (string, error) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(lang), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	primary = strings.ToLower(primary)
+	if len(primary) < 2 || len(primary) > 3 || strings.Trim(primary, "abcdefghijklmnopqrstuvwxyz") != "" {
+		return "", fmt.Errorf("%w : %q", ErrInvalidLang, lang)
+	}
+	return primary, nil
+}
+
+// EntryRef désigne une entrée par son mot et sa langue.
+type EntryRef struct {
+	Word string `json:"word"`
+	Lang string `json:"lang"`
+}
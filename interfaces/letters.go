@@ -0,0 +1,67 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidPattern signale un motif ou une liste de lettres sans lettre, ou
+// contenant un caractère autre qu'une lettre ou un joker.
+var ErrInvalidPattern = errors.New("motif de lettres invalide")
+
+// WordLetters renvoie les lettres de la clé d'un mot (voir WordKey), sans
+// espaces, tirets ni apostrophes : « Arc-en-ciel » donne « arcenciel ». Les
+// recherches pour les jeux de lettres portent sur elles.
+func WordLetters(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		return -1
+	}, WordKey(word))
+}
+
+// SortedLetters renvoie les lettres d'un mot par ordre alphabétique : deux
+// anagrammes ont les mêmes.
+func SortedLetters(word string) string {
+	letters := []rune(WordLetters(word))
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+// NormalizePattern renvoie un motif de mots croisés sous la forme des
+// lettres de WordLetters : « ? » remplace une lettre et « * » zéro ou plusieurs.
+// Les espaces, tirets et apostrophes sont ignorés comme dans les mots.
+func NormalizePattern(pattern string) (string, error) {
+	var b strings.Builder
+	for _, r := range WordKey(pattern) {
+		switch {
+		case unicode.IsLetter(r), r == '?':
+			b.WriteRune(r)
+		case r == '*':
+			if !strings.HasSuffix(b.String(), "*") {
+				b.WriteRune(r)
+			}
+		case r == ' ', r == '-', r == '\'', r == '’':
+		default:
+			return "", fmt.Errorf("%w : %q", ErrInvalidPattern, pattern)
+		}
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("%w : %q", ErrInvalidPattern, pattern)
+	}
+	return b.String(), nil
+}
+
+// NormalizeLetters renvoie les lettres triées d'une liste de lettres, ou
+// ErrInvalidPattern si elle n'en contient aucune.
+func NormalizeLetters(letters string) (string, error) {
+	sorted := SortedLetters(letters)
+	if sorted == "" {
+		return "", fmt.Errorf("%w : %q", ErrInvalidPattern, letters)
+	}
+	return sorted, nil
+}
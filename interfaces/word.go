@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Les ligatures sont décomposées dans la clé pour que « cœur » et « coeur » se confondent.
+var ligatures = strings.NewReplacer("œ", "oe", "æ", "ae")
+
+// NormalizeWord renvoie la forme enregistrée d'un mot : sans espaces autour
+// et en Unicode NFC, pour que « é » saisi en une ou deux séquences soit le même mot.
+func NormalizeWord(word string) string {
+	return norm.NFC.String(strings.TrimSpace(word))
+}
+
+// WordKey renvoie la clé de recherche d'un mot : sa forme normalisée sans
+// casse, sans accents ni ligatures. « Éléphant », « éléphant » et « elephant »
+// ont la même clé et désignent donc la même entrée d'une langue.
+func WordKey(word string) string {
+	// Les transformations gardent un état : elles ne sont pas partagées entre goroutines.
+	folded := cases.Fold().String(NormalizeWord(word))
+	key, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), folded)
+	if err != nil {
+		return ligatures.Replace(folded)
+	}
+	return ligatures.Replace(key)
+}
+
+// WordLength renvoie le nombre de caractères d'un texte sous sa forme NFC.
+func WordLength(text string) int {
+	return len([]rune(norm.NFC.String(text)))
+}
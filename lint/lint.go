@@ -0,0 +1,405 @@
+// Package lint repère les entrées douteuses du dictionnaire : quasi-doublons,
+// définitions partagées, textes trop courts ou répétitifs, sens manquants et
+// relations orphelines. Il ne modifie rien : chaque anomalie suggère une correction.
+package lint
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"tp2/config"
+	"tp2/i18n"
+	"tp2/interfaces"
+	"unicode"
+)
+
+// Contrôles effectués, dans l'ordre du rapport.
+const (
+	CheckNearDuplicate    = "near_duplicate"
+	CheckSharedDefinition = "shared_definition"
+	CheckMissingSense     = "missing_sense"
+	CheckShortWord        = "short_word"
+	CheckShortDefinition  = "short_definition"
+	CheckRepeatedLetters  = "repeated_letters"
+	CheckOrphanRelation   = "orphan_relation"
+)
+
+var checkOrder = []string{
+	CheckNearDuplicate,
+	CheckSharedDefinition,
+	CheckMissingSense,
+	CheckShortWord,
+	CheckShortDefinition,
+	CheckRepeatedLetters,
+	CheckOrphanRelation,
+}
+
+// maxRun est le nombre de lettres identiques consécutives au-delà duquel un
+// texte est suspect : ni le français ni l'anglais n'en triplent une.
+const maxRun = 2
+
+// Options fixe les seuils des contrôles de longueur.
+type Options struct {
+	MinWordLength       int
+	MinDefinitionLength int
+}
+
+// OptionsFrom reprend les longueurs minimales de la validation des entrées.
+func OptionsFrom(cfg config.ValidationConfig) Options {
+	return Options{MinWordLength: cfg.MinWordLength, MinDefinitionLength: cfg.MinDefinitionLength}
+}
+
+// Issue est une anomalie portant sur Words : Message la décrit et Fix suggère
+// une correction, traduits au moment de l'affichage.
+type Issue struct {
+	Check   string
+	Lang    string
+	Words   []string
+	Message *i18n.Error
+	Fix     *i18n.Error
+}
+
+// Report est le résultat d'une analyse de Entries entrées.
+type Report struct {
+	Entries int
+	Issues  []Issue
+}
+
+// Run analyse toutes les entrées du dépôt, ainsi que ses relations orphelines
+// s'il implémente interfaces.IntegrityRepository.
+func Run(ctx context.Context, repo interfaces.WordRepository, opts Options) (Report, error) {
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Check(words, opts)
+
+	if integrity, ok := repo.(interfaces.IntegrityRepository); ok {
+		orphans, err := integrity.OrphanRelations(ctx)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, o := range orphans {
+			report.Issues = append(report.Issues, orphanIssue(o))
+		}
+	}
+	return report, nil
+}
+
+// Check analyse les entrées données. Les anomalies sont rangées par contrôle,
+// puis par langue et par mot.
+func Check(words []interfaces.Word, opts Options) Report {
+	var issues []Issue
+	issues = append(issues, nearDuplicates(words)...)
+	issues = append(issues, sharedDefinitions(words)...)
+	for _, w := range words {
+		issues = append(issues, entryIssues(w, opts)...)
+	}
+	sortIssues(issues)
+	return Report{Entries: len(words), Issues: issues}
+}
+
+func newIssue(check, lang string, words []string, message *i18n.Error, fix *i18n.Error) Issue {
+	return Issue{Check: check, Lang: lang, Words: words, Message: message, Fix: fix}
+}
+
+func msg(key string, args ...any) *i18n.Error {
+	return &i18n.Error{Key: key, Args: args}
+}
+
+// skeleton réduit un mot à ce qui reste une fois ignorés la casse, les
+// accents, la ponctuation et les lettres répétées plus de maxRun fois :
+// « Testtt » et « test » ont le même squelette. Les chiffres sont gardés.
+func skeleton(word string) string {
+	return collapseRuns(strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return r
+	}, interfaces.WordKey(word)))
+}
+
+// collapseRuns ramène à une seule lettre toute suite de plus de maxRun lettres identiques.
+func collapseRuns(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && unicode.ToLower(runes[j]) == unicode.ToLower(runes[i]) {
+			j++
+		}
+		n := j - i
+		if n > maxRun && unicode.IsLetter(runes[i]) {
+			n = 1
+		}
+		b.WriteString(string(runes[i : i+n]))
+		i = j
+	}
+	return b.String()
+}
+
+// noise compte les chiffres et les lettres en trop d'un mot : parmi des
+// quasi-doublons, celui qui en a le moins est proposé comme forme à garder.
+func noise(word string) int {
+	n := len([]rune(word)) - len([]rune(collapseRuns(word)))
+	for _, r := range word {
+		if unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// maxDistance est le nombre de modifications au-delà duquel deux squelettes
+// ne sont plus des quasi-doublons.
+const maxDistance = 1
+
+// minFuzzyLength est la longueur en dessous de laquelle changer une lettre ne
+// rapproche plus deux mots : « chat » et « char » sont deux mots.
+const minFuzzyLength = 6
+
+// nearDuplicates regroupe les mots d'une même langue dont les squelettes sont
+// identiques ou à au plus maxDistance modifications l'un de l'autre. Chaque
+// squelette n'est comparé qu'à ceux de sa langue dont la longueur diffère
+// d'au plus maxDistance.
+func nearDuplicates(words []interfaces.Word) []Issue {
+	type node struct {
+		lang     string
+		skeleton []rune
+	}
+	var nodes []node
+	index := make(map[string]int)
+	for _, w := range words {
+		s := skeleton(w.Word)
+		if s == "" {
+			continue
+		}
+		if _, seen := index[w.Lang+"\x00"+s]; !seen {
+			index[w.Lang+"\x00"+s] = len(nodes)
+			nodes = append(nodes, node{lang: w.Lang, skeleton: []rune(s)})
+		}
+	}
+
+	parent := make([]int, len(nodes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	buckets := make(map[string]map[int][]int) // langue -> longueur -> squelettes
+	for i, n := range nodes {
+		byLength := buckets[n.lang]
+		if byLength == nil {
+			byLength = make(map[int][]int)
+			buckets[n.lang] = byLength
+		}
+		length := len(n.skeleton)
+		for l := length - maxDistance; l <= length+maxDistance; l++ {
+			for _, j := range byLength[l] {
+				if distance(nodes[j].skeleton, n.skeleton) <= maxDistance {
+					parent[find(i)] = find(j)
+				}
+			}
+		}
+		byLength[length] = append(byLength[length], i)
+	}
+
+	groups := make(map[int][]string)
+	var order []int
+	for _, w := range words {
+		i, ok := index[w.Lang+"\x00"+skeleton(w.Word)]
+		if !ok {
+			continue
+		}
+		root := find(i)
+		if _, seen := groups[root]; !seen {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], w.Word)
+	}
+
+	var issues []Issue
+	for _, root := range order {
+		group := groups[root]
+		if len(group) < 2 {
+			continue
+		}
+		keep := group[0]
+		for _, word := range group[1:] {
+			if noise(word) < noise(keep) {
+				keep = word
+			}
+		}
+		var others []string
+		for _, word := range group {
+			if word != keep {
+				others = append(others, word)
+			}
+		}
+		issues = append(issues, newIssue(CheckNearDuplicate, nodes[root].lang, group,
+			msg("app.lint.near_duplicate", strings.Join(group, ", ")),
+			msg("app.lint.near_duplicate_fix", keep, strings.Join(others, ", "))))
+	}
+	return issues
+}
+
+// distance renvoie la distance de Levenshtein entre a et b, bornée à
+// maxDistance+1 : le calcul s'arrête dès qu'elle est dépassée. Remplacer un
+// chiffre par un autre n'est jamais permis (« mp3 » et « mp4 » diffèrent), et
+// changer une lettre ne l'est que si les deux mots ont au moins minFuzzyLength
+// caractères ; ajouter ou retirer un chiffre l'est toujours.
+func distance(a, b []rune) int {
+	const tooFar = maxDistance + 1
+	letterCost := 1
+	if min(len(a), len(b)) < minFuzzyLength {
+		letterCost = tooFar
+	}
+	cost := func(r rune) int {
+		if unicode.IsDigit(r) {
+			return 1
+		}
+		return letterCost
+	}
+	substitution := func(x, y rune) int {
+		switch {
+		case x == y:
+			return 0
+		case unicode.IsDigit(x) && unicode.IsDigit(y):
+			return tooFar
+		}
+		return letterCost
+	}
+
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := 1; j <= len(b); j++ {
+		prev[j] = prev[j-1] + cost(b[j-1])
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = prev[0] + cost(a[i-1])
+		best := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cur[j] = min(prev[j-1]+substitution(a[i-1], b[j-1]), prev[j]+cost(a[i-1]), cur[j-1]+cost(b[j-1]))
+			best = min(best, cur[j])
+		}
+		if best >= tooFar {
+			return tooFar
+		}
+		prev, cur = cur, prev
+	}
+	return min(prev[len(b)], tooFar)
+}
+
+// sharedDefinitions regroupe les mots d'une même langue dont les définitions
+// sont identiques aux espaces, à la casse et aux accents près.
+func sharedDefinitions(words []interfaces.Word) []Issue {
+	groups := make(map[string][]interfaces.Word)
+	var order []string
+	for _, w := range words {
+		definition := strings.Join(strings.Fields(w.Definition), " ")
+		if definition == "" {
+			continue
+		}
+		key := w.Lang + "\x00" + interfaces.WordKey(definition)
+		if _, seen := groups[key]; !seen {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], w)
+	}
+
+	var issues []Issue
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		headwords := make([]string, len(group))
+		for i, w := range group {
+			headwords[i] = w.Word
+		}
+		issues = append(issues, newIssue(CheckSharedDefinition, group[0].Lang, headwords,
+			msg("app.lint.shared_definition", strings.Join(headwords, ", "), group[0].Definition),
+			msg("app.lint.shared_definition_fix")))
+	}
+	return issues
+}
+
+// entryIssues contrôle une entrée seule : sens manquant, textes trop courts et
+// lettres répétées.
+func entryIssues(w interfaces.Word, opts Options) []Issue {
+	var issues []Issue
+	words := []string{w.Word}
+
+	if length := interfaces.WordLength(interfaces.NormalizeWord(w.Word)); length < opts.MinWordLength {
+		if length == 0 {
+			// Une entrée sans mot est à supprimer : inutile de détailler sa définition.
+			return []Issue{newIssue(CheckShortWord, w.Lang, words,
+				msg("app.lint.empty_word"), msg("app.lint.empty_word_fix"))}
+		}
+		issues = append(issues, newIssue(CheckShortWord, w.Lang, words,
+			msg("app.lint.short_word", w.Word, length, opts.MinWordLength), msg("app.lint.short_word_fix", w.Word)))
+	}
+
+	switch length := interfaces.WordLength(strings.TrimSpace(w.Definition)); {
+	case length == 0:
+		issues = append(issues, newIssue(CheckMissingSense, w.Lang, words,
+			msg("app.lint.missing_sense", w.Word), msg("app.lint.missing_sense_fix", w.Word)))
+	case length < opts.MinDefinitionLength:
+		issues = append(issues, newIssue(CheckShortDefinition, w.Lang, words,
+			msg("app.lint.short_definition", w.Word, w.Definition, opts.MinDefinitionLength),
+			msg("app.lint.short_definition_fix", opts.MinDefinitionLength)))
+	}
+
+	if fixed := collapseRuns(w.Word); fixed != w.Word {
+		issues = append(issues, newIssue(CheckRepeatedLetters, w.Lang, words,
+			msg("app.lint.repeated_word", w.Word), msg("app.lint.repeated_word_fix", fixed)))
+	}
+	if fixed := collapseRuns(w.Definition); fixed != w.Definition {
+		issues = append(issues, newIssue(CheckRepeatedLetters, w.Lang, words,
+			msg("app.lint.repeated_definition", w.Word, w.Definition), msg("app.lint.repeated_definition_fix", fixed)))
+	}
+	return issues
+}
+
+func orphanIssue(o interfaces.OrphanRelation) Issue {
+	var words []string
+	for _, word := range []string{o.Word, o.Target} {
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	message := msg("app.lint.orphan_relations", o.Type)
+	if len(words) == 1 {
+		message = msg("app.lint.orphan_relation", o.Type, words[0])
+	}
+	return newIssue(CheckOrphanRelation, o.Lang, words, message, msg("app.lint.orphan_relation_fix"))
+}
+
+func sortIssues(issues []Issue) {
+	rank := make(map[string]int, len(checkOrder))
+	for i, check := range checkOrder {
+		rank[check] = i
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Check != b.Check {
+			return rank[a.Check] < rank[b.Check]
+		}
+		if a.Lang != b.Lang {
+			return a.Lang < b.Lang
+		}
+		return interfaces.WordKey(firstWord(a)) < interfaces.WordKey(firstWord(b))
+	})
+}
+
+func firstWord(issue Issue) string {
+	if len(issue.Words) == 0 {
+		return ""
+	}
+	return issue.Words[0]
+}
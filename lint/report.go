@@ -0,0 +1,58 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"tp2/i18n"
+)
+
+// Finding est une anomalie rédigée dans une langue, telle qu'exportée en JSON.
+type Finding struct {
+	Check   string   `json:"check"`
+	Lang    string   `json:"lang"`
+	Words   []string `json:"words"`
+	Message string   `json:"message"`
+	Fix     string   `json:"fix"`
+}
+
+// Summary est le rapport rédigé dans une langue.
+type Summary struct {
+	Entries int            `json:"entries"`
+	Counts  map[string]int `json:"counts"`
+	Issues  []Finding      `json:"issues"`
+}
+
+// Summary rédige le rapport dans la langue de p et compte les anomalies par contrôle.
+func (r Report) Summary(p i18n.Printer) Summary {
+	summary := Summary{Entries: r.Entries, Counts: map[string]int{}, Issues: make([]Finding, len(r.Issues))}
+	for i, issue := range r.Issues {
+		summary.Counts[issue.Check]++
+		summary.Issues[i] = Finding{
+			Check:   issue.Check,
+			Lang:    issue.Lang,
+			Words:   issue.Words,
+			Message: issue.Message.Localize(p),
+			Fix:     issue.Fix.Localize(p),
+		}
+	}
+	return summary
+}
+
+// WriteJSON écrit le rapport en JSON, rédigé dans la langue de p.
+func (r Report) WriteJSON(w io.Writer, p i18n.Printer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.Summary(p))
+}
+
+// WriteText écrit une anomalie par ligne, suivie de sa correction, puis le total.
+func (r Report) WriteText(w io.Writer, p i18n.Printer) error {
+	for _, f := range r.Summary(p).Issues {
+		if _, err := fmt.Fprintf(w, "[%s, %s] %s\n    -> %s\n", f.Lang, f.Check, f.Message, f.Fix); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, p.N("app.lint.total", len(r.Issues), len(r.Issues), r.Entries))
+	return err
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"tp2/requestctx"
+)
+
+// Options décrit la configuration des logs applicatifs.
+type Options struct {
+	Level         string   // debug, info, warn ou error
+	Outputs       []string // stderr et/ou file
+	Dir           string   // dossier des fichiers de log
+	MaxSizeMB     int      // taille maximale d'un fichier avant rotation, 0 pour désactiver
+	RetentionDays int      // nombre de jours conservés, 0 pour tout garder
+	Compress      bool     // compression gzip des fichiers après rotation
+}
+
+var (
+	mu      sync.Mutex
+	logFile *RotatingFile
+)
+
+// ParseLevel convertit un niveau textuel en slog.Level.
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("niveau de log inconnu : %q", level)
+	}
+	return l, nil
+}
+
+// Setup construit le logger JSON décrit par opts et l'installe comme logger par défaut.
+// Le io.Closer renvoyé ferme les fichiers ouverts.
+func Setup(opts Options) (io.Closer, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var writers []io.Writer
+	var closer io.Closer = nopCloser{}
+	for _, output := range opts.Outputs {
+		switch strings.TrimSpace(output) {
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "file":
+			f, err := NewRotatingFile(opts.Dir, int64(opts.MaxSizeMB)<<20, time.Duration(opts.RetentionDays)*24*time.Hour, opts.Compress)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			logFile = f
+			mu.Unlock()
+			writers = append(writers, f)
+			closer = f
+		default:
+			return nil, fmt.Errorf("sortie de log inconnue : %q", output)
+		}
+	}
+	if len(writers) == 0 {
+		writers = append(writers, os.Stderr)
+	}
+
+	handler := slog.NewJSONHandler(io.MultiWriter(writers...), &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(NewContextHandler(handler)))
+	return closer, nil
+}
+
+// CheckWritable vérifie que le fichier de log, s'il est configuré, peut toujours être ouvert en écriture.
+func CheckWritable() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if logFile == nil {
+		return nil
+	}
+	f, err := os.OpenFile(logFile.Name(), os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// ContextHandler ajoute aux enregistrements l'identifiant de requête et
+// l'utilisateur présents dans le contexte.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info := requestctx.From(ctx); info != nil {
+		if info.RequestID != "" {
+			record.AddAttrs(slog.String("request_id", info.RequestID))
+		}
+		if info.User != "" {
+			record.AddAttrs(slog.String("user", info.User))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,187 @@
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Filter sélectionne des lignes de log JSON par niveau minimal, route et utilisateur.
+type Filter struct {
+	MinLevel *slog.Level
+	Route    string // préfixe du chemin de la requête
+	User     string
+}
+
+func (f Filter) empty() bool {
+	return f.MinLevel == nil && f.Route == "" && f.User == ""
+}
+
+// Match indique si la ligne correspond au filtre. Les lignes qui ne sont pas
+// du JSON (anciens fichiers) ne sont retenues qu'en l'absence de filtre.
+func (f Filter) Match(line string) bool {
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return f.empty()
+	}
+
+	if f.MinLevel != nil {
+		levelText, _ := entry["level"].(string)
+		level, err := ParseLevel(levelText)
+		if err != nil || level < *f.MinLevel {
+			return false
+		}
+	}
+	if f.Route != "" {
+		path, _ := entry["path"].(string)
+		if path == "" {
+			path, _ = entry["route"].(string)
+		}
+		if !strings.HasPrefix(path, f.Route) {
+			return false
+		}
+	}
+	if f.User != "" {
+		if user, _ := entry["user"].(string); user != f.User {
+			return false
+		}
+	}
+	return true
+}
+
+// Tail écrit dans w les n dernières lignes correspondant au filtre (toutes si n <= 0).
+func Tail(dir string, filter Filter, n int, w io.Writer) error {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, name := range files {
+		err := readFile(name, func(line string) {
+			if !filter.Match(line) {
+				return
+			}
+			lines = append(lines, line)
+			if n > 0 && len(lines) > n {
+				lines = lines[1:]
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+func readFile(name string, fn func(line string)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s : %w", name, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Follow suit le fichier de log le plus récent, y compris après une rotation,
+// et écrit dans w les nouvelles lignes correspondant au filtre jusqu'à l'annulation de ctx.
+func Follow(ctx context.Context, dir string, filter Filter, interval time.Duration, w io.Writer) error {
+	var (
+		current string
+		offset  int64
+		partial string
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		files, err := ListFiles(dir)
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			latest := files[len(files)-1]
+			if latest != current {
+				if current == "" {
+					// Au démarrage on ne reprend que ce qui sera écrit ensuite.
+					if info, err := os.Stat(latest); err == nil {
+						offset = info.Size()
+					}
+				} else {
+					offset = 0
+				}
+				current = latest
+				partial = ""
+			}
+
+			if !strings.HasSuffix(current, ".gz") {
+				offset, partial, err = readFrom(current, offset, partial, func(line string) {
+					if filter.Match(line) {
+						fmt.Fprintln(w, line)
+					}
+				})
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func readFrom(name string, offset int64, partial string, fn func(line string)) (int64, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return offset, partial, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, partial, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return offset, partial, err
+	}
+	offset += int64(len(data))
+
+	chunk := partial + string(data)
+	lines := strings.Split(chunk, "\n")
+	for _, line := range lines[:len(lines)-1] {
+		fn(line)
+	}
+	return offset, lines[len(lines)-1], nil
+}
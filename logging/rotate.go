@@ -0,0 +1,253 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix = "app_"
+	dateLayout = "2006-01-02"
+)
+
+// RotatingFile écrit dans logs/app_<date>.log et change de fichier à minuit
+// ou lorsque la taille maximale est atteinte. Les anciens fichiers sont
+// compressés en gzip puis supprimés au-delà de la durée de rétention.
+type RotatingFile struct {
+	Dir       string
+	MaxSize   int64         // taille maximale d'un fichier en octets, 0 pour désactiver
+	Retention time.Duration // durée de conservation, 0 pour tout garder
+	Compress  bool
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	day     string
+	size    int64
+	cleanup sync.WaitGroup
+	pruning sync.Mutex // une seule compression/suppression à la fois
+}
+
+// NewRotatingFile ouvre le fichier du jour dans dir.
+func NewRotatingFile(dir string, maxSize int64, retention time.Duration, compress bool) (*RotatingFile, error) {
+	rf := &RotatingFile{Dir: dir, MaxSize: maxSize, Retention: retention, Compress: compress, now: time.Now}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("impossible de créer le dossier logs : %w", err)
+	}
+	if err := rf.open(rf.now()); err != nil {
+		return nil, err
+	}
+	rf.startCleanup()
+	return rf, nil
+}
+
+func (rf *RotatingFile) currentName(day string) string {
+	return filepath.Join(rf.Dir, filePrefix+day+".log")
+}
+
+func (rf *RotatingFile) open(now time.Time) error {
+	day := now.Format(dateLayout)
+	f, err := os.OpenFile(rf.currentName(day), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("impossible d'ouvrir le fichier de log : %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.file = f
+	rf.day = day
+	rf.size = info.Size()
+	return nil
+}
+
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	now := rf.now()
+	switch {
+	case now.Format(dateLayout) != rf.day:
+		if err := rf.rotate(now, false); err != nil {
+			return 0, err
+		}
+	case rf.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.MaxSize:
+		if err := rf.rotate(now, true); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate ferme le fichier courant et en ouvre un nouveau. Lors d'une rotation
+// par taille, le fichier plein est renommé app_<date>.<n>.log.
+func (rf *RotatingFile) rotate(now time.Time, bySize bool) error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	if bySize {
+		if err := os.Rename(rf.currentName(rf.day), rf.nextBackupName(rf.day)); err != nil {
+			return err
+		}
+	}
+	if err := rf.open(now); err != nil {
+		return err
+	}
+	rf.startCleanup()
+	return nil
+}
+
+func (rf *RotatingFile) nextBackupName(day string) string {
+	for i := 1; ; i++ {
+		name := filepath.Join(rf.Dir, fmt.Sprintf("%s%s.%d.log", filePrefix, day, i))
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			if _, err := os.Stat(name + ".gz"); os.IsNotExist(err) {
+				return name
+			}
+		}
+	}
+}
+
+// Name renvoie le chemin du fichier en cours d'écriture.
+func (rf *RotatingFile) Name() string {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Name()
+}
+
+func (rf *RotatingFile) Close() error {
+	rf.cleanup.Wait()
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
+
+func (rf *RotatingFile) startCleanup() {
+	current := rf.file.Name()
+	rf.cleanup.Add(1)
+	go func() {
+		defer rf.cleanup.Done()
+		rf.pruning.Lock()
+		defer rf.pruning.Unlock()
+		if err := rf.compressAndPrune(current); err != nil {
+			// Le logger ne peut pas s'appeler lui-même ici : on écrit directement sur stderr.
+			fmt.Fprintln(os.Stderr, "rotation des logs :", err)
+		}
+	}()
+}
+
+// compressAndPrune compresse les fichiers autres que current et supprime ceux
+// dont la date dépasse la durée de rétention.
+func (rf *RotatingFile) compressAndPrune(current string) error {
+	files, err := ListFiles(rf.Dir)
+	if err != nil {
+		return err
+	}
+
+	limit := rf.now().Add(-rf.Retention)
+	for _, name := range files {
+		if name == current {
+			continue
+		}
+		if rf.Retention > 0 {
+			if day, ok := fileDay(name); ok && day.Before(limit) {
+				if err := os.Remove(name); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if rf.Compress && strings.HasSuffix(name, ".log") {
+			if err := gzipFile(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// ListFiles renvoie les fichiers de log du dossier, du plus ancien au plus récent.
+func ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) {
+			continue
+		}
+		if strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return fileOrder(files[i]) < fileOrder(files[j])
+	})
+	return files, nil
+}
+
+// fileOrder classe app_<date>.<n>.log avant app_<date>.log, qui reçoit les écritures les plus récentes.
+func fileOrder(name string) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(name), ".gz"), ".log")
+	parts := strings.SplitN(strings.TrimPrefix(base, filePrefix), ".", 2)
+	if len(parts) == 1 {
+		return parts[0] + "~"
+	}
+	n, _ := strconv.Atoi(parts[1])
+	return fmt.Sprintf("%s.%08d", parts[0], n)
+}
+
+func fileDay(name string) (time.Time, bool) {
+	base := strings.TrimPrefix(filepath.Base(name), filePrefix)
+	if len(base) < len(dateLayout) {
+		return time.Time{}, false
+	}
+	day, err := time.ParseInLocation(dateLayout, base[:len(dateLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// Un fichier couvre toute sa journée : on le compare à la fin de celle-ci.
+	return day.AddDate(0, 0, 1), true
+}
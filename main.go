@@ -2,46 +2,112 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
 	"tp2/api_mode"
+	"tp2/cli_mode"
+	"tp2/config"
 	"tp2/console_mode"
 	"tp2/db"
 	"tp2/dictionary"
+	"tp2/i18n"
+	"tp2/interfaces"
+	"tp2/lint"
+	"tp2/logging"
+	"tp2/metrics"
+	"tp2/webhooks"
 )
 
 func main() {
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := i18n.SetDefault(i18n.Resolve(cfg.UI.Locale)); err != nil {
+		log.Fatal(err)
+	}
+
+	mode := ""
+	if len(args) > 0 {
+		mode = strings.ToLower(args[0])
+	}
+
+	// Les sous-commandes lisent la même configuration : elle est validée avant elles.
+	if err := cfg.Validate(mode); err != nil {
+		log.Fatal(err)
+	}
 
-	wordRepository := &db.GormWordRepository{}
+	switch mode {
+	case "logs":
+		runCommand(cli_mode.RunLogs, cfg, args[1:])
+		return
+	case "migrate":
+		runCommand(cli_mode.RunMigrate, cfg, args[1:])
+		return
+	case "backup":
+		runCommand(cli_mode.RunBackup, cfg, args[1:])
+		return
+	case "restore":
+		runCommand(cli_mode.RunRestore, cfg, args[1:])
+		return
+	case "graph":
+		runCommand(cli_mode.RunGraph, cfg, args[1:])
+		return
+	case "lint":
+		runCommand(cli_mode.RunLint, cfg, args[1:])
+		return
+	}
+
+	// Les options globales se placent avant le mode : ailleurs, elles seraient ignorées.
+	if len(args) > 1 {
+		log.Fatal(i18n.T("main.unexpected_args", args[0], strings.Join(args[1:], " ")))
+	}
 
-	err := wordRepository.InitializeDB("db/database.db")
+	logCloser, err := logging.Setup(cfg.LoggingOptions())
+	if err != nil {
+		log.Fatal("Failed to configure logging:", err)
+	}
+	defer logCloser.Close()
+
+	validator, err := dictionary.NewValidator(cfg.Validation)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	wordRepository, err := db.NewWordRepository(cfg.Storage.Driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = wordRepository.InitializeDB(cfg.Database.Path)
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 	defer wordRepository.CloseDB()
 
-	mode := getModeFromArgs()
-
-	myDictionary := dictionary.New("dictionary.csv", wordRepository)
-	fmt.Println("Bienvenue dans le dico !")
+	myDictionary := dictionary.New(cfg.Dictionary.File, metrics.NewInstrumentedRepository(wordRepository))
+	myDictionary.SetValidator(validator)
+	startWebhooks(cfg, myDictionary, wordRepository)
+	fmt.Println(i18n.T("app.welcome"))
 
 	switch mode {
 	case "console", "1":
 		runConsoleMode(myDictionary)
 	case "api", "2":
-		runAPIMode(myDictionary)
+		runAPIMode(cfg, myDictionary, wordRepository)
 	default:
-		fmt.Println("Mode non reconnu. Choisissez le mode :")
-		fmt.Println("1. Console")
-		fmt.Println("2. API")
+		fmt.Println(i18n.T("main.unknown_mode"))
 
 		reader := bufio.NewReader(os.Stdin)
 		choice, err := reader.ReadString('\n')
 		if err != nil {
-			fmt.Println("Erreur de lecture de l'entrée utilisateur:", err)
+			fmt.Println(i18n.T("main.read_error", err))
 			return
 		}
 		choice = strings.TrimSpace(choice)
@@ -50,69 +116,130 @@ func main() {
 		case "1":
 			runConsoleMode(myDictionary)
 		case "2":
-			runAPIMode(myDictionary)
+			if err := cfg.Validate(choice); err != nil {
+				log.Fatal(err)
+			}
+			runAPIMode(cfg, myDictionary, wordRepository)
 		default:
-			fmt.Println("Choix invalide. Terminé.")
+			fmt.Println(i18n.T("main.invalid_mode"))
 		}
 	}
 }
 
-func getModeFromArgs() string {
-	if len(os.Args) < 2 {
-		return ""
+// runCommand exécute une sous-commande de la ligne de commande avec les arguments qui suivent son nom.
+func runCommand(command func(cfg config.Config, args []string) error, cfg config.Config, args []string) {
+	if err := command(cfg, args); err != nil {
+		log.Fatal(err)
 	}
-	return strings.ToLower(os.Args[1])
+}
+
+// startWebhooks livre les événements de la boîte d'envoi aux webhooks, dans
+// les deux modes, si le stockage les prend en charge.
+func startWebhooks(cfg config.Config, d *dictionary.Dictionary, wordRepository interfaces.WordRepository) {
+	webhookRepository, ok := wordRepository.(interfaces.WebhookRepository)
+	if !ok {
+		return
+	}
+	dispatcher := webhooks.NewDispatcher(webhookRepository, webhooks.Options{
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.InitialBackoff,
+		MaxBackoff:     cfg.Webhooks.MaxBackoff,
+		Timeout:        cfg.Webhooks.Timeout,
+		Concurrency:    4,
+	})
+	dispatcher.Start(context.Background(), d.Events())
 }
 
 func runConsoleMode(d *dictionary.Dictionary) {
+	lang := "" // langue de travail, toutes les langues par défaut
 	for {
-		fmt.Println("|| MENU Dico ||")
-		fmt.Println("Voir : 1")
-		fmt.Println("Ajouter : 2,  Définir : 3")
-		fmt.Println("Supprimer : 4, Sortir : 5")
-		fmt.Println("Choisissez ...")
+		fmt.Println(i18n.T("main.menu"))
 
 		reader := bufio.NewReader(os.Stdin)
 
 		choix, err := reader.ReadString('\n')
 		if err != nil {
-			fmt.Println("Erreur de lecture de l'entrée utilisateur:", err)
+			fmt.Println(i18n.T("main.read_error", err))
 			return
 		}
 		choix = strings.TrimSpace(choix)
 
 		switch choix {
 		case "1":
-			console_mode.ActionList(d)
+			console_mode.ActionList(d, lang)
 		case "2":
-			console_mode.ActionAddAsync(d, reader)
+			console_mode.ActionAddAsync(d, reader, lang)
 		case "3":
-			console_mode.ActionDefineAsync(d, reader)
+			console_mode.ActionDefineAsync(d, reader, lang)
 		case "4":
-			console_mode.ActionRemoveAsync(d, reader)
+			console_mode.ActionRemoveAsync(d, reader, lang)
 		case "5":
-			fmt.Println("Au revoir !")
+			fmt.Println(i18n.T("main.goodbye"))
 			return
+		case "6":
+			console_mode.ActionTag(d, reader, lang)
+		case "7":
+			console_mode.ActionBrowseTags(d, reader, lang)
+		case "8":
+			lang = console_mode.ActionChooseLang(reader, lang)
 		default:
-			fmt.Println("Choix invalide. Veuillez entrer un numéro valide.")
+			fmt.Println(i18n.T("main.invalid_menu_choice"))
 		}
 	}
 }
 
-func runAPIMode(d *dictionary.Dictionary) {
-	http.HandleFunc("/", api_mode.WelcomeHandler)
-	http.HandleFunc("/api/words/add", api_mode.ApiAddWordHandler(d))
-	http.HandleFunc("/api/words/define/", api_mode.ApiDefineWordHandler(d))
-	http.HandleFunc("/api/words/remove/", api_mode.ApiRemoveWordHandler(d))
-	http.HandleFunc("/api/words/list", api_mode.ApiListWordsHandler(d))
-	http.HandleFunc("/api/login", api_mode.LoginHandler)
+func runAPIMode(cfg config.Config, d *dictionary.Dictionary, wordRepository interfaces.WordRepository) {
+	srv := api_mode.NewServer(cfg)
+	if idempotencyRepository, ok := wordRepository.(interfaces.IdempotencyRepository); ok {
+		srv.SetIdempotencyStore(idempotencyRepository)
+	}
 
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = ":8080"
+	handle("/", api_mode.WelcomeHandler)
+	handle("/healthz", api_mode.HealthzHandler)
+	handle("/readyz", api_mode.ReadyzHandler(d))
+	handle("/version", api_mode.VersionHandler)
+	handle("/api/words/add", srv.Idempotent(srv.ApiAddWordHandler(d)))
+	handle("/api/words/define/", srv.Idempotent(srv.ApiDefineWordHandler(d)))
+	handle("/api/words/remove/", srv.Idempotent(srv.ApiRemoveWordHandler(d)))
+	handle("/api/words/list", srv.ApiListWordsHandler(d))
+	handle("/api/words/batch", srv.Idempotent(srv.ApiBatchHandler(d)))
+	handle("/api/words/search", srv.ApiSearchHandler(d))
+	handle("/api/words/suggest", srv.ApiSuggestHandler(d))
+	handle("/api/words/sounds-like", srv.ApiSoundsLikeHandler(d))
+	handle("/api/words/match", srv.ApiMatchHandler(d))
+	handle("/api/words/anagrams", srv.ApiAnagramsHandler(d))
+	handle("/api/words/containing", srv.ApiContainingHandler(d))
+	handle("/api/words/", srv.Idempotent(srv.ApiWordHandler(d)))
+	handle("/api/tags", srv.ApiTagsHandler(d))
+	handle("/api/collections", srv.Idempotent(srv.ApiCollectionsHandler(d)))
+	handle("/api/collections/", srv.Idempotent(srv.ApiCollectionsHandler(d)))
+	handle("/api/login", srv.LoginHandler)
+	handle("/api/events", srv.ApiEventsHandler(d))
+	handle("/api/events/ws", srv.ApiEventsWebSocketHandler(d))
+	if auditRepository, ok := wordRepository.(interfaces.AuditRepository); ok {
+		handle("/api/audit", srv.ApiAuditHandler(auditRepository))
+	}
+	if webhookRepository, ok := wordRepository.(interfaces.WebhookRepository); ok {
+		handle("/api/webhooks", srv.Idempotent(srv.ApiWebhooksHandler(webhookRepository)))
+		handle("/api/webhooks/", srv.Idempotent(srv.ApiDeleteWebhookHandler(webhookRepository)))
+		handle("/api/webhooks/deliveries", srv.ApiWebhookDeliveriesHandler(webhookRepository))
+	}
+	if backupRepository, ok := wordRepository.(interfaces.BackupRepository); ok {
+		handle("/api/admin/backup", srv.Idempotent(srv.ApiBackupHandler(backupRepository)))
 	}
+	handle("/api/admin/lint", srv.ApiLintHandler(wordRepository, lint.OptionsFrom(cfg.Validation)))
+	if gormRepository, ok := wordRepository.(*db.GormWordRepository); ok && cfg.Backup.Interval > 0 {
+		go gormRepository.ScheduleBackups(context.Background(), cfg.Backup.Dir, cfg.Backup.Interval, cfg.Backup.Retention)
+	}
+	http.Handle("/metrics", metrics.Handler())
+
+	port := cfg.Server.Port
+	fmt.Println(i18n.T("main.server_starting", port))
+	slog.Info("Server started", "port", port)
+	log.Fatal(http.ListenAndServe(port, api_mode.RequestLogger(srv.Localize(http.DefaultServeMux))))
+}
 
-	fmt.Println("Starting server on", port)
-	api_mode.LogToFile("runAPIMode", fmt.Sprintf("Server started on %s", port))
-	log.Fatal(http.ListenAndServe(port, nil))
+// handle enregistre un handler de l'API en le mesurant sous son motif de route.
+func handle(pattern string, handler http.HandlerFunc) {
+	http.HandleFunc(pattern, api_mode.Instrument(pattern, handler))
 }
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dico"
+
+var (
+	// Registry regroupe toutes les métriques exposées sur /metrics.
+	Registry = prometheus.NewRegistry()
+
+	HTTPRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Nombre de requêtes HTTP traitées, par route, méthode et statut.",
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Durée de traitement des requêtes HTTP, par route, méthode et statut.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	RepositoryOperationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "repository_operations_total",
+			Help:      "Nombre d'opérations sur le dépôt de mots, par opération et résultat.",
+		},
+		[]string{"operation", "result"},
+	)
+
+	RepositoryOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_operation_duration_seconds",
+			Help:      "Durée des opérations sur le dépôt de mots, par opération et résultat.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		},
+		[]string{"operation", "result"},
+	)
+
+	wordsTotal = &wordsCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "words_total"),
+			"Nombre total de mots dans le dictionnaire.",
+			nil, nil,
+		),
+	}
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		RepositoryOperationsTotal,
+		RepositoryOperationDuration,
+		wordsTotal,
+	)
+}
+
+// Handler renvoie le handler HTTP qui expose les métriques au format Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// wordsCollector calcule la jauge du nombre de mots au moment de la collecte.
+type wordsCollector struct {
+	desc  *prometheus.Desc
+	mu    sync.RWMutex
+	count func() (int, error)
+}
+
+func (c *wordsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *wordsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	count := c.count
+	c.mu.RUnlock()
+
+	if count == nil {
+		return
+	}
+	n, err := count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n))
+}
+
+// SetWordCounter définit la fonction utilisée pour calculer la jauge du nombre de mots.
+func SetWordCounter(count func() (int, error)) {
+	wordsTotal.mu.Lock()
+	defer wordsTotal.mu.Unlock()
+	wordsTotal.count = count
+}
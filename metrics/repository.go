@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"context"
+	"time"
+	"tp2/interfaces"
+)
+
+// InstrumentedWordRepository décore un interfaces.WordRepository pour mesurer
+// chaque opération (nombre, résultat et durée).
+type InstrumentedWordRepository struct {
+	inner interfaces.WordRepository
+}
+
+// NewInstrumentedRepository enveloppe le dépôt et l'utilise pour la jauge du nombre de mots.
+func NewInstrumentedRepository(inner interfaces.WordRepository) *InstrumentedWordRepository {
+	SetWordCounter(func() (int, error) {
+		words, err := inner.ListWordsFromDB(context.Background())
+		return len(words), err
+	})
+	return &InstrumentedWordRepository{inner: inner}
+}
+
+// observe est appelée en defer : errp pointe vers l'erreur nommée renvoyée par l'opération.
+func observe(operation string, start time.Time, errp *error) {
+	result := "success"
+	if *errp != nil {
+		result = "failure"
+	}
+	RepositoryOperationsTotal.WithLabelValues(operation, result).Inc()
+	RepositoryOperationDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
+
+func (r *InstrumentedWordRepository) InitializeDB(dbPath string) (err error) {
+	defer observe("initialize", time.Now(), &err)
+	return r.inner.InitializeDB(dbPath)
+}
+
+func (r *InstrumentedWordRepository) CloseDB() {
+	r.inner.CloseDB()
+}
+
+func (r *InstrumentedWordRepository) Ping(ctx context.Context) (err error) {
+	defer observe("ping", time.Now(), &err)
+	return r.inner.Ping(ctx)
+}
+
+func (r *InstrumentedWordRepository) ListWordsFromDB(ctx context.Context) (words []interfaces.Word, err error) {
+	defer observe("list", time.Now(), &err)
+	return r.inner.ListWordsFromDB(ctx)
+}
+
+func (r *InstrumentedWordRepository) AddWordToDB(ctx context.Context, entry interfaces.Word) (err error) {
+	defer observe("add", time.Now(), &err)
+	return r.inner.AddWordToDB(ctx, entry)
+}
+
+func (r *InstrumentedWordRepository) DeleteWordFromDB(ctx context.Context, word, lang string) (err error) {
+	defer observe("delete", time.Now(), &err)
+	return r.inner.DeleteWordFromDB(ctx, word, lang)
+}
+
+func (r *InstrumentedWordRepository) UpdateWordInDB(ctx context.Context, word, lang, newDefinition string) (err error) {
+	defer observe("update", time.Now(), &err)
+	return r.inner.UpdateWordInDB(ctx, word, lang, newDefinition)
+}
+
+func (r *InstrumentedWordRepository) GetWordFromDB(ctx context.Context, word, lang string) (w interfaces.Word, err error) {
+	defer observe("get", time.Now(), &err)
+	return r.inner.GetWordFromDB(ctx, word, lang)
+}
+
+func (r *InstrumentedWordRepository) ListWordsByKey(ctx context.Context, word string) (words []interfaces.Word, err error) {
+	defer observe("list_words_by_key", time.Now(), &err)
+	return r.inner.ListWordsByKey(ctx, word)
+}
+
+func (r *InstrumentedWordRepository) ApplyBatch(ctx context.Context, ops []interfaces.BatchOperation, atomic bool) (results []interfaces.BatchResult, err error) {
+	defer observe("batch", time.Now(), &err)
+	return r.inner.ApplyBatch(ctx, ops, atomic)
+}
+
+func (r *InstrumentedWordRepository) ListRevisions(ctx context.Context, afterID uint64, limit int) (revisions []interfaces.Revision, err error) {
+	defer observe("list_revisions", time.Now(), &err)
+	return r.inner.ListRevisions(ctx, afterID, limit)
+}
+
+func (r *InstrumentedWordRepository) LastRevisionID(ctx context.Context) (id uint64, err error) {
+	defer observe("last_revision", time.Now(), &err)
+	return r.inner.LastRevisionID(ctx)
+}
+
+func (r *InstrumentedWordRepository) AddRelation(ctx context.Context, word, lang, relationType, target string) (err error) {
+	defer observe("add_relation", time.Now(), &err)
+	return r.inner.AddRelation(ctx, word, lang, relationType, target)
+}
+
+func (r *InstrumentedWordRepository) RemoveRelation(ctx context.Context, word, lang, relationType, target string) (err error) {
+	defer observe("remove_relation", time.Now(), &err)
+	return r.inner.RemoveRelation(ctx, word, lang, relationType, target)
+}
+
+func (r *InstrumentedWordRepository) ListRelations(ctx context.Context, word, lang string) (relations []interfaces.Relation, err error) {
+	defer observe("list_relations", time.Now(), &err)
+	return r.inner.ListRelations(ctx, word, lang)
+}
+
+func (r *InstrumentedWordRepository) ListRelationEdges(ctx context.Context, lang string, words []string) (edges []interfaces.RelationEdge, err error) {
+	defer observe("list_relation_edges", time.Now(), &err)
+	return r.inner.ListRelationEdges(ctx, lang, words)
+}
+
+func (r *InstrumentedWordRepository) TagWord(ctx context.Context, word, lang, tag string) (err error) {
+	defer observe("tag_word", time.Now(), &err)
+	return r.inner.TagWord(ctx, word, lang, tag)
+}
+
+func (r *InstrumentedWordRepository) UntagWord(ctx context.Context, word, lang, tag string) (err error) {
+	defer observe("untag_word", time.Now(), &err)
+	return r.inner.UntagWord(ctx, word, lang, tag)
+}
+
+func (r *InstrumentedWordRepository) WordTags(ctx context.Context, word, lang string) (tags []string, err error) {
+	defer observe("word_tags", time.Now(), &err)
+	return r.inner.WordTags(ctx, word, lang)
+}
+
+func (r *InstrumentedWordRepository) ListTags(ctx context.Context) (tags []interfaces.TagCount, err error) {
+	defer observe("list_tags", time.Now(), &err)
+	return r.inner.ListTags(ctx)
+}
+
+func (r *InstrumentedWordRepository) ListWordsBySound(ctx context.Context, word, lang string) (words []interfaces.Word, err error) {
+	defer observe("list_words_by_sound", time.Now(), &err)
+	return r.inner.ListWordsBySound(ctx, word, lang)
+}
+
+func (r *InstrumentedWordRepository) SearchWords(ctx context.Context, query, lang, tag string, limit int) (hits []interfaces.SearchHit, err error) {
+	defer observe("search_words", time.Now(), &err)
+	return r.inner.SearchWords(ctx, query, lang, tag, limit)
+}
+
+func (r *InstrumentedWordRepository) SuggestWords(ctx context.Context, query, lang string, limit int) (suggestions []interfaces.EntryRef, err error) {
+	defer observe("suggest_words", time.Now(), &err)
+	return r.inner.SuggestWords(ctx, query, lang, limit)
+}
+
+func (r *InstrumentedWordRepository) MatchWords(ctx context.Context, pattern, lang string, limit int) (words []interfaces.Word, err error) {
+	defer observe("match_words", time.Now(), &err)
+	return r.inner.MatchWords(ctx, pattern, lang, limit)
+}
+
+func (r *InstrumentedWordRepository) ListAnagrams(ctx context.Context, letters, lang string, limit int) (words []interfaces.Word, err error) {
+	defer observe("list_anagrams", time.Now(), &err)
+	return r.inner.ListAnagrams(ctx, letters, lang, limit)
+}
+
+func (r *InstrumentedWordRepository) ListWordsContaining(ctx context.Context, letters, lang string, limit int) (words []interfaces.Word, err error) {
+	defer observe("list_words_containing", time.Now(), &err)
+	return r.inner.ListWordsContaining(ctx, letters, lang, limit)
+}
+
+func (r *InstrumentedWordRepository) ListWordsByTags(ctx context.Context, tags []string) (words []interfaces.Word, err error) {
+	defer observe("list_words_by_tags", time.Now(), &err)
+	return r.inner.ListWordsByTags(ctx, tags)
+}
+
+func (r *InstrumentedWordRepository) CreateCollection(ctx context.Context, collection interfaces.Collection, lang string) (err error) {
+	defer observe("create_collection", time.Now(), &err)
+	return r.inner.CreateCollection(ctx, collection, lang)
+}
+
+func (r *InstrumentedWordRepository) UpdateCollection(ctx context.Context, collection interfaces.Collection, lang string) (err error) {
+	defer observe("update_collection", time.Now(), &err)
+	return r.inner.UpdateCollection(ctx, collection, lang)
+}
+
+func (r *InstrumentedWordRepository) DeleteCollection(ctx context.Context, name string) (err error) {
+	defer observe("delete_collection", time.Now(), &err)
+	return r.inner.DeleteCollection(ctx, name)
+}
+
+func (r *InstrumentedWordRepository) GetCollection(ctx context.Context, name string) (collection interfaces.Collection, err error) {
+	defer observe("get_collection", time.Now(), &err)
+	return r.inner.GetCollection(ctx, name)
+}
+
+func (r *InstrumentedWordRepository) ListCollections(ctx context.Context) (collections []interfaces.Collection, err error) {
+	defer observe("list_collections", time.Now(), &err)
+	return r.inner.ListCollections(ctx)
+}
+
+func (r *InstrumentedWordRepository) AddTranslation(ctx context.Context, entry, translation interfaces.EntryRef) (err error) {
+	defer observe("add_translation", time.Now(), &err)
+	return r.inner.AddTranslation(ctx, entry, translation)
+}
+
+func (r *InstrumentedWordRepository) RemoveTranslation(ctx context.Context, entry, translation interfaces.EntryRef) (err error) {
+	defer observe("remove_translation", time.Now(), &err)
+	return r.inner.RemoveTranslation(ctx, entry, translation)
+}
+
+func (r *InstrumentedWordRepository) ListTranslations(ctx context.Context, entry interfaces.EntryRef) (translations []interfaces.EntryRef, err error) {
+	defer observe("list_translations", time.Now(), &err)
+	return r.inner.ListTranslations(ctx, entry)
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"tp2/interfaces"
 )
 
@@ -8,17 +9,17 @@ var (
 	DB interfaces.WordRepository
 )
 
-func AddWordToDB(word, definition string) error {
-	return DB.AddWordToDB(word, definition)
+func AddWordToDB(ctx context.Context, entry interfaces.Word) error {
+	return DB.AddWordToDB(ctx, entry)
 }
 
-func DeleteWordFromDB(word string) error {
-	return DB.DeleteWordFromDB(word)
+func DeleteWordFromDB(ctx context.Context, word, lang string) error {
+	return DB.DeleteWordFromDB(ctx, word, lang)
 }
 
-func ListWordsFromDB() ([]interfaces.Word, error) {
-	return DB.ListWordsFromDB()
+func ListWordsFromDB(ctx context.Context) ([]interfaces.Word, error) {
+	return DB.ListWordsFromDB(ctx)
 }
-func GetWordFromDB(word string) (interfaces.Word, error) {
-	return DB.GetWordFromDB(word)
+func GetWordFromDB(ctx context.Context, word, lang string) (interfaces.Word, error) {
+	return DB.GetWordFromDB(ctx, word, lang)
 }
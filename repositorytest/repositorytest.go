@@ -0,0 +1,958 @@
+// Package repositorytest fournit une suite de tests commune à toutes les
+// implémentations de interfaces.WordRepository.
+//
+//	func TestMonDepot(t *testing.T) {
+//		repositorytest.Run(t, func(t *testing.T) interfaces.WordRepository {
+//			repo := &MonDepot{}
+//			if err := repo.InitializeDB(filepath.Join(t.TempDir(), "dico")); err != nil {
+//				t.Fatal(err)
+//			}
+//			return repo
+//		})
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"tp2/interfaces"
+	"tp2/search"
+)
+
+// Factory renvoie un dépôt vide et initialisé. La suite appelle CloseDB à la fin de chaque test.
+type Factory func(t *testing.T) interfaces.WordRepository
+
+// Run exécute toute la suite sur des dépôts créés par newRepository.
+func Run(t *testing.T, newRepository Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo interfaces.WordRepository)
+	}{
+		{"CRUD", testCRUD},
+		{"EmptyList", testEmptyList},
+		{"Duplicates", testDuplicates},
+		{"MissingWords", testMissingWords},
+		{"UnicodeHeadwords", testUnicodeHeadwords},
+		{"WordKeys", testWordKeys},
+		{"LongDefinitions", testLongDefinitions},
+		{"PartOfSpeech", testPartOfSpeech},
+		{"Ordering", testOrdering},
+		{"ConcurrentWriters", testConcurrentWriters},
+		{"Batch", testBatch},
+		{"Revisions", testRevisions},
+		{"Relations", testRelations},
+		{"Tags", testTags},
+		{"Collections", testCollections},
+		{"Languages", testLanguages},
+		{"SoundsLike", testSoundsLike},
+		{"Search", testSearch},
+		{"WordGames", testWordGames},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newRepository(t)
+			t.Cleanup(repo.CloseDB)
+			tc.fn(t, repo)
+		})
+	}
+}
+
+func mustAdd(t *testing.T, repo interfaces.WordRepository, word, definition string) {
+	t.Helper()
+	if err := repo.AddWordToDB(context.Background(), interfaces.Word{Word: word, Lang: "fr", Definition: definition}); err != nil {
+		t.Fatalf("AddWordToDB(%q) : %v", word, err)
+	}
+}
+
+func headwords(words []interfaces.Word) []string {
+	result := make([]string, len(words))
+	for i, w := range words {
+		result[i] = w.Word
+	}
+	return result
+}
+
+func testCRUD(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatalf("Ping : %v", err)
+	}
+
+	mustAdd(t, repo, "go", "langage")
+	word, err := repo.GetWordFromDB(ctx, "go", "fr")
+	if err != nil || word.Word != "go" || word.Definition != "langage" {
+		t.Fatalf("GetWordFromDB après ajout : %+v, %v", word, err)
+	}
+
+	if err := repo.UpdateWordInDB(ctx, "go", "fr", "langage compilé"); err != nil {
+		t.Fatalf("UpdateWordInDB : %v", err)
+	}
+	word, err = repo.GetWordFromDB(ctx, "go", "fr")
+	if err != nil || word.Definition != "langage compilé" {
+		t.Fatalf("GetWordFromDB après modification : %+v, %v", word, err)
+	}
+
+	if err := repo.DeleteWordFromDB(ctx, "go", "fr"); err != nil {
+		t.Fatalf("DeleteWordFromDB : %v", err)
+	}
+	if _, err := repo.GetWordFromDB(ctx, "go", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Fatalf("ErrWordNotFound attendue après suppression, obtenu %v", err)
+	}
+}
+
+func testEmptyList(t *testing.T, repo interfaces.WordRepository) {
+	words, err := repo.ListWordsFromDB(context.Background())
+	if err != nil {
+		t.Fatalf("ListWordsFromDB : %v", err)
+	}
+	if len(words) != 0 {
+		t.Fatalf("liste vide attendue, obtenu %v", headwords(words))
+	}
+}
+
+func testDuplicates(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "php", "langage")
+
+	err := repo.AddWordToDB(ctx, interfaces.Word{Word: "php", Lang: "fr", Definition: "autre définition"})
+	if !errors.Is(err, interfaces.ErrWordExists) {
+		t.Fatalf("ErrWordExists attendue pour un doublon, obtenu %v", err)
+	}
+
+	word, err := repo.GetWordFromDB(ctx, "php", "fr")
+	if err != nil || word.Definition != "langage" {
+		t.Fatalf("le doublon refusé ne doit pas modifier le mot : %+v, %v", word, err)
+	}
+	words, _ := repo.ListWordsFromDB(ctx)
+	if len(words) != 1 {
+		t.Fatalf("un seul mot attendu, obtenu %v", headwords(words))
+	}
+}
+
+func testMissingWords(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+
+	if _, err := repo.GetWordFromDB(ctx, "absent", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("GetWordFromDB : ErrWordNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.UpdateWordInDB(ctx, "absent", "fr", "définition"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("UpdateWordInDB : ErrWordNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.DeleteWordFromDB(ctx, "absent", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("DeleteWordFromDB : ErrWordNotFound attendue, obtenu %v", err)
+	}
+	if words, _ := repo.ListWordsFromDB(ctx); len(words) != 0 {
+		t.Errorf("les opérations sur un mot absent ne doivent rien créer : %v", headwords(words))
+	}
+}
+
+func testUnicodeHeadwords(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	entries := map[string]string{
+		"éléphant":  "grand mammifère à trompe",
+		"naïveté":   "excès de confiance",
+		"日本語":       "langue japonaise",
+		"straße":    "rue en allemand",
+		"emoji 🐘":   "un éléphant",
+		"œuf":       "produit de la poule",
+		"Ελληνικά":  "langue grecque",
+		"кириллица": "alphabet cyrillique",
+	}
+
+	for word, definition := range entries {
+		mustAdd(t, repo, word, definition)
+	}
+	for word, definition := range entries {
+		got, err := repo.GetWordFromDB(ctx, word, "fr")
+		if err != nil || got.Word != word || got.Definition != definition {
+			t.Errorf("GetWordFromDB(%q) : %+v, %v", word, got, err)
+		}
+	}
+	if words, _ := repo.ListWordsFromDB(ctx); len(words) != len(entries) {
+		t.Errorf("%d mots attendus, obtenu %v", len(entries), headwords(words))
+	}
+}
+
+// testWordKeys vérifie que les mots sont enregistrés en NFC et retrouvés sans
+// tenir compte de la casse, des accents ni des ligatures.
+func testWordKeys(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, " E\u0301le\u0301phant ", "grand mammifère") // É et é décomposés
+	mustAdd(t, repo, "cœur", "organe")
+
+	got, err := repo.GetWordFromDB(ctx, "elephant", "fr")
+	if err != nil || got.Word != "Éléphant" {
+		t.Fatalf("GetWordFromDB(elephant) : %+v, %v ; Éléphant en NFC attendu", got, err)
+	}
+	for _, duplicate := range []string{"éléphant", "ELEPHANT", "coeur"} {
+		if err := repo.AddWordToDB(ctx, interfaces.Word{Word: duplicate, Lang: "fr", Definition: "doublon"}); !errors.Is(err, interfaces.ErrWordExists) {
+			t.Errorf("AddWordToDB(%q) : ErrWordExists attendu, obtenu %v", duplicate, err)
+		}
+	}
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "elephant", Lang: "en", Definition: "large mammal"}); err != nil {
+		t.Errorf("la même clé doit rester libre dans une autre langue : %v", err)
+	}
+
+	if err := repo.UpdateWordInDB(ctx, "ÉLÉPHANT", "fr", "pachyderme"); err != nil {
+		t.Fatalf("UpdateWordInDB : %v", err)
+	}
+	if err := repo.AddRelation(ctx, "elephant", "fr", interfaces.RelationSeeAlso, "COEUR"); err != nil {
+		t.Fatalf("AddRelation : %v", err)
+	}
+	if err := repo.AddRelation(ctx, "cœur", "fr", interfaces.RelationSeeAlso, "Éléphant"); !errors.Is(err, interfaces.ErrRelationExists) {
+		t.Errorf("relation symétrique en double : ErrRelationExists attendu, obtenu %v", err)
+	}
+	relations, err := repo.ListRelations(ctx, "éléphant", "fr")
+	if err != nil || len(relations) != 1 || relations[0].Target != "cœur" {
+		t.Errorf("ListRelations : %v, %v ; cible cœur attendue", relations, err)
+	}
+	if err := repo.TagWord(ctx, "Coeur", "fr", "corps"); err != nil {
+		t.Fatalf("TagWord : %v", err)
+	}
+	if words, _ := repo.ListWordsByTags(ctx, []string{"corps"}); len(words) != 1 || words[0].Word != "cœur" {
+		t.Errorf("ListWordsByTags : %v ; cœur attendu", headwords(words))
+	}
+
+	if err := repo.DeleteWordFromDB(ctx, "elephant", "fr"); err != nil {
+		t.Fatalf("DeleteWordFromDB : %v", err)
+	}
+	if relations, _ := repo.ListRelations(ctx, "coeur", "fr"); len(relations) != 0 {
+		t.Errorf("les relations du mot supprimé doivent disparaître : %v", relations)
+	}
+}
+
+func testLongDefinitions(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	long := strings.Repeat("définition très longue ", 50000) // ~1,2 Mo
+
+	mustAdd(t, repo, "long", long)
+	word, err := repo.GetWordFromDB(ctx, "long", "fr")
+	if err != nil || word.Definition != long {
+		t.Fatalf("la définition longue doit être conservée à l'identique (%d octets lus, %v)", len(word.Definition), err)
+	}
+
+	if err := repo.UpdateWordInDB(ctx, "long", "fr", long+"fin"); err != nil {
+		t.Fatalf("UpdateWordInDB : %v", err)
+	}
+	word, _ = repo.GetWordFromDB(ctx, "long", "fr")
+	if !strings.HasSuffix(word.Definition, "fin") {
+		t.Fatalf("la définition longue modifiée n'a pas été conservée")
+	}
+}
+
+// testPartOfSpeech vérifie que la nature grammaticale est enregistrée et relue,
+// et qu'une entrée peut s'en passer.
+func testPartOfSpeech(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "courir", Lang: "fr", Definition: "aller vite", PartOfSpeech: "verbe"}); err != nil {
+		t.Fatalf("AddWordToDB : %v", err)
+	}
+	mustAdd(t, repo, "sans", "sans nature")
+
+	if word, err := repo.GetWordFromDB(ctx, "courir", "fr"); err != nil || word.PartOfSpeech != "verbe" {
+		t.Fatalf("nature grammaticale relue : %q (%v)", word.PartOfSpeech, err)
+	}
+	if err := repo.UpdateWordInDB(ctx, "courir", "fr", "se déplacer vite"); err != nil {
+		t.Fatalf("UpdateWordInDB : %v", err)
+	}
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, w := range words {
+		got[w.Word] = w.PartOfSpeech
+	}
+	if got["courir"] != "verbe" || got["sans"] != "" {
+		t.Errorf("natures grammaticales listées : %v", got)
+	}
+}
+
+// testOrdering vérifie que ListWordsFromDB renvoie les mots dans l'ordre d'insertion.
+func testOrdering(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	for _, word := range []string{"zeta", "alpha", "mu", "beta"} {
+		mustAdd(t, repo, word, "lettre grecque")
+	}
+	if err := repo.UpdateWordInDB(ctx, "alpha", "fr", "première lettre"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteWordFromDB(ctx, "mu", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, repo, "mu", "lettre grecque")
+
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(headwords(words), ",")
+	if want := "zeta,alpha,beta,mu"; got != want {
+		t.Fatalf("ordre %s attendu, obtenu %s", want, got)
+	}
+}
+
+func testConcurrentWriters(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	const writers, perWriter = 8, 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if err := repo.AddWordToDB(ctx, interfaces.Word{Word: fmt.Sprintf("mot-%d-%d", w, i), Lang: "fr", Definition: "définition"}); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("écriture concurrente : %v", err)
+	}
+
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil || len(words) != writers*perWriter {
+		t.Fatalf("%d mots attendus, obtenu %d (%v)", writers*perWriter, len(words), err)
+	}
+
+	// Plusieurs ajouts simultanés du même mot : un seul doit réussir.
+	var successes int
+	var mu sync.Mutex
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.AddWordToDB(ctx, interfaces.Word{Word: "disputé", Lang: "fr", Definition: "définition"})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				successes++
+			case !errors.Is(err, interfaces.ErrWordExists):
+				t.Errorf("ErrWordExists attendue pour un ajout concurrent, obtenu %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if successes != 1 {
+		t.Fatalf("un seul ajout concurrent doit réussir, %d ont réussi", successes)
+	}
+}
+
+func testBatch(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "existant", "déjà là")
+
+	ops := []interfaces.BatchOperation{
+		{Op: interfaces.BatchAdd, Word: "un", Lang: "fr", Definition: "premier", PartOfSpeech: "déterminant"},
+		{Op: interfaces.BatchDefine, Word: "existant", Lang: "fr", Definition: "redéfini"},
+		{Op: interfaces.BatchAdd, Word: "existant", Lang: "fr", Definition: "doublon"},
+		{Op: interfaces.BatchRemove, Word: "absent", Lang: "fr"},
+		{Op: "rename", Word: "un", Lang: "fr"},
+		{Op: interfaces.BatchAdd, Word: "deux", Definition: "sans langue"},
+	}
+
+	// En mode atomique, une seule erreur annule tout le lot.
+	results, err := repo.ApplyBatch(ctx, ops, true)
+	if !errors.Is(err, interfaces.ErrBatchRolledBack) {
+		t.Fatalf("ErrBatchRolledBack attendue, obtenu %v", err)
+	}
+	if len(results) != len(ops) || results[0].Err != nil || !errors.Is(results[2].Err, interfaces.ErrWordExists) ||
+		!errors.Is(results[3].Err, interfaces.ErrWordNotFound) || !errors.Is(results[4].Err, interfaces.ErrUnknownBatchOp) ||
+		!errors.Is(results[5].Err, interfaces.ErrInvalidLang) {
+		t.Fatalf("résultats par opération inattendus : %v", results)
+	}
+	if _, err := repo.GetWordFromDB(ctx, "un", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("l'ajout doit être annulé avec le lot : %v", err)
+	}
+	if word, _ := repo.GetWordFromDB(ctx, "existant", "fr"); word.Definition != "déjà là" {
+		t.Errorf("la redéfinition doit être annulée avec le lot : %q", word.Definition)
+	}
+
+	// Sans atomicité, seules les opérations en échec sont ignorées.
+	results, err = repo.ApplyBatch(ctx, ops, false)
+	if err != nil {
+		t.Fatalf("ApplyBatch non atomique : %v", err)
+	}
+	if results[0].Err != nil || results[1].Err != nil || results[2].Err == nil {
+		t.Fatalf("résultats par opération inattendus : %v", results)
+	}
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(headwords(words), ","); got != "existant,un" {
+		t.Errorf("mots après le lot : %s", got)
+	}
+	if word, _ := repo.GetWordFromDB(ctx, "existant", "fr"); word.Definition != "redéfini" {
+		t.Errorf("la redéfinition doit être appliquée : %q", word.Definition)
+	}
+	if word, _ := repo.GetWordFromDB(ctx, "un", "fr"); word.PartOfSpeech != "déterminant" {
+		t.Errorf("la nature grammaticale d'un ajout par lot doit être enregistrée : %q", word.PartOfSpeech)
+	}
+
+	// Chaque résultat porte l'entrée telle qu'enregistrée, pas telle que saisie.
+	results, err = repo.ApplyBatch(ctx, []interfaces.BatchOperation{
+		{Op: interfaces.BatchDefine, Word: "EXISTANT", Lang: "FR", Definition: "encore redéfini"},
+		{Op: interfaces.BatchRemove, Word: "Un", Lang: "fr"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interfaces.Word{
+		{Word: "existant", Lang: "fr", Definition: "encore redéfini"},
+		{Word: "un", Lang: "fr", Definition: "premier", PartOfSpeech: "déterminant"},
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Entry != want[i] {
+			t.Errorf("résultat %d : %+v, attendu %+v", i, result, want[i])
+		}
+	}
+}
+
+func testRevisions(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	if id, err := repo.LastRevisionID(ctx); err != nil || id != 0 {
+		t.Fatalf("LastRevisionID sur un dépôt vide : %d, %v", id, err)
+	}
+
+	mustAdd(t, repo, "chat", "félin")
+	if err := repo.UpdateWordInDB(ctx, "chat", "fr", "petit félin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteWordFromDB(ctx, "chat", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	// Un échec ou un lot annulé n'enregistre aucune révision.
+	if err := repo.DeleteWordFromDB(ctx, "chat", "fr"); err == nil {
+		t.Fatal("la suppression d'un mot absent doit échouer")
+	}
+	if _, err := repo.ApplyBatch(ctx, []interfaces.BatchOperation{
+		{Op: interfaces.BatchAdd, Word: "chien", Lang: "fr", Definition: "canidé"},
+		{Op: interfaces.BatchRemove, Word: "absent", Lang: "fr"},
+	}, true); !errors.Is(err, interfaces.ErrBatchRolledBack) {
+		t.Fatalf("ErrBatchRolledBack attendue, obtenu %v", err)
+	}
+	if _, err := repo.ApplyBatch(ctx, []interfaces.BatchOperation{
+		{Op: interfaces.BatchAdd, Word: "chien", Lang: "fr", Definition: "canidé"},
+		{Op: interfaces.BatchRemove, Word: "absent", Lang: "fr"},
+	}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := repo.ListRevisions(ctx, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interfaces.Revision{
+		{Type: "word.added", Word: "chat", Lang: "fr", Definition: "félin"},
+		{Type: "word.updated", Word: "chat", Lang: "fr", Definition: "petit félin"},
+		{Type: "word.removed", Word: "chat", Lang: "fr"},
+		{Type: "word.added", Word: "chien", Lang: "fr", Definition: "canidé"},
+	}
+	if len(revisions) != len(want) {
+		t.Fatalf("révisions inattendues : %+v", revisions)
+	}
+	for i, r := range revisions {
+		if i > 0 && r.ID <= revisions[i-1].ID {
+			t.Errorf("identifiants non croissants : %+v", revisions)
+		}
+		if r.CreatedAt.IsZero() {
+			t.Errorf("révision %d sans date", i)
+		}
+		got := interfaces.Revision{Type: r.Type, Word: r.Word, Lang: r.Lang, Definition: r.Definition}
+		if got != want[i] {
+			t.Errorf("révision %d : %+v, attendu %+v", i, got, want[i])
+		}
+	}
+
+	if id, err := repo.LastRevisionID(ctx); err != nil || id != revisions[3].ID {
+		t.Errorf("LastRevisionID : %d, %v, attendu %d", id, err, revisions[3].ID)
+	}
+	page, err := repo.ListRevisions(ctx, revisions[0].ID, 2)
+	if err != nil || len(page) != 2 || page[0].ID != revisions[1].ID || page[1].ID != revisions[2].ID {
+		t.Errorf("page de révisions inattendue : %+v, %v", page, err)
+	}
+}
+
+func testRelations(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "voiture", "véhicule à moteur")
+	mustAdd(t, repo, "automobile", "voiture")
+	mustAdd(t, repo, "véhicule", "moyen de transport")
+	mustAdd(t, repo, "piéton", "personne à pied")
+
+	for _, rel := range []struct{ word, relationType, target string }{
+		{"voiture", interfaces.RelationSynonym, "automobile"},
+		{"voiture", interfaces.RelationHypernym, "véhicule"},
+		{"piéton", interfaces.RelationAntonym, "voiture"},
+	} {
+		if err := repo.AddRelation(ctx, rel.word, "fr", rel.relationType, rel.target); err != nil {
+			t.Fatalf("AddRelation(%v) : %v", rel, err)
+		}
+	}
+
+	// Une relation symétrique n'existe qu'une fois, dans un sens ou dans l'autre.
+	if err := repo.AddRelation(ctx, "automobile", "fr", interfaces.RelationSynonym, "voiture"); !errors.Is(err, interfaces.ErrRelationExists) {
+		t.Errorf("ErrRelationExists attendue, obtenu %v", err)
+	}
+	if err := repo.AddRelation(ctx, "voiture", "fr", interfaces.RelationSynonym, "absent"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue pour une cible absente, obtenu %v", err)
+	}
+	if err := repo.AddRelation(ctx, "voiture", "fr", "cousin", "automobile"); !errors.Is(err, interfaces.ErrInvalidRelation) {
+		t.Errorf("ErrInvalidRelation attendue, obtenu %v", err)
+	}
+
+	relations, err := repo.ListRelations(ctx, "voiture", "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interfaces.Relation{
+		{Type: interfaces.RelationSynonym, Target: "automobile"},
+		{Type: interfaces.RelationHypernym, Target: "véhicule"},
+		{Type: interfaces.RelationAntonym, Target: "piéton"},
+	}
+	if fmt.Sprint(relations) != fmt.Sprint(want) {
+		t.Errorf("relations de voiture : %v, attendu %v", relations, want)
+	}
+
+	edges, err := repo.ListRelationEdges(ctx, interfaces.DefaultLang, []string{"VEHICULE"})
+	if err != nil || len(edges) != 1 || edges[0].Word.Word != "voiture" || edges[0].Type != interfaces.RelationHypernym || edges[0].Target.Definition != "moyen de transport" {
+		t.Errorf("ListRelationEdges(véhicule) : %+v, %v", edges, err)
+	}
+	if edges, err := repo.ListRelationEdges(ctx, interfaces.DefaultLang, nil); err != nil || len(edges) != 3 {
+		t.Errorf("ListRelationEdges de toute la langue : %+v, %v", edges, err)
+	}
+	if edges, err := repo.ListRelationEdges(ctx, "en", nil); err != nil || len(edges) != 0 {
+		t.Errorf("ListRelationEdges(en) : %+v, %v", edges, err)
+	}
+
+	// L'hyperonyme se lit comme un hyponyme depuis le mot plus général.
+	relations, err = repo.ListRelations(ctx, "véhicule", "fr")
+	if err != nil || len(relations) != 1 || relations[0] != (interfaces.Relation{Type: interfaces.RelationHyponym, Target: "voiture"}) {
+		t.Errorf("relations de véhicule : %v, %v", relations, err)
+	}
+
+	if err := repo.RemoveRelation(ctx, "véhicule", "fr", interfaces.RelationHyponym, "voiture"); err != nil {
+		t.Errorf("RemoveRelation par l'hyponyme : %v", err)
+	}
+	if err := repo.RemoveRelation(ctx, "véhicule", "fr", interfaces.RelationHyponym, "voiture"); !errors.Is(err, interfaces.ErrRelationNotFound) {
+		t.Errorf("ErrRelationNotFound attendue, obtenu %v", err)
+	}
+
+	// Supprimer un mot supprime ses relations.
+	if err := repo.DeleteWordFromDB(ctx, "voiture", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range []string{"automobile", "piéton"} {
+		if relations, err := repo.ListRelations(ctx, word, "fr"); err != nil || len(relations) != 0 {
+			t.Errorf("relations de %s après suppression de voiture : %v, %v", word, relations, err)
+		}
+	}
+	mustAdd(t, repo, "voiture", "nouvelle définition")
+	if relations, err := repo.ListRelations(ctx, "voiture", "fr"); err != nil || len(relations) != 0 {
+		t.Errorf("un mot recréé ne doit pas retrouver ses anciennes relations : %v, %v", relations, err)
+	}
+	if _, err := repo.ListRelations(ctx, "absent", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue, obtenu %v", err)
+	}
+}
+
+func testTags(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "pomme", "fruit du pommier")
+	mustAdd(t, repo, "chien", "animal domestique")
+	mustAdd(t, repo, "poire", "fruit du poirier")
+
+	for _, tag := range []struct{ word, tag string }{
+		{"pomme", "Fruit"},
+		{"pomme", " automne "},
+		{"pomme", "fruit"}, // étiqueter deux fois ne fait rien
+		{"chien", "animal"},
+		{"poire", "fruit"},
+	} {
+		if err := repo.TagWord(ctx, tag.word, "fr", tag.tag); err != nil {
+			t.Fatalf("TagWord(%v) : %v", tag, err)
+		}
+	}
+	if err := repo.TagWord(ctx, "absent", "fr", "fruit"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.TagWord(ctx, "pomme", "fr", "a/b"); !errors.Is(err, interfaces.ErrInvalidTag) {
+		t.Errorf("ErrInvalidTag attendue, obtenu %v", err)
+	}
+
+	if tags, err := repo.WordTags(ctx, "pomme", "fr"); err != nil || fmt.Sprint(tags) != "[automne fruit]" {
+		t.Errorf("étiquettes de pomme : %v, %v", tags, err)
+	}
+	counts, err := repo.ListTags(ctx)
+	if err != nil || fmt.Sprint(counts) != "[{animal 1} {automne 1} {fruit 2}]" {
+		t.Errorf("ListTags : %v, %v", counts, err)
+	}
+
+	words, err := repo.ListWordsByTags(ctx, []string{"fruit"})
+	if err != nil || fmt.Sprint(headwords(words)) != "[pomme poire]" {
+		t.Errorf("mots étiquetés fruit : %v, %v", headwords(words), err)
+	}
+	words, err = repo.ListWordsByTags(ctx, []string{"fruit", "AUTOMNE"})
+	if err != nil || fmt.Sprint(headwords(words)) != "[pomme]" {
+		t.Errorf("mots étiquetés fruit et automne : %v, %v", headwords(words), err)
+	}
+
+	if err := repo.UntagWord(ctx, "pomme", "fr", "automne"); err != nil {
+		t.Errorf("UntagWord : %v", err)
+	}
+	if err := repo.UntagWord(ctx, "pomme", "fr", "automne"); !errors.Is(err, interfaces.ErrTagNotFound) {
+		t.Errorf("ErrTagNotFound attendue, obtenu %v", err)
+	}
+
+	// Supprimer un mot supprime ses étiquettes.
+	if err := repo.DeleteWordFromDB(ctx, "pomme", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, repo, "pomme", "nouvelle définition")
+	if tags, err := repo.WordTags(ctx, "pomme", "fr"); err != nil || len(tags) != 0 {
+		t.Errorf("un mot recréé ne doit pas retrouver ses étiquettes : %v, %v", tags, err)
+	}
+	if _, err := repo.WordTags(ctx, "absent", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue, obtenu %v", err)
+	}
+}
+
+func testCollections(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "un", "1")
+	mustAdd(t, repo, "deux", "2")
+	mustAdd(t, repo, "trois", "3")
+
+	chiffres := interfaces.Collection{Name: "chiffres", Description: "à réviser", Words: []string{"trois", "un", "deux"}}
+	if err := repo.CreateCollection(ctx, chiffres, "fr"); err != nil {
+		t.Fatalf("CreateCollection : %v", err)
+	}
+	if err := repo.CreateCollection(ctx, chiffres, "fr"); !errors.Is(err, interfaces.ErrCollectionExists) {
+		t.Errorf("ErrCollectionExists attendue, obtenu %v", err)
+	}
+	if err := repo.CreateCollection(ctx, interfaces.Collection{Name: "x", Words: []string{"absent"}}, "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.CreateCollection(ctx, interfaces.Collection{Name: "x", Words: []string{"un", "un"}}, "fr"); !errors.Is(err, interfaces.ErrInvalidCollection) {
+		t.Errorf("ErrInvalidCollection attendue, obtenu %v", err)
+	}
+
+	// L'ordre des mots est celui de la collection, pas celui d'insertion.
+	got, err := repo.GetCollection(ctx, "chiffres")
+	if err != nil || fmt.Sprint(got) != fmt.Sprint(chiffres) {
+		t.Errorf("GetCollection : %+v, %v", got, err)
+	}
+
+	chiffres.Words = []string{"un", "deux"}
+	chiffres.Description = ""
+	if err := repo.UpdateCollection(ctx, chiffres, "fr"); err != nil {
+		t.Fatalf("UpdateCollection : %v", err)
+	}
+	if err := repo.UpdateCollection(ctx, interfaces.Collection{Name: "absente"}, "fr"); !errors.Is(err, interfaces.ErrCollectionNotFound) {
+		t.Errorf("ErrCollectionNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.CreateCollection(ctx, interfaces.Collection{Name: "vide"}, "fr"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Supprimer un mot le retire des collections.
+	if err := repo.DeleteWordFromDB(ctx, "un", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	collections, err := repo.ListCollections(ctx)
+	if err != nil || fmt.Sprint(collections) != "[{chiffres  [deux]} {vide  []}]" {
+		t.Errorf("ListCollections : %v, %v", collections, err)
+	}
+
+	if err := repo.DeleteCollection(ctx, "chiffres"); err != nil {
+		t.Errorf("DeleteCollection : %v", err)
+	}
+	if _, err := repo.GetCollection(ctx, "chiffres"); !errors.Is(err, interfaces.ErrCollectionNotFound) {
+		t.Errorf("ErrCollectionNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.DeleteCollection(ctx, "chiffres"); !errors.Is(err, interfaces.ErrCollectionNotFound) {
+		t.Errorf("ErrCollectionNotFound attendue, obtenu %v", err)
+	}
+}
+
+func testLanguages(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "chat", "petit félin domestique")
+	mustAdd(t, repo, "matou", "chat mâle")
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "chat", Lang: "en", Definition: "informal conversation"}); err != nil {
+		t.Fatalf("un même mot doit pouvoir exister dans une autre langue : %v", err)
+	}
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "cat", Lang: "en", Definition: "small domestic feline"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "chien", Definition: "sans langue"}); !errors.Is(err, interfaces.ErrInvalidLang) {
+		t.Errorf("une entrée sans langue doit être refusée : %v", err)
+	}
+	if _, err := repo.GetWordFromDB(ctx, "chat", ""); !errors.Is(err, interfaces.ErrInvalidLang) {
+		t.Errorf("GetWordFromDB sans langue : ErrInvalidLang attendue, obtenu %v", err)
+	}
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "cat", Lang: "en", Definition: "doublon"}); !errors.Is(err, interfaces.ErrWordExists) {
+		t.Errorf("ErrWordExists attendue dans la même langue, obtenu %v", err)
+	}
+
+	if word, err := repo.GetWordFromDB(ctx, "chat", "en"); err != nil || word.Definition != "informal conversation" || word.Lang != "en" {
+		t.Errorf("GetWordFromDB en anglais : %+v, %v", word, err)
+	}
+	if word, err := repo.GetWordFromDB(ctx, "chat", "fr"); err != nil || word.Lang != interfaces.DefaultLang {
+		t.Errorf("GetWordFromDB sans langue : %+v, %v", word, err)
+	}
+	if _, err := repo.GetWordFromDB(ctx, "cat", "fr"); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("cat n'existe qu'en anglais, obtenu %v", err)
+	}
+	words, err := repo.ListWordsFromDB(ctx)
+	if err != nil || fmt.Sprint(words) != "[{chat fr petit félin domestique } {matou fr chat mâle } {chat en informal conversation } {cat en small domestic feline }]" {
+		t.Errorf("ListWordsFromDB : %v, %v", words, err)
+	}
+	if words, err := repo.ListWordsByKey(ctx, "CHAT"); err != nil || fmt.Sprint(words) != "[{chat fr petit félin domestique } {chat en informal conversation }]" {
+		t.Errorf("ListWordsByKey(CHAT) : %v, %v", words, err)
+	}
+	if words, err := repo.ListWordsByKey(ctx, "chien"); err != nil || len(words) != 0 {
+		t.Errorf("ListWordsByKey(chien) : %v, %v", words, err)
+	}
+
+	// Les relations et étiquettes appartiennent à l'entrée d'une langue.
+	if err := repo.AddRelation(ctx, "matou", "fr", interfaces.RelationHypernym, "chat"); err != nil {
+		t.Fatal(err)
+	}
+	if relations, err := repo.ListRelations(ctx, "chat", "en"); err != nil || len(relations) != 0 {
+		t.Errorf("relations de chat en anglais : %v, %v", relations, err)
+	}
+	if err := repo.TagWord(ctx, "chat", "en", "informel"); err != nil {
+		t.Fatal(err)
+	}
+	if tags, err := repo.WordTags(ctx, "chat", "fr"); err != nil || len(tags) != 0 {
+		t.Errorf("étiquettes de chat en français : %v, %v", tags, err)
+	}
+
+	chat := interfaces.EntryRef{Word: "chat", Lang: "fr"}
+	cat := interfaces.EntryRef{Word: "cat", Lang: "EN"}
+	if err := repo.AddTranslation(ctx, chat, cat); err != nil {
+		t.Fatalf("AddTranslation : %v", err)
+	}
+	if err := repo.AddTranslation(ctx, cat, chat); !errors.Is(err, interfaces.ErrTranslationExists) {
+		t.Errorf("une traduction vaut dans les deux sens, ErrTranslationExists attendue, obtenu %v", err)
+	}
+	if err := repo.AddTranslation(ctx, chat, interfaces.EntryRef{Word: "matou", Lang: "fr"}); !errors.Is(err, interfaces.ErrInvalidTranslation) {
+		t.Errorf("ErrInvalidTranslation attendue entre deux entrées françaises, obtenu %v", err)
+	}
+	if err := repo.AddTranslation(ctx, chat, interfaces.EntryRef{Word: "dog", Lang: "en"}); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue, obtenu %v", err)
+	}
+	if err := repo.AddTranslation(ctx, chat, interfaces.EntryRef{Word: "cat", Lang: "english"}); !errors.Is(err, interfaces.ErrInvalidLang) {
+		t.Errorf("ErrInvalidLang attendue, obtenu %v", err)
+	}
+
+	translations, err := repo.ListTranslations(ctx, interfaces.EntryRef{Word: "cat", Lang: "en"})
+	if err != nil || fmt.Sprint(translations) != "[{chat fr}]" {
+		t.Errorf("traductions de cat : %v, %v", translations, err)
+	}
+	if translations, err := repo.ListTranslations(ctx, interfaces.EntryRef{Word: "chat", Lang: "en"}); err != nil || len(translations) != 0 {
+		t.Errorf("traductions de chat en anglais : %v, %v", translations, err)
+	}
+
+	// Supprimer une entrée ne touche pas au même mot dans une autre langue.
+	if err := repo.DeleteWordFromDB(ctx, "chat", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetWordFromDB(ctx, "chat", "fr"); err != nil {
+		t.Errorf("chat en français doit rester : %v", err)
+	}
+	if err := repo.DeleteWordFromDB(ctx, "chat", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	if translations, err := repo.ListTranslations(ctx, interfaces.EntryRef{Word: "cat", Lang: "en"}); err != nil || len(translations) != 0 {
+		t.Errorf("les traductions disparaissent avec l'entrée : %v, %v", translations, err)
+	}
+	if err := repo.RemoveTranslation(ctx, chat, cat); !errors.Is(err, interfaces.ErrWordNotFound) {
+		t.Errorf("ErrWordNotFound attendue, obtenu %v", err)
+	}
+}
+
+func testSoundsLike(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "photographie", "image obtenue par la lumière")
+	mustAdd(t, repo, "maison", "lieu d'habitation")
+	for _, word := range []string{"knight", "Smith", "night"} {
+		if err := repo.AddWordToDB(ctx, interfaces.Word{Word: word, Lang: "en", Definition: "définition"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		query, lang string
+		want        []string
+	}{
+		{"fotografie", "fr", []string{"photographie"}},
+		{"mézon", "", []string{"maison"}},
+		{"nite", "en", []string{"knight", "night"}},
+		{"Schmidt", "en", []string{"Smith"}}, // par le code secondaire de Double Metaphone
+		{"fotografie", "en", nil},
+	} {
+		words, err := repo.ListWordsBySound(ctx, tc.query, tc.lang)
+		if err != nil || fmt.Sprint(headwords(words)) != fmt.Sprint(tc.want) {
+			t.Errorf("ListWordsBySound(%q, %q) : %v, %v ; attendu %v", tc.query, tc.lang, headwords(words), err, tc.want)
+		}
+	}
+
+	// Les clés suivent les modifications et suppressions.
+	if err := repo.UpdateWordInDB(ctx, "night", "en", "nuit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteWordFromDB(ctx, "knight", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if words, err := repo.ListWordsBySound(ctx, "nite", ""); err != nil || len(words) != 1 || words[0].Definition != "nuit" {
+		t.Errorf("ListWordsBySound après modification : %v, %v", words, err)
+	}
+}
+
+func testSearch(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, "framework", "ensemble de bibliothèques")
+	mustAdd(t, repo, "langage", "système de signes")
+	mustAdd(t, repo, "go", "langage compilé")
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "language", Lang: "en", Definition: "a system of signs"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.TagWord(ctx, "go", "fr", "informatique"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := func(hits []interfaces.SearchHit) []string {
+		var result []string
+		for _, hit := range hits {
+			result = append(result, hit.Word+"/"+hit.Lang)
+		}
+		return result
+	}
+	for _, tc := range []struct {
+		query, lang, tag string
+		limit            int
+		want             []string
+	}{
+		{"frameworks", "fr", "", 0, []string{"framework/fr"}},
+		{"Langages", "", "", 0, []string{"langage/fr", "go/fr"}}, // le mot avant les définitions qui le citent
+		{"languages", "", "", 0, []string{"language/en"}},        // chaque langue a son raciniseur
+		{"langage", "", "", 1, []string{"langage/fr"}},
+		{"langage", "fr", "Informatique", 0, []string{"go/fr"}},
+		{"langage", "fr", "absente", 0, nil},
+	} {
+		hits, err := repo.SearchWords(ctx, tc.query, tc.lang, tc.tag, tc.limit)
+		if err != nil || fmt.Sprint(entries(hits)) != fmt.Sprint(tc.want) {
+			t.Errorf("SearchWords(%q, %q, %q, %d) : %v, %v ; attendu %v", tc.query, tc.lang, tc.tag, tc.limit, entries(hits), err, tc.want)
+		}
+	}
+	if hits, err := repo.SearchWords(ctx, "Langage", "fr", "", 0); err != nil || len(hits) != 2 ||
+		hits[0].Score != search.ExactScore+search.HeadwordScore || hits[1].Score != search.DefinitionScore {
+		t.Errorf("scores de SearchWords(Langage) : %+v, %v", hits, err)
+	}
+	if _, err := repo.SearchWords(ctx, "langage", "", "a/b", 0); !errors.Is(err, interfaces.ErrInvalidTag) {
+		t.Errorf("ErrInvalidTag attendue, obtenu %v", err)
+	}
+
+	for _, tc := range []struct {
+		query, lang string
+		want        []interfaces.EntryRef
+	}{
+		{"langages", "fr", []interfaces.EntryRef{{Word: "langage", Lang: "fr"}}},
+		{"lang", "", []interfaces.EntryRef{{Word: "langage", Lang: "fr"}, {Word: "language", Lang: "en"}}},
+		{"fram", "en", []interfaces.EntryRef{}},
+	} {
+		suggestions, err := repo.SuggestWords(ctx, tc.query, tc.lang, 0)
+		if err != nil || fmt.Sprint(suggestions) != fmt.Sprint(tc.want) {
+			t.Errorf("SuggestWords(%q, %q) : %v, %v ; attendu %v", tc.query, tc.lang, suggestions, err, tc.want)
+		}
+	}
+
+	// Les termes suivent les modifications et les suppressions.
+	if err := repo.UpdateWordInDB(ctx, "go", "fr", "langue des gophers"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteWordFromDB(ctx, "framework", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, repo, "frameworks", "pluriel conservé")
+	if hits, err := repo.SearchWords(ctx, "framework", "fr", "", 0); err != nil || fmt.Sprint(entries(hits)) != "[frameworks/fr]" {
+		t.Errorf("SearchWords après modification : %v, %v", entries(hits), err)
+	}
+	if hits, err := repo.SearchWords(ctx, "compilé", "fr", "", 0); err != nil || len(hits) != 0 {
+		t.Errorf("l'ancienne définition ne doit plus être trouvée : %v, %v", entries(hits), err)
+	}
+}
+
+func testWordGames(t *testing.T, repo interfaces.WordRepository) {
+	ctx := context.Background()
+	for _, word := range []string{"niche", "chien", "symphonie", "Chine", "arc-en-ciel", "sympa", "chêne"} {
+		mustAdd(t, repo, word, "définition")
+	}
+	if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "inch", Lang: "en", Definition: "pouce"}); err != nil {
+		t.Fatal(err)
+	}
+
+	type query func(ctx context.Context, q, lang string, limit int) ([]interfaces.Word, error)
+	for _, tc := range []struct {
+		name  string
+		find  query
+		q     string
+		lang  string
+		limit int
+		want  []string
+	}{
+		{"MatchWords", repo.MatchWords, "s?mph*", "", 0, []string{"symphonie"}},
+		{"MatchWords", repo.MatchWords, "sym*", "fr", 1, []string{"symphonie"}},
+		{"MatchWords", repo.MatchWords, "ch?ne", "", 0, []string{"Chine", "chêne"}}, // sans accents
+		{"MatchWords", repo.MatchWords, "arc en *", "", 0, []string{"arc-en-ciel"}},
+		{"MatchWords", repo.MatchWords, "?????", "fr", 0, []string{"niche", "chien", "Chine", "sympa", "chêne"}},
+		{"MatchWords", repo.MatchWords, "?????*", "", 0, []string{"niche", "chien", "symphonie", "Chine", "arc-en-ciel", "sympa", "chêne"}},
+		{"MatchWords", repo.MatchWords, "*e*e*", "", 0, []string{"arc-en-ciel", "chêne"}},
+		{"ListAnagrams", repo.ListAnagrams, "Chien", "fr", 0, []string{"niche", "chien", "Chine"}},
+		{"ListAnagrams", repo.ListAnagrams, "ichen", "", 2, []string{"niche", "chien"}},
+		{"ListAnagrams", repo.ListAnagrams, "nich", "", 0, []string{"inch"}},
+		{"ListWordsContaining", repo.ListWordsContaining, "hc", "", 0, []string{"niche", "chien", "Chine", "chêne", "inch"}},
+		{"ListWordsContaining", repo.ListWordsContaining, "ee", "", 0, []string{"arc-en-ciel", "chêne"}},
+		{"ListWordsContaining", repo.ListWordsContaining, "yp", "fr", 0, []string{"symphonie", "sympa"}},
+		{"ListWordsContaining", repo.ListWordsContaining, "zz", "", 0, nil},
+	} {
+		words, err := tc.find(ctx, tc.q, tc.lang, tc.limit)
+		if err != nil || fmt.Sprint(headwords(words)) != fmt.Sprint(tc.want) {
+			t.Errorf("%s(%q, %q, %d) : %v, %v ; attendu %v", tc.name, tc.q, tc.lang, tc.limit, headwords(words), err, tc.want)
+		}
+	}
+
+	for _, pattern := range []string{"", " - ", "a[b]", "a1"} {
+		if _, err := repo.MatchWords(ctx, pattern, "", 0); !errors.Is(err, interfaces.ErrInvalidPattern) {
+			t.Errorf("MatchWords(%q) : %v, attendu ErrInvalidPattern", pattern, err)
+		}
+	}
+	if _, err := repo.ListAnagrams(ctx, "-'", "", 0); !errors.Is(err, interfaces.ErrInvalidPattern) {
+		t.Errorf("ListAnagrams sans lettres : %v, attendu ErrInvalidPattern", err)
+	}
+
+	// Les lettres suivent les suppressions.
+	if err := repo.DeleteWordFromDB(ctx, "chien", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	if words, err := repo.ListAnagrams(ctx, "chien", "fr", 0); err != nil || fmt.Sprint(headwords(words)) != "[niche Chine]" {
+		t.Errorf("ListAnagrams après suppression : %v, %v", headwords(words), err)
+	}
+	if words, err := repo.ListWordsContaining(ctx, "ch", "", 0); err != nil || fmt.Sprint(headwords(words)) != "[niche Chine chêne inch]" {
+		t.Errorf("ListWordsContaining après suppression : %v, %v", headwords(words), err)
+	}
+}
@@ -0,0 +1,51 @@
+package requestctx
+
+import "context"
+
+type contextKey struct{}
+
+// Info regroupe les informations d'une requête propagées dans le contexte
+// jusqu'au dictionnaire et au dépôt. Le pointeur est partagé afin que
+// l'authentification puisse renseigner l'utilisateur après coup.
+type Info struct {
+	RequestID string
+	User      string
+	ClientIP  string
+}
+
+// With attache les informations de requête au contexte.
+func With(ctx context.Context, info *Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// From renvoie les informations de requête du contexte, ou nil.
+func From(ctx context.Context) *Info {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(contextKey{}).(*Info)
+	return info
+}
+
+// RequestID renvoie l'identifiant de corrélation de la requête, ou une chaîne vide.
+func RequestID(ctx context.Context) string {
+	if info := From(ctx); info != nil {
+		return info.RequestID
+	}
+	return ""
+}
+
+// User renvoie l'utilisateur authentifié de la requête, ou une chaîne vide.
+func User(ctx context.Context) string {
+	if info := From(ctx); info != nil {
+		return info.User
+	}
+	return ""
+}
+
+// SetUser renseigne l'utilisateur authentifié de la requête en cours.
+func SetUser(ctx context.Context, user string) {
+	if info := From(ctx); info != nil {
+		info.User = user
+	}
+}
@@ -0,0 +1,357 @@
+// Package search décrit la recherche plein texte des dépôts : les mots et les
+// définitions sont rangés par terme (voir analysis.Term), si bien qu'une
+// recherche sur une forme fléchie trouve les entrées du même lemme. Index est
+// l'index inversé des dépôts en mémoire ; la base SQLite range les mêmes
+// termes (voir Postings) dans une table.
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"tp2/analysis"
+	"tp2/interfaces"
+)
+
+// Poids d'une correspondance dans le score d'un résultat.
+const (
+	ExactScore      = 10 // Le mot cherché est l'entrée, aux accents et à la casse près
+	HeadwordScore   = 3  // Un terme de la requête est un terme du mot
+	DefinitionScore = 1  // Un terme de la requête est un terme de la définition
+)
+
+// Champs d'une entrée dont les termes sont indexés.
+const (
+	FieldWord       = "word"
+	FieldDefinition = "definition"
+)
+
+// Posting est un terme du champ Field d'une entrée.
+type Posting struct {
+	Term  string
+	Field string
+}
+
+// Index est sûr pour un usage concurrent. La valeur zéro n'est pas utilisable : voir New.
+type Index struct {
+	mu          sync.RWMutex
+	entries     map[string]interfaces.Word     // Par entryKey
+	headwords   map[string]map[string]struct{} // Terme d'un mot → entryKey
+	definitions map[string]map[string]struct{} // Terme d'une définition → entryKey
+	wordKeys    map[string][]string            // Par langue, clés triées des mots (voir interfaces.WordKey)
+}
+
+// New renvoie un index contenant words. Pour un même mot dans la même
+// langue, la dernière entrée l'emporte, comme avec Put.
+func New(words []interfaces.Word) *Index {
+	idx := &Index{
+		entries:     make(map[string]interfaces.Word),
+		headwords:   make(map[string]map[string]struct{}),
+		definitions: make(map[string]map[string]struct{}),
+		wordKeys:    make(map[string][]string),
+	}
+	latest := make(map[string]interfaces.Word, len(words))
+	for _, w := range words {
+		w.Lang = langOf(w)
+		latest[entryKey(w.Word, w.Lang)] = w
+	}
+	// Les clés sont triées une fois pour toutes plutôt qu'insérées une à une.
+	for _, w := range latest {
+		idx.index(w)
+		idx.wordKeys[w.Lang] = append(idx.wordKeys[w.Lang], interfaces.WordKey(w.Word))
+	}
+	for _, keys := range idx.wordKeys {
+		sort.Strings(keys)
+	}
+	return idx
+}
+
+func entryKey(word, lang string) string {
+	return lang + "\x00" + interfaces.WordKey(word)
+}
+
+func postingKey(lang, term string) string {
+	return lang + "\x00" + term
+}
+
+func langOf(w interfaces.Word) string {
+	if w.Lang == "" {
+		return interfaces.DefaultLang
+	}
+	return w.Lang
+}
+
+// HeadwordTerms renvoie les termes d'un mot, mots vides compris : « de » est
+// une entrée comme une autre.
+func HeadwordTerms(lang, word string) []string {
+	var terms []string
+	for _, token := range analysis.Tokens(word) {
+		terms = append(terms, analysis.Term(lang, token))
+	}
+	return terms
+}
+
+// QueryTerms renvoie sans doublons les termes d'une requête, sans ses mots
+// vides sauf s'il n'y a qu'eux.
+func QueryTerms(lang, query string) []string {
+	terms := analysis.Terms(lang, query)
+	if len(terms) == 0 {
+		terms = HeadwordTerms(lang, query)
+	}
+	return distinct(terms)
+}
+
+// Postings renvoie sans doublons les termes du mot et de la définition de w,
+// analysés dans sa langue.
+func Postings(w interfaces.Word) []Posting {
+	lang := langOf(w)
+	var postings []Posting
+	for _, term := range distinct(HeadwordTerms(lang, w.Word)) {
+		postings = append(postings, Posting{Term: term, Field: FieldWord})
+	}
+	for _, term := range distinct(analysis.Terms(lang, w.Definition)) {
+		postings = append(postings, Posting{Term: term, Field: FieldDefinition})
+	}
+	return postings
+}
+
+func distinct(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := terms[:0:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// Put ajoute l'entrée ou remplace celle du même mot dans la même langue.
+func (idx *Index) Put(w interfaces.Word) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.put(w)
+}
+
+func (idx *Index) put(w interfaces.Word) {
+	w.Lang = langOf(w)
+	idx.remove(w.Word, w.Lang)
+	idx.index(w)
+
+	keys, wordKey := idx.wordKeys[w.Lang], interfaces.WordKey(w.Word)
+	i := sort.SearchStrings(keys, wordKey)
+	keys = append(keys, "")
+	copy(keys[i+1:], keys[i:])
+	keys[i] = wordKey
+	idx.wordKeys[w.Lang] = keys
+}
+
+// index range l'entrée et ses termes, sans toucher aux clés triées.
+func (idx *Index) index(w interfaces.Word) {
+	key := entryKey(w.Word, w.Lang)
+	idx.entries[key] = w
+	for _, p := range Postings(w) {
+		addPosting(idx.postings(p.Field), postingKey(w.Lang, p.Term), key)
+	}
+}
+
+// Delete retire l'entrée du mot dans la langue lang, si elle est indexée.
+func (idx *Index) Delete(word, lang string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if lang == "" {
+		lang = interfaces.DefaultLang
+	}
+	idx.remove(word, lang)
+}
+
+func (idx *Index) remove(word, lang string) {
+	key := entryKey(word, lang)
+	old, ok := idx.entries[key]
+	if !ok {
+		return
+	}
+	delete(idx.entries, key)
+	keys, wordKey := idx.wordKeys[lang], interfaces.WordKey(old.Word)
+	if i := sort.SearchStrings(keys, wordKey); i < len(keys) && keys[i] == wordKey {
+		keys = append(keys[:i], keys[i+1:]...)
+	}
+	if len(keys) == 0 {
+		delete(idx.wordKeys, lang)
+	} else {
+		idx.wordKeys[lang] = keys
+	}
+	for _, p := range Postings(old) {
+		removePosting(idx.postings(p.Field), postingKey(lang, p.Term), key)
+	}
+}
+
+// postings renvoie les listes de termes du champ field.
+func (idx *Index) postings(field string) map[string]map[string]struct{} {
+	if field == FieldWord {
+		return idx.headwords
+	}
+	return idx.definitions
+}
+
+func addPosting(postings map[string]map[string]struct{}, term, key string) {
+	if postings[term] == nil {
+		postings[term] = make(map[string]struct{})
+	}
+	postings[term][key] = struct{}{}
+}
+
+func removePosting(postings map[string]map[string]struct{}, term, key string) {
+	delete(postings[term], key)
+	if len(postings[term]) == 0 {
+		delete(postings, term)
+	}
+}
+
+func (idx *Index) has(key string) bool {
+	_, ok := idx.entries[key]
+	return ok
+}
+
+// searchLangs renvoie lang, ou toutes les langues indexées s'il est vide.
+func (idx *Index) searchLangs(lang string) []string {
+	if lang != "" {
+		return []string{lang}
+	}
+	langs := make([]string, 0, len(idx.wordKeys))
+	for l := range idx.wordKeys {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Search renvoie au plus limit entrées (toutes si limit <= 0) dont le mot ou
+// la définition contient un terme de query, de la plus à la moins pertinente.
+// Sans lang, la requête est analysée dans chaque langue indexée. Si keep
+// n'est pas nil, seules les entrées qu'elle accepte sont retenues.
+func (idx *Index) Search(lang, query string, limit int, keep func(w interfaces.Word) bool) []interfaces.SearchHit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[string]int)
+	for _, l := range idx.searchLangs(lang) {
+		if key := entryKey(query, l); idx.has(key) {
+			scores[key] += ExactScore
+		}
+		for _, term := range QueryTerms(l, query) {
+			for key := range idx.headwords[postingKey(l, term)] {
+				scores[key] += HeadwordScore
+			}
+			for key := range idx.definitions[postingKey(l, term)] {
+				scores[key] += DefinitionScore
+			}
+		}
+	}
+
+	hits := make([]interfaces.SearchHit, 0, len(scores))
+	for key, score := range scores {
+		w := idx.entries[key]
+		if keep == nil || keep(w) {
+			hits = append(hits, interfaces.SearchHit{Word: w.Word, Lang: w.Lang, Definition: w.Definition, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return entryKey(hits[i].Word, hits[i].Lang) < entryKey(hits[j].Word, hits[j].Lang)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// Suggest renvoie au plus limit entrées proches de query : l'entrée elle-même,
+// puis celles dont le mot a les mêmes termes (une autre forme du même lemme),
+// puis celles dont le mot commence par query, des plus courtes aux plus longues.
+func (idx *Index) Suggest(lang, query string, limit int) []interfaces.EntryRef {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var suggestions []interfaces.EntryRef
+	seen := make(map[string]bool)
+	add := func(keys ...string) {
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				w := idx.entries[key]
+				suggestions = append(suggestions, interfaces.EntryRef{Word: w.Word, Lang: w.Lang})
+			}
+		}
+	}
+
+	prefix := interfaces.WordKey(query)
+	langs := idx.searchLangs(lang)
+	for _, l := range langs {
+		if key := entryKey(query, l); idx.has(key) {
+			add(key)
+		}
+	}
+	for _, l := range langs {
+		add(idx.sameTerms(l, query)...)
+	}
+
+	// Les clés triées d'une langue qui commencent par prefix se suivent.
+	var prefixed []string
+	if prefix != "" {
+		for _, l := range langs {
+			keys := idx.wordKeys[l]
+			for i := sort.SearchStrings(keys, prefix); i < len(keys) && strings.HasPrefix(keys[i], prefix); i++ {
+				prefixed = append(prefixed, l+"\x00"+keys[i]) // entryKey du mot
+			}
+		}
+	}
+	sort.Slice(prefixed, func(i, j int) bool {
+		a, b := interfaces.WordLength(idx.entries[prefixed[i]].Word), interfaces.WordLength(idx.entries[prefixed[j]].Word)
+		if a != b {
+			return a < b
+		}
+		return prefixed[i] < prefixed[j]
+	})
+	add(prefixed...)
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	if suggestions == nil {
+		suggestions = []interfaces.EntryRef{}
+	}
+	return suggestions
+}
+
+// sameTerms renvoie, triées, les entrées de la langue lang dont le mot a
+// exactement les mêmes termes que query.
+func (idx *Index) sameTerms(lang, query string) []string {
+	terms := HeadwordTerms(lang, query)
+	if len(terms) == 0 {
+		return nil
+	}
+	var keys []string
+	for key := range idx.headwords[postingKey(lang, terms[0])] {
+		if equalTerms(HeadwordTerms(lang, idx.entries[key].Word), terms) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func equalTerms(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,18 +2,37 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 	"tp2/api_mode"
+	"tp2/config"
 	"tp2/db"
 	"tp2/dictionary"
+	"tp2/interfaces"
+	"tp2/metrics"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// apiServer sert les handlers de l'API avec la configuration des tests.
+var apiServer = api_mode.NewServer(testConfig())
+
+// testConfig complète la configuration par défaut, qui n'a aucun compte,
+// avec l'administrateur utilisé par les tests.
+func testConfig() config.Config {
+	cfg := config.Default()
+	cfg.Auth.Users = map[string]string{"nabil": "10"}
+	cfg.Auth.AdminUsers = []string{"nabil"}
+	return cfg
+}
+
 func TestWelcomeHandler(t *testing.T) {
 	req, err := http.NewRequest("GET", "/", nil)
 	assert.NoError(t, err)
@@ -38,7 +57,7 @@ func loginAndGetToken(t *testing.T) string {
 
 	rr := httptest.NewRecorder()
 
-	handler := http.HandlerFunc(api_mode.LoginHandler)
+	handler := http.HandlerFunc(apiServer.LoginHandler)
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
@@ -47,7 +66,7 @@ func loginAndGetToken(t *testing.T) string {
 	fmt.Println("Token:", token)
 	assert.NotEmpty(t, token, "Token not found in the response")
 
-	isValid := api_mode.IsValidToken(token)
+	isValid := apiServer.IsValidToken(token)
 	assert.True(t, isValid, "Le jeton généré doit être valide.")
 
 	return token
@@ -65,11 +84,395 @@ func TestAddHandler(t *testing.T) {
 	addWordReq.Header.Set("Authorization", token)
 
 	addWordRR := httptest.NewRecorder()
-	wordRepository := &db.GormWordRepository{}
+	wordRepository := &db.MemoryWordRepository{}
 	myDictionary := dictionary.New("dictionary.csv", wordRepository)
-	addWordHandler := http.HandlerFunc(api_mode.ApiAddWordHandler(myDictionary))
+	addWordHandler := http.HandlerFunc(apiServer.ApiAddWordHandler(myDictionary))
 	addWordHandler.ServeHTTP(addWordRR, addWordReq)
 
 	assert.Equal(t, http.StatusCreated, addWordRR.Code)
 	assert.Contains(t, addWordRR.Body.String(), fmt.Sprintf("Le mot '%s' avec la définition '%s' a été ajouté.", word.Word, word.Definition))
+
+	// La longueur est comptée en caractères : 25 lettres accentuées font 50 octets.
+	accented := strings.Repeat("é", 25)
+	addWordReq, err = http.NewRequest("POST", "/api/words/add", bytes.NewBufferString(`{"word": "`+accented+`", "definition": "definition"}`))
+	assert.NoError(t, err)
+	addWordReq.Header.Set("Authorization", token)
+	addWordRR = httptest.NewRecorder()
+	addWordHandler.ServeHTTP(addWordRR, addWordReq)
+	assert.Equal(t, http.StatusCreated, addWordRR.Code)
+}
+
+func TestHealthHandlers(t *testing.T) {
+	wordRepository := &db.GormWordRepository{}
+	err := wordRepository.InitializeDB(":memory:")
+	assert.NoError(t, err)
+	defer wordRepository.CloseDB()
+	myDictionary := dictionary.New("dictionary.csv", wordRepository)
+
+	rr := httptest.NewRecorder()
+	api_mode.HealthzHandler(rr, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	api_mode.ReadyzHandler(myDictionary)(rr, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"database":"ok"`)
+
+	rr = httptest.NewRecorder()
+	api_mode.VersionHandler(rr, httptest.NewRequest("GET", "/version", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "go_version")
+}
+
+func TestMetricsHandler(t *testing.T) {
+	handler := api_mode.Instrument("/", api_mode.WelcomeHandler)
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `dico_http_requests_total{method="GET",route="/",status="200"}`)
+}
+
+func TestBatchHandler(t *testing.T) {
+	token := loginAndGetToken(t)
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	handler := apiServer.ApiBatchHandler(myDictionary)
+
+	send := func(body string) (int, map[string]interface{}) {
+		req, err := http.NewRequest("POST", "/api/words/batch", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return rr.Code, response
+	}
+
+	// Le doublon annule tout le lot atomique.
+	code, response := send(`{"atomic": true, "operations": [
+		{"op": "add", "word": "lot", "definition": "ensemble d'opérations"},
+		{"op": "add", "word": "lot", "definition": "ensemble d'opérations"}]}`)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, false, response["committed"])
+	results := response["results"].([]interface{})
+	assert.Equal(t, "rolled_back", results[0].(map[string]interface{})["status"])
+	assert.Equal(t, "error", results[1].(map[string]interface{})["status"])
+
+	words, err := myDictionary.List(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, words)
+
+	code, response = send(`{"operations": [
+		{"op": "add", "word": "lot", "definition": "ensemble d'opérations"},
+		{"op": "define", "word": "absent", "definition": "introuvable"}]}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, response["committed"])
+
+	words, err = myDictionary.List(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, words, 1)
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	token := loginAndGetToken(t)
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	// Un serveur propre au test a ses propres clés d'idempotence.
+	srv := api_mode.NewServer(testConfig())
+	handler := srv.Idempotent(srv.ApiAddWordHandler(myDictionary))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/api/words/add", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Idempotency-Key", key)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	body := `{"word": "rejeu", "definition": "requête renvoyée"}`
+	first := send("cle-1", body)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	// Le client réessaie : il reçoit la réponse d'origine au lieu d'un doublon.
+	retry := send("cle-1", body)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get("Idempotency-Replayed"))
+
+	mismatch := send("cle-1", `{"word": "autre", "definition": "autre requête"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+
+	words, err := myDictionary.List(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, words, 1)
+}
+
+// Avec un stockage SQLite, les clés survivent au redémarrage du serveur et
+// sont purgées une fois expirées.
+func TestIdempotencyKeysPersist(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.GormWordRepository{}
+	if err := repo.InitializeDB(filepath.Join(t.TempDir(), "idempotency.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer repo.CloseDB()
+	token := loginAndGetToken(t)
+	myDictionary := dictionary.New("dictionary.csv", repo)
+
+	newServer := func() http.HandlerFunc {
+		srv := api_mode.NewServer(testConfig())
+		srv.SetIdempotencyStore(repo)
+		return srv.Idempotent(srv.ApiAddWordHandler(myDictionary))
+	}
+	send := func(handler http.HandlerFunc, token, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/api/words/add", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Idempotency-Key", "cle-persistante")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	body := `{"word": "durable", "definition": "qui survit au redémarrage"}`
+	first := send(newServer(), token, body)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	retry := send(newServer(), token, body)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotency-Replayed"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	// Un refus d'authentification n'est pas enregistré sous la clé.
+	handler := newServer()
+	for i := 0; i < 2; i++ {
+		rr := send(handler, "jeton-invalide", body)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Empty(t, rr.Header().Get("Idempotency-Replayed"))
+	}
+
+	tooLarge := send(handler, token, `{"word": "gros", "definition": "`+strings.Repeat("x", 1<<20)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, tooLarge.Code)
+
+	purged, err := repo.PurgeIdempotencyKeys(ctx, time.Now().Add(config.Default().Server.IdempotencyWindow+time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+// Une réservation restée sans réponse (requête interrompue) est reprise par
+// une nouvelle tentative une fois son délai passé, sans attendre l'expiration.
+func TestIdempotencyReservationTakeover(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.GormWordRepository{}
+	if err := repo.InitializeDB(filepath.Join(t.TempDir(), "idempotency.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer repo.CloseDB()
+
+	now := time.Now()
+	reserve := func(at time.Time) (interfaces.IdempotentResponse, bool) {
+		response, found, err := repo.ReserveIdempotencyKey(ctx, "cle", "hash", at, at.Add(time.Minute), now.Add(24*time.Hour))
+		assert.NoError(t, err)
+		return response, found
+	}
+
+	_, found := reserve(now)
+	assert.False(t, found)
+	response, found := reserve(now.Add(30 * time.Second))
+	assert.True(t, found, "la première requête est encore en cours")
+	assert.Equal(t, 0, response.Status)
+
+	_, found = reserve(now.Add(2 * time.Minute))
+	assert.False(t, found, "la réservation abandonnée est reprise")
+	assert.NoError(t, repo.CompleteIdempotencyKey(ctx, "cle", interfaces.IdempotentResponse{RequestHash: "hash", Status: http.StatusCreated}))
+
+	// Une réponse enregistrée est rejouée jusqu'à l'expiration de la clé.
+	response, found = reserve(now.Add(time.Hour))
+	assert.True(t, found)
+	assert.Equal(t, http.StatusCreated, response.Status)
+}
+
+func TestWordRelationsHandler(t *testing.T) {
+	token := loginAndGetToken(t)
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	ctx := context.Background()
+	assert.NoError(t, myDictionary.AddAsync(ctx, "rapide", "fr", "qui va vite"))
+	assert.NoError(t, myDictionary.AddAsync(ctx, "lent", "fr", "qui va doucement"))
+	handler := apiServer.ApiWordHandler(myDictionary)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("POST", "/api/words/rapide/relations", `{"type": "antonym", "target": "lent"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr = send("POST", "/api/words/rapide/relations", `{"type": "antonym", "target": "lent"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	rr = send("GET", "/api/words/lent", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var detail dictionary.WordDetail
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &detail))
+	assert.Equal(t, "qui va doucement", detail.Definition)
+	assert.Equal(t, []interfaces.Relation{{Type: "antonym", Target: "rapide"}}, detail.Relations)
+
+	rr = send("DELETE", "/api/words/lent/relations/antonym/rapide", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = send("GET", "/api/words/absent", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestTagsAndCollectionsHandlers(t *testing.T) {
+	token := loginAndGetToken(t)
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	ctx := context.Background()
+	assert.NoError(t, myDictionary.AddAsync(ctx, "go", "fr", "langage compilé"))
+	assert.NoError(t, myDictionary.AddAsync(ctx, "gin", "fr", "framework web"))
+	assert.NoError(t, myDictionary.AddAsync(ctx, "python", "fr", "langage interprété"))
+
+	send := func(handler http.HandlerFunc, method, path, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	words := apiServer.ApiWordHandler(myDictionary)
+
+	for _, word := range []string{"go", "python"} {
+		rr := send(words, "POST", "/api/words/"+word+"/tags", `{"tag": "Langage"}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	rr := send(words, "POST", "/api/words/gin/tags", `{"tag": "framework"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = send(words, "POST", "/api/words/absent/tags", `{"tag": "framework"}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = send(apiServer.ApiListWordsHandler(myDictionary), "GET", "/api/words/list?tag=langage", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var listed []dictionary.Word
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+
+	rr = send(apiServer.ApiTagsHandler(myDictionary), "GET", "/api/tags", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"tag": "framework", "words": 1}, {"tag": "langage", "words": 2}]`, rr.Body.String())
+
+	rr = send(words, "DELETE", "/api/words/go/tags/langage", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = send(words, "GET", "/api/words/go", "")
+	var detail dictionary.WordDetail
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &detail))
+	assert.Empty(t, detail.Tags)
+
+	collections := apiServer.ApiCollectionsHandler(myDictionary)
+	rr = send(collections, "POST", "/api/collections", `{"name": "débuter", "description": "à lire d'abord", "words": ["python", "go"]}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr = send(collections, "POST", "/api/collections", `{"name": "débuter"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	rr = send(collections, "PUT", "/api/collections/débuter", `{"words": ["go", "gin"]}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = send(collections, "GET", "/api/collections/débuter", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"name": "débuter", "description": "", "words": ["go", "gin"]}`, rr.Body.String())
+	rr = send(collections, "DELETE", "/api/collections/débuter", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = send(collections, "GET", "/api/collections/débuter", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestMultilingualHandlers(t *testing.T) {
+	token := loginAndGetToken(t)
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+
+	send := func(handler http.HandlerFunc, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	add := apiServer.ApiAddWordHandler(myDictionary)
+	words := apiServer.ApiWordHandler(myDictionary)
+
+	assert.Equal(t, http.StatusCreated, send(add, "POST", "/api/words/add", `{"word": "chat", "definition": "petit félin"}`).Code)
+	assert.Equal(t, http.StatusCreated, send(add, "POST", "/api/words/add", `{"word": "chat", "lang": "en", "definition": "conversation"}`).Code)
+	assert.Equal(t, http.StatusCreated, send(add, "POST", "/api/words/add?lang=en", `{"word": "cat", "definition": "small feline"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(add, "POST", "/api/words/add?lang=english", `{"word": "dog", "definition": "canine"}`).Code)
+
+	rr := send(apiServer.ApiListWordsHandler(myDictionary), "GET", "/api/words/list?lang=en", "")
+	var listed []dictionary.Word
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+
+	// Sans paramètre lang, les autres routes suivent aussi Accept-Language.
+	assert.Equal(t, http.StatusCreated, send(add, "POST", "/api/words/add", `{"word": "dog", "definition": "canine"}`, "Accept-Language", "en").Code)
+	assert.Equal(t, http.StatusOK, send(words, "GET", "/api/words/dog/tags", "", "Accept-Language", "en-US").Code)
+	assert.Equal(t, http.StatusNotFound, send(words, "GET", "/api/words/dog/tags", "").Code)
+
+	// Sans paramètre lang, le détail suit Accept-Language puis la langue par défaut.
+	rr = send(words, "GET", "/api/words/chat", "", "Accept-Language", "en-GB,en;q=0.9,fr;q=0.8")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "en", rr.Header().Get("Content-Language"))
+	assert.Contains(t, rr.Body.String(), `"definition":"conversation"`)
+	rr = send(words, "GET", "/api/words/chat", "", "Accept-Language", "de")
+	assert.Equal(t, "fr", rr.Header().Get("Content-Language"))
+	rr = send(words, "GET", "/api/words/cat", "")
+	assert.Equal(t, "en", rr.Header().Get("Content-Language"))
+
+	rr = send(words, "POST", "/api/words/chat/translations", `{"word": "cat", "lang": "en"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr = send(words, "POST", "/api/words/chat/translations", `{"word": "cat", "lang": "en"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	rr = send(words, "GET", "/api/words/cat/translations?lang=en", "")
+	assert.JSONEq(t, `[{"word": "chat", "lang": "fr"}]`, rr.Body.String())
+	rr = send(words, "DELETE", "/api/words/chat/translations/en/cat", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = send(words, "DELETE", "/api/words/chat/translations/en/cat", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAuditHandlerLangFilter(t *testing.T) {
+	token := loginAndGetToken(t)
+	repo := &db.GormWordRepository{}
+	if err := repo.InitializeDB(":memory:"); err != nil {
+		t.Fatal(err)
+	}
+	defer repo.CloseDB()
+	ctx := context.Background()
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "chat", Lang: "fr", Definition: "petit félin"}))
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "chat", Lang: "en", Definition: "conversation"}))
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		apiServer.ApiAuditHandler(repo).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("/api/audit?word=chat&lang=EN")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var entries []interfaces.AuditEntry
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "en", entries[0].Lang)
+		assert.Equal(t, "conversation", entries[0].After)
+	}
+	assert.Equal(t, http.StatusBadRequest, send("/api/audit?lang=english").Code)
 }
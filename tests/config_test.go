@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"tp2/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "server:\n  port: \":7000\"\ndatabase:\n  path: fichier.db\nlog:\n  level: debug\nauth:\n  users:\n    alice: secret\nvalidation:\n  max_word_length: 40\n"
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0666))
+
+	t.Setenv("DB_PATH", "env.db")
+	t.Setenv("PORT", "7100")
+	t.Setenv("DICO_LOCALE", "en")
+
+	cfg, args, err := config.Load([]string{"-config", file, "-port", "7200", "api"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"api"}, args)
+	assert.Equal(t, ":7200", cfg.Server.Port, "l'option l'emporte sur l'environnement")
+	assert.Equal(t, "env.db", cfg.Database.Path, "l'environnement l'emporte sur le fichier")
+	assert.Equal(t, "debug", cfg.Log.Level, "le fichier l'emporte sur les valeurs par défaut")
+	assert.Equal(t, 40, cfg.Validation.MaxWordLength)
+	assert.Equal(t, 2, cfg.Validation.MinWordLength, "valeur par défaut conservée")
+
+	assert.Error(t, cfg.Validate("api"), "la clé secrète est requise en mode API")
+	cfg.Auth.SecretKey = "secret"
+	assert.NoError(t, cfg.Validate("api"))
+
+	assert.Equal(t, "en", cfg.UI.Locale)
+	cfg.UI.Locale = "de"
+	assert.Error(t, cfg.Validate("console"), "langue des messages non prise en charge")
+}
+
+// Les tables du fichier remplacent celles par défaut au lieu de s'y ajouter.
+func TestConfigFileReplacesMaps(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "auth:\n  users:\n    alice: secret\nvalidation:\n  required_fields:\n    \"*\": [word]\n"
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0666))
+
+	cfg, _, err := config.Load([]string{"-config", file})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"alice": "secret"}, cfg.Auth.Users)
+	assert.Empty(t, cfg.Auth.AdminUsers, "aucun administrateur par défaut")
+	assert.Equal(t, map[string][]string{"*": {"word"}}, cfg.Validation.RequiredFields)
+
+	// Sans table dans le fichier, celle par défaut est conservée.
+	assert.NoError(t, os.WriteFile(file, []byte("log:\n  level: warn\n"), 0666))
+	cfg, _, err = config.Load([]string{"-config", file})
+	assert.NoError(t, err)
+	assert.Equal(t, config.Default().Validation.RequiredFields, cfg.Validation.RequiredFields)
+	assert.Empty(t, cfg.Auth.Users, "aucun compte par défaut")
+
+	cfg.Auth.SecretKey = "secret"
+	assert.Error(t, cfg.Validate("api"), "un compte est requis en mode API")
+}
+
+func TestConfigValidateWebhookBackoff(t *testing.T) {
+	cfg := config.Default()
+	assert.NoError(t, cfg.Validate("console"))
+
+	cfg.Webhooks.MaxBackoff = 0
+	assert.Error(t, cfg.Validate("console"))
+	cfg.Webhooks.MaxBackoff = -time.Second
+	assert.Error(t, cfg.Validate("console"))
+	cfg.Webhooks.MaxBackoff = cfg.Webhooks.InitialBackoff / 2
+	assert.Error(t, cfg.Validate("console"), "max_backoff inférieur à initial_backoff")
+}
@@ -1,9 +1,16 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 	"tp2/db"
+	"tp2/interfaces"
+	"tp2/requestctx"
 )
 
 func TestCRUDOperations(t *testing.T) {
@@ -15,15 +22,16 @@ func TestCRUDOperations(t *testing.T) {
 		log.Fatal("Failed to initialize database:", err)
 	}
 	defer wordRepository.CloseDB()
+	ctx := context.Background()
 
 	// Test d'ajout
-	err = wordRepository.AddWordToDB("example", "This is an example definition.")
+	err = wordRepository.AddWordToDB(ctx, interfaces.Word{Word: "example", Lang: "fr", Definition: "This is an example definition."})
 	if err != nil {
 		log.Fatal("Failed to add word to database:", err)
 	}
 
 	// Test de récupération du mot ajouté
-	word, err := wordRepository.GetWordFromDB("example")
+	word, err := wordRepository.GetWordFromDB(ctx, "example", "fr")
 	if err != nil {
 		t.Errorf("Erreur lors de la récupération du mot ajouté : %v", err)
 	}
@@ -32,13 +40,13 @@ func TestCRUDOperations(t *testing.T) {
 	}
 
 	// Test de modification
-	err = wordRepository.UpdateWordInDB("example", "nouvelle_definition")
+	err = wordRepository.UpdateWordInDB(ctx, "example", "fr", "nouvelle_definition")
 	if err != nil {
 		t.Errorf("Erreur lors de la modification du mot : %v", err)
 	}
 
 	// Vérification de la modification
-	word, err = wordRepository.GetWordFromDB("example")
+	word, err = wordRepository.GetWordFromDB(ctx, "example", "fr")
 	if err != nil {
 		t.Errorf("Erreur lors de la récupération du mot modifié : %v", err)
 	}
@@ -47,14 +55,260 @@ func TestCRUDOperations(t *testing.T) {
 	}
 
 	// Test de suppression
-	err = wordRepository.DeleteWordFromDB("example")
+	err = wordRepository.DeleteWordFromDB(ctx, "example", "fr")
 	if err != nil {
 		t.Errorf("Erreur lors de la suppression du mot : %v", err)
 	}
 
 	// Vérification de la suppression
-	_, err = wordRepository.GetWordFromDB("example")
+	_, err = wordRepository.GetWordFromDB(ctx, "example", "fr")
 	if err == nil {
 		t.Errorf("Le mot supprimé est toujours présent dans la base de données.")
 	}
 }
+
+func TestAuditLog(t *testing.T) {
+	wordRepository := &db.GormWordRepository{}
+	if err := wordRepository.InitializeDB(":memory:"); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer wordRepository.CloseDB()
+
+	ctx := requestctx.With(context.Background(), &requestctx.Info{RequestID: "req-1", User: "nabil", ClientIP: "127.0.0.1"})
+
+	if err := wordRepository.AddWordToDB(ctx, interfaces.Word{Word: "audit", Lang: "fr", Definition: "première définition"}); err != nil {
+		t.Fatalf("Erreur lors de l'ajout : %v", err)
+	}
+	if err := wordRepository.UpdateWordInDB(ctx, "audit", "fr", "seconde définition"); err != nil {
+		t.Fatalf("Erreur lors de la modification : %v", err)
+	}
+	if err := wordRepository.DeleteWordFromDB(ctx, "audit", "fr"); err != nil {
+		t.Fatalf("Erreur lors de la suppression : %v", err)
+	}
+
+	entries, err := wordRepository.ListAuditEntries(context.Background(), interfaces.AuditFilter{Word: "audit"})
+	if err != nil {
+		t.Fatalf("Erreur lors de la lecture du journal d'audit : %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("3 entrées d'audit attendues, %d obtenues", len(entries))
+	}
+
+	// Les entrées sont renvoyées de la plus récente à la plus ancienne.
+	update := entries[1]
+	if update.Action != db.AuditActionUpdate || update.Before != "première définition" || update.After != "seconde définition" {
+		t.Errorf("Entrée de modification inattendue : %+v", update)
+	}
+	if update.Username != "nabil" || update.ClientIP != "127.0.0.1" || update.RequestID != "req-1" {
+		t.Errorf("Le contexte de la requête n'a pas été enregistré : %+v", update)
+	}
+	for _, entry := range entries {
+		if entry.Lang != "fr" {
+			t.Errorf("La langue de l'entrée n'a pas été enregistrée : %+v", entry)
+		}
+	}
+
+	// Le même mot dans une autre langue a ses propres entrées.
+	if err := wordRepository.AddWordToDB(ctx, interfaces.Word{Word: "audit", Lang: "EN", Definition: "official inspection"}); err != nil {
+		t.Fatalf("Erreur lors de l'ajout : %v", err)
+	}
+	entries, err = wordRepository.ListAuditEntries(context.Background(), interfaces.AuditFilter{Word: "audit", Lang: "en"})
+	if err != nil || len(entries) != 1 || entries[0].Action != db.AuditActionAdd || entries[0].Lang != "en" {
+		t.Errorf("Filtre par langue : %+v, %v", entries, err)
+	}
+
+	if err := wordRepository.DB.Where("1 = 1").Delete(&db.AuditRecord{}).Error; err == nil {
+		t.Errorf("La suppression d'entrées d'audit doit être refusée.")
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrations.db")
+	ctx := context.Background()
+
+	gormDB, err := db.OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migrator.Up(ctx, 0)
+	if err != nil || len(applied) != migrator.LatestVersion() {
+		t.Fatalf("toutes les migrations doivent être appliquées : %v, %v", applied, err)
+	}
+	if reverted, err := migrator.Down(ctx, migrator.LatestVersion()); err != nil || len(reverted) != migrator.LatestVersion() {
+		t.Fatalf("toutes les migrations doivent être annulées : %v, %v", reverted, err)
+	}
+	if gormDB.Migrator().HasTable("words") {
+		t.Errorf("la table words doit être supprimée par la migration down")
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Une base migrée par un binaire plus récent doit être refusée au démarrage.
+	if err := gormDB.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', CURRENT_TIMESTAMP)", migrator.LatestVersion()+1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sqlDB, err := gormDB.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	wordRepository := &db.GormWordRepository{}
+	if err := wordRepository.InitializeDB(path); !errors.Is(err, db.ErrSchemaAhead) {
+		t.Fatalf("ErrSchemaAhead attendue, obtenu %v", err)
+	}
+}
+
+func TestWordKeyMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.db")
+	ctx := context.Background()
+
+	gormDB, err := db.OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 6); err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range []string{"Élan", "élan", "Cafe\u0301"} {
+		if err := gormDB.Exec("INSERT INTO words (word, lang, definition) VALUES (?, 'fr', 'définition')", word).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Deux mots qui ne diffèrent que par la casse bloquent la migration, qui est annulée.
+	if _, err := migrator.Up(ctx, 0); !errors.Is(err, db.ErrWordKeyCollision) {
+		t.Fatalf("ErrWordKeyCollision attendue, obtenu %v", err)
+	}
+	if version, _ := migrator.CurrentVersion(ctx); version != 6 {
+		t.Errorf("la base doit rester en version 6, obtenu %d", version)
+	}
+
+	if err := gormDB.Exec("DELETE FROM words WHERE word = 'élan'").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if sqlDB, err := gormDB.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	wordRepository := &db.GormWordRepository{}
+	if err := wordRepository.InitializeDB(path); err != nil {
+		t.Fatal(err)
+	}
+	defer wordRepository.CloseDB()
+	if word, err := wordRepository.GetWordFromDB(ctx, "elan", "fr"); err != nil || word.Word != "Élan" {
+		t.Errorf("GetWordFromDB(elan) : %+v, %v", word, err)
+	}
+	if word, err := wordRepository.GetWordFromDB(ctx, "CAFÉ", "fr"); err != nil || word.Word != "Café" {
+		t.Errorf("le mot doit être normalisé en NFC : %+v, %v", word, err)
+	}
+	// La migration 8 calcule les clés phonétiques des mots existants.
+	if words, err := wordRepository.ListWordsBySound(ctx, "kafé", "fr"); err != nil || len(words) != 1 || words[0].Word != "Café" {
+		t.Errorf("ListWordsBySound(kafé) : %+v, %v", words, err)
+	}
+	// La migration 9 calcule leurs lettres.
+	if words, err := wordRepository.ListAnagrams(ctx, "éfac", "fr", 0); err != nil || len(words) != 1 || words[0].Word != "Café" {
+		t.Errorf("ListAnagrams(éfac) : %+v, %v", words, err)
+	}
+	// La migration 14 indexe leurs termes.
+	if hits, err := wordRepository.SearchWords(ctx, "cafés", "fr", "", 0); err != nil || len(hits) != 1 || hits[0].Word != "Café" {
+		t.Errorf("SearchWords(cafés) : %+v, %v", hits, err)
+	}
+	// La migration 15 compte leurs lettres.
+	if words, err := wordRepository.ListWordsContaining(ctx, "FÉ", "fr", 0); err != nil || len(words) != 1 || words[0].Word != "Café" {
+		t.Errorf("ListWordsContaining(FÉ) : %+v, %v", words, err)
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "live.db")
+	ctx := context.Background()
+
+	wordRepository := &db.GormWordRepository{}
+	if err := wordRepository.InitializeDB(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := wordRepository.AddWordToDB(ctx, interfaces.Word{Word: "sauvegarde", Lang: "fr", Definition: "copie de sécurité"}); err != nil {
+		t.Fatal(err)
+	}
+
+	backupDir := filepath.Join(dir, "backups")
+	backup := db.BackupFileName(backupDir, time.Now())
+	if err := wordRepository.Backup(ctx, backup); err != nil {
+		t.Fatalf("échec de la sauvegarde : %v", err)
+	}
+	if err := db.ValidateBackup(backup); err != nil {
+		t.Fatalf("la sauvegarde doit être valide : %v", err)
+	}
+
+	// Les modifications postérieures à la sauvegarde sont perdues à la
+	// restauration, même lorsqu'elles sont encore dans le journal WAL.
+	if err := wordRepository.DB.Exec("PRAGMA journal_mode=WAL").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := wordRepository.DeleteWordFromDB(ctx, "sauvegarde", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	wal, err := os.ReadFile(dbPath + "-wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wordRepository.CloseDB()
+	// Journal laissé par un arrêt brutal du serveur.
+	if err := os.WriteFile(dbPath+"-wal", wal, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := db.Restore(backup, dbPath)
+	if err != nil {
+		t.Fatalf("échec de la restauration : %v", err)
+	}
+	if _, err := os.Stat(previous); err != nil {
+		t.Errorf("l'ancienne base doit être conservée : %v", err)
+	}
+	if _, err := os.Stat(dbPath + "-wal"); !os.IsNotExist(err) {
+		t.Errorf("le journal WAL de l'ancienne base doit être supprimé : %v", err)
+	}
+
+	restored := &db.GormWordRepository{}
+	if err := restored.InitializeDB(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	defer restored.CloseDB()
+	if _, err := restored.GetWordFromDB(ctx, "sauvegarde", "fr"); err != nil {
+		t.Errorf("le mot sauvegardé doit être restauré : %v", err)
+	}
+
+	// Un fichier qui n'est pas une base du dictionnaire est refusé.
+	invalid := filepath.Join(dir, "invalide.db")
+	if err := os.WriteFile(invalid, []byte("pas une base"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Restore(invalid, dbPath); err == nil {
+		t.Errorf("une sauvegarde invalide doit être refusée")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := restored.Backup(ctx, db.BackupFileName(backupDir, time.Now().Add(time.Duration(i+1)*time.Second))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.PruneBackups(backupDir, 2); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := db.ListBackups(backupDir); len(backups) != 2 {
+		t.Errorf("2 sauvegardes doivent être conservées, obtenu %d", len(backups))
+	}
+}
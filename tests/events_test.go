@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"tp2/api_mode"
+	"tp2/db"
+	"tp2/dictionary"
+	"tp2/interfaces"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.MemoryWordRepository{}
+	bus := dictionary.NewEventBus(repo)
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "chat", Lang: "fr", Definition: "félin"}))
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "chien", Lang: "fr", Definition: "canidé"}))
+	assert.NoError(t, repo.DeleteWordFromDB(ctx, "chat", "fr"))
+	assert.NoError(t, bus.Notify(ctx))
+	revisions, err := repo.ListRevisions(ctx, 0, 0)
+	if !assert.NoError(t, err) || !assert.Len(t, revisions, 3) {
+		return
+	}
+	assert.Equal(t, revisions[2].ID, bus.LastID())
+
+	// Reprise après le premier événement, limitée aux ajouts.
+	sub, missed, err := bus.Subscribe(ctx, revisions[0].ID, dictionary.EventFilter{Types: []string{dictionary.EventWordAdded}})
+	assert.NoError(t, err)
+	defer sub.Close()
+	if assert.Len(t, missed, 1) {
+		assert.Equal(t, "chien", missed[0].Word)
+	}
+
+	assert.NoError(t, repo.DeleteWordFromDB(ctx, "chien", "fr"))
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "cheval", Lang: "fr", Definition: "équidé"}))
+	assert.NoError(t, bus.Notify(ctx))
+	event := <-sub.C
+	assert.Equal(t, "cheval", event.Word)
+	assert.Equal(t, bus.LastID(), event.ID)
+
+	// Un identifiant inconnu du dépôt oblige le client à recharger la liste.
+	_, _, err = bus.Subscribe(ctx, bus.LastID()+1, dictionary.EventFilter{})
+	assert.ErrorIs(t, err, dictionary.ErrEventsExpired)
+}
+
+// Les révisions étant enregistrées par le dépôt, un client peut reprendre le
+// flux après un redémarrage du serveur.
+func TestEventReplayAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dictionary.json")
+
+	repo := openRepository(t, db.DriverJSON, path)
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "chat", Lang: "fr", Definition: "félin"}))
+	lastID := dictionary.NewEventBus(repo).LastID()
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "chien", Lang: "fr", Definition: "canidé"}))
+	repo.CloseDB()
+
+	repo = openRepository(t, db.DriverJSON, path)
+	defer repo.CloseDB()
+	sub, missed, err := dictionary.NewEventBus(repo).Subscribe(ctx, lastID, dictionary.EventFilter{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer sub.Close()
+	if assert.Len(t, missed, 1) {
+		assert.Equal(t, "chien", missed[0].Word)
+		assert.Equal(t, dictionary.EventWordAdded, missed[0].Type)
+	}
+}
+
+func TestEventsStreams(t *testing.T) {
+	token := strings.TrimSpace(loginAndGetToken(t))
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/events", api_mode.Instrument("/api/events", apiServer.ApiEventsHandler(myDictionary)))
+	mux.HandleFunc("/api/events/ws", api_mode.Instrument("/api/events/ws", apiServer.ApiEventsWebSocketHandler(myDictionary)))
+	server := httptest.NewServer(api_mode.RequestLogger(mux))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/events?type=word.added", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", token)
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/events/ws?token=" + url.QueryEscape(token)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	assert.NoError(t, myDictionary.AddAsync(ctx, "flux", "fr", "suite d'événements"))
+
+	// SSE : on attend la ligne de données de l'événement.
+	reader := bufio.NewReader(resp.Body)
+	var data string
+	for !strings.HasPrefix(data, "data: ") {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		data = line
+	}
+	var event dictionary.Event
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &event))
+	assert.Equal(t, "flux", event.Word)
+	assert.Equal(t, dictionary.EventWordAdded, event.Type)
+
+	var wsEvent dictionary.Event
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.NoError(t, conn.ReadJSON(&wsEvent))
+	assert.Equal(t, event.ID, wsEvent.ID)
+
+	// Sans jeton, le flux est refusé.
+	unauthorized, err := http.Get(server.URL + "/api/events")
+	if assert.NoError(t, err) {
+		unauthorized.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, unauthorized.StatusCode)
+	}
+}
+
+// Les événements portent le mot tel qu'enregistré, quelle que soit la casse saisie.
+func TestEventsCarryStoredHeadword(t *testing.T) {
+	ctx := context.Background()
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	sub, _, err := myDictionary.Events().Subscribe(ctx, 0, dictionary.EventFilter{})
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	assert.NoError(t, myDictionary.AddAsync(ctx, "chat", "fr", "petit félin"))
+	_, err = myDictionary.ApplyBatch(ctx, []interfaces.BatchOperation{
+		{Op: interfaces.BatchDefine, Word: "CHAT", Lang: "FR", Definition: "félin domestique"},
+	}, true)
+	assert.NoError(t, err)
+	assert.NoError(t, myDictionary.RemoveAsync(ctx, "Chat", "fr"))
+	assert.ErrorIs(t, myDictionary.RemoveAsync(ctx, "chat", "fr"), interfaces.ErrWordNotFound)
+
+	for _, want := range []string{dictionary.EventWordAdded, dictionary.EventWordUpdated, dictionary.EventWordRemoved} {
+		select {
+		case event := <-sub.C:
+			assert.Equal(t, want, event.Type)
+			assert.Equal(t, "chat", event.Word)
+			assert.Equal(t, "fr", event.Lang)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("événement %s non reçu", want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"testing"
+	"tp2/db"
+	"tp2/graph"
+	"tp2/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelationGraph(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.MemoryWordRepository{}
+	for _, word := range []string{"chat", "félin", "animal", "chien", "matou", "isolé"} {
+		assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: word, Lang: "fr", Definition: "définition de " + word}))
+	}
+	for _, rel := range [][3]string{
+		{"chat", interfaces.RelationHypernym, "félin"},
+		{"félin", interfaces.RelationHypernym, "animal"},
+		{"chien", interfaces.RelationHypernym, "animal"},
+		{"matou", interfaces.RelationSynonym, "chat"},
+	} {
+		assert.NoError(t, repo.AddRelation(ctx, rel[0], "fr", rel[1], rel[2]))
+	}
+
+	g, err := graph.Load(ctx, repo, "fr")
+	assert.NoError(t, err)
+	assert.Len(t, g.Edges(), 4)
+
+	path, err := g.ShortestPath("matou", "chien")
+	assert.NoError(t, err)
+	assert.Equal(t, []graph.Step{
+		{Word: "matou"},
+		{Word: "chat", Relation: interfaces.RelationSynonym},
+		{Word: "félin", Relation: interfaces.RelationHypernym},
+		{Word: "animal", Relation: interfaces.RelationHypernym},
+		{Word: "chien", Relation: interfaces.RelationHyponym},
+	}, path)
+
+	// Les mots de départ sont retrouvés sans tenir compte de la casse ni des accents.
+	path, err = g.ShortestPath("MATOU", "felin")
+	assert.NoError(t, err)
+	assert.Equal(t, "félin", path[len(path)-1].Word)
+
+	_, err = g.ShortestPath("chat", "isolé")
+	assert.ErrorIs(t, err, graph.ErrNoPath)
+
+	neighbours, err := g.Neighbourhood("chat", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []graph.Neighbour{{Word: "félin", Distance: 1}, {Word: "matou", Distance: 1}, {Word: "animal", Distance: 2}}, neighbours)
+
+	assert.Equal(t, [][]string{{"animal", "chat", "chien", "félin", "matou"}, {"isolé"}}, g.Components())
+
+	// Le voisinage chargé seul a les mêmes mots et relations que dans le graphe complet.
+	sub, err := graph.LoadNeighbourhood(ctx, repo, "CHAT", "fr", 2)
+	assert.NoError(t, err)
+	subNeighbours, err := sub.Neighbourhood("chat", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, neighbours, subNeighbours)
+	assert.ElementsMatch(t, g.Subgraph([]string{"chat", "félin", "matou", "animal"}).Edges(), sub.Edges())
+	_, err = graph.LoadNeighbourhood(ctx, repo, "absent", "fr", 1)
+	assert.ErrorIs(t, err, interfaces.ErrWordNotFound)
+
+	var dot bytes.Buffer
+	assert.NoError(t, g.WriteDOT(&dot))
+	assert.Contains(t, dot.String(), `"chat" -> "félin" [label="hypernym"];`)
+	assert.Contains(t, dot.String(), `"chat" -> "matou" [label="synonym", dir=none];`)
+
+	var graphml bytes.Buffer
+	assert.NoError(t, g.WriteGraphML(&graphml))
+	var parsed struct {
+		Nodes []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+		} `xml:"graph>edge"`
+	}
+	assert.NoError(t, xml.Unmarshal(graphml.Bytes(), &parsed))
+	assert.Len(t, parsed.Nodes, 6)
+	assert.Len(t, parsed.Edges, 4)
+}
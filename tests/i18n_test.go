@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"tp2/api_mode"
+	"tp2/db"
+	"tp2/dictionary"
+	"tp2/i18n"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Les clés du catalogue utilisées par le code : "app.…", "main.…", "console.…", "api.…" et "cli.…".
+var messageKey = regexp.MustCompile(`"((?:app|main|console|api|cli)\.[a-z_.]+)"`)
+
+func TestMessageCatalogue(t *testing.T) {
+	assert.NoError(t, i18n.Check())
+
+	files, err := filepath.Glob("../api_mode/*.go")
+	assert.NoError(t, err)
+	consoleFiles, err := filepath.Glob("../console_mode/*.go")
+	assert.NoError(t, err)
+	lintFiles, err := filepath.Glob("../lint/*.go")
+	assert.NoError(t, err)
+	cliFiles, err := filepath.Glob("../cli_mode/*.go")
+	assert.NoError(t, err)
+	files = append(append(append(append(files, consoleFiles...), lintFiles...), cliFiles...), "../main.go")
+
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		for _, match := range messageKey.FindAllStringSubmatch(string(source), -1) {
+			assert.True(t, i18n.Has(match[1]), "%s : clé %q absente du catalogue", file, match[1])
+		}
+	}
+}
+
+func TestLocalizedMessages(t *testing.T) {
+	fr, en := i18n.For("fr"), i18n.For("en")
+	assert.Equal(t, "    langage (0 mot)", fr.N("console.tag_words", 0, "langage", 0))
+	assert.Equal(t, "    langage (2 mots)", fr.N("console.tag_words", 2, "langage", 2))
+	assert.Equal(t, "    langage (0 words)", en.N("console.tag_words", 0, "langage", 0))
+	assert.Equal(t, "    langage (1 word)", en.N("console.tag_words", 1, "langage", 1))
+	assert.Equal(t, "fr", i18n.For("de").Locale(), "langue non prise en charge")
+
+	err := i18n.Errorf("app.validation.word_length", 2, 30)
+	assert.Equal(t, "Validation error: The word must be between 2 and 30 characters long", en.T("api.validation_error", err))
+	assert.Equal(t, "La longueur du mot doit être entre 2 et 30 caractères", err.Error())
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	assert.Equal(t, "en", i18n.Resolve(""))
+	assert.Equal(t, "fr", i18n.Resolve("fr"), "la configuration l'emporte sur LANG")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	assert.Equal(t, "fr", i18n.Resolve(""))
+
+	// L'API répond dans la langue de l'en-tête Accept-Language.
+	token := loginAndGetToken(t)
+	handler := apiServer.ApiAddWordHandler(dictionary.New("dictionary.csv", &db.MemoryWordRepository{}))
+	send := func(acceptLanguage string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/api/words/add", bytes.NewBufferString(`{"word": "x", "definition": "trop court"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("en-US,en;q=0.9")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Validation error: The word must be between")
+
+	rr = send("de, fr;q=0.5")
+	assert.Contains(t, rr.Body.String(), "Erreur de validation : La longueur du mot doit être entre")
+
+	// Sans langue disponible dans Accept-Language, le serveur répond dans celle de ui.locale.
+	cfg := testConfig()
+	cfg.UI.Locale = "en"
+	english := api_mode.NewServer(cfg)
+	req, err := http.NewRequest("POST", "/api/words/add", bytes.NewBufferString(`{"word": "x", "definition": "trop court"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Accept-Language", "de")
+	rr = httptest.NewRecorder()
+	english.Localize(english.ApiAddWordHandler(dictionary.New("dictionary.csv", &db.MemoryWordRepository{}))).ServeHTTP(rr, req)
+	assert.Contains(t, rr.Body.String(), "Validation error: The word must be between")
+
+	req, err = http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept-Language", "en")
+	rr = httptest.NewRecorder()
+	api_mode.WelcomeHandler(rr, req)
+	assert.Contains(t, rr.Body.String(), "Welcome to the dico!")
+
+	// Les erreurs des dépôts ne sont pas renvoyées telles quelles.
+	repo := &db.GormWordRepository{}
+	assert.NoError(t, repo.InitializeDB(":memory:"))
+	defer repo.CloseDB()
+	req, err = http.NewRequest("DELETE", "/api/webhooks/42", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Accept-Language", "en")
+	rr = httptest.NewRecorder()
+	apiServer.ApiDeleteWebhookHandler(repo).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Webhook 42 not found.\n", rr.Body.String())
+	assert.Equal(t, "Applied: 0010_add_part_of_speech", en.T("cli.migrate.applied", 10, "add_part_of_speech"))
+}
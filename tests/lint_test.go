@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"tp2/db"
+	"tp2/i18n"
+	"tp2/interfaces"
+	"tp2/lint"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Les anomalies de dictionary.csv.
+var junkWords = []interfaces.Word{
+	{Word: "php", Lang: "fr", Definition: "langage"},
+	{Word: "javascript", Lang: "fr", Definition: "Langage "},
+	{Word: "", Lang: "fr", Definition: ""},
+	{Word: "testtt", Lang: "fr", Definition: "frameworkkkk"},
+	{Word: "test1", Lang: "fr", Definition: "frameworkkkk"},
+	{Word: "test", Lang: "fr", Definition: "une definition"},
+	{Word: "Test", Lang: "en", Definition: "a trial"},
+	{Word: "api", Lang: "en", Definition: "ok"},
+	{Word: "vide", Lang: "fr", Definition: " "},
+}
+
+func TestLintCheck(t *testing.T) {
+	opts := lint.Options{MinWordLength: 2, MinDefinitionLength: 5}
+	summary := lint.Check(junkWords, opts).Summary(i18n.For("fr"))
+
+	assert.Equal(t, len(junkWords), summary.Entries)
+	assert.Equal(t, map[string]int{
+		lint.CheckNearDuplicate:    1,
+		lint.CheckSharedDefinition: 2,
+		lint.CheckMissingSense:     1,
+		lint.CheckShortWord:        1,
+		lint.CheckShortDefinition:  1,
+		lint.CheckRepeatedLetters:  3,
+	}, summary.Counts)
+
+	// Le quasi-doublon ne mêle pas les langues et propose la forme sans défaut.
+	duplicate := summary.Issues[0]
+	assert.Equal(t, []string{"testtt", "test1", "test"}, duplicate.Words)
+	assert.Equal(t, "Garder 'test' et fusionner ou supprimer : testtt, test1", duplicate.Fix)
+
+	assert.Equal(t, []string{"php", "javascript"}, summary.Issues[1].Words, "définitions identiques aux espaces et à la casse près")
+	var fixes []string
+	for _, issue := range summary.Issues {
+		if issue.Check == lint.CheckRepeatedLetters {
+			fixes = append(fixes, issue.Fix)
+		}
+	}
+	assert.Equal(t, []string{"Corriger la définition en 'framework'", "Remplacer le mot par 'test'", "Corriger la définition en 'framework'"}, fixes)
+
+	assert.Empty(t, lint.Check([]interfaces.Word{{Word: "arc-en-ciel", Lang: "fr", Definition: "phénomène optique"}}, opts).Issues)
+
+	// Une faute de frappe rapproche deux mots longs, pas deux mots courts ni deux chiffres.
+	typos := lint.Check([]interfaces.Word{
+		{Word: "definition", Lang: "en", Definition: "meaning of a word"},
+		{Word: "definiton", Lang: "en", Definition: "meaning of words"},
+		{Word: "mp3", Lang: "en", Definition: "audio format"},
+		{Word: "mp4", Lang: "en", Definition: "video format"},
+		{Word: "chat", Lang: "fr", Definition: "petit félin"},
+		{Word: "char", Lang: "fr", Definition: "véhicule blindé"},
+	}, opts).Summary(i18n.For("fr"))
+	assert.Equal(t, 1, typos.Counts[lint.CheckNearDuplicate])
+	assert.Equal(t, []string{"definition", "definiton"}, typos.Issues[0].Words)
+}
+
+func TestLintOrphanRelations(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.GormWordRepository{}
+	if err := repo.InitializeDB(":memory:"); err != nil {
+		t.Fatal(err)
+	}
+	defer repo.CloseDB()
+
+	for _, word := range []string{"chat", "félin"} {
+		assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: word, Lang: "fr", Definition: "un animal"}))
+	}
+	assert.NoError(t, repo.AddRelation(ctx, "chat", "fr", interfaces.RelationHypernym, "félin"))
+
+	// Les clés étrangères ne sont pas activées : une suppression directe laisse la relation.
+	assert.NoError(t, repo.DB.Exec("DELETE FROM words WHERE word = ?", "félin").Error)
+	orphans, err := repo.OrphanRelations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.OrphanRelation{{Lang: "fr", Word: "chat", Type: interfaces.RelationHypernym}}, orphans)
+
+	report, err := lint.Run(ctx, repo, lint.Options{MinWordLength: 2, MinDefinitionLength: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Summary(i18n.For("fr")).Counts[lint.CheckOrphanRelation])
+
+	deleted, err := repo.DeleteOrphanRelations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	orphans, err = repo.OrphanRelations(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	// Un fichier JSON modifié à la main peut aussi désigner une entrée absente.
+	path := filepath.Join(t.TempDir(), "orphan.json")
+	data := `{
+		"words": {"chat": {"seq": 1, "word": "chat", "definition": "un animal"}},
+		"relations": {"chat\u0000synonym\u0000matou": {"seq": 2, "word": "chat", "type": "synonym", "target": "matou"}}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jsonRepo := openRepository(t, db.DriverJSON, path)
+	defer jsonRepo.CloseDB()
+	integrity := jsonRepo.(interfaces.IntegrityRepository)
+	orphans, err = integrity.OrphanRelations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.OrphanRelation{{Lang: "fr", Word: "chat", Type: interfaces.RelationSynonym}}, orphans)
+	deleted, err = integrity.DeleteOrphanRelations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestLintHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.MemoryWordRepository{}
+	for _, w := range junkWords[3:6] {
+		assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: w.Word, Lang: "fr", Definition: w.Definition}))
+	}
+
+	token := loginAndGetToken(t)
+	send := func(method, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Accept-Language", "en")
+		rr := httptest.NewRecorder()
+		apiServer.ApiLintHandler(repo, lint.Options{MinWordLength: 2, MinDefinitionLength: 5}).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("GET", "/api/admin/lint")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var summary lint.Summary
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &summary))
+	assert.Equal(t, 3, summary.Entries)
+	assert.Equal(t, 1, summary.Counts[lint.CheckNearDuplicate])
+	assert.Equal(t, "Keep 'test' and merge or delete: testtt, test1", summary.Issues[0].Fix)
+
+	rr = send("GET", "/api/admin/lint?format=text")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "[fr, near_duplicate] Near-duplicates: testtt, test1, test\n")
+
+	assert.Equal(t, http.StatusBadRequest, send("GET", "/api/admin/lint?format=xml").Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/admin/lint").Code)
+}
+
+func TestOpenGormRepositoryWithoutMigrating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lint.db")
+	_, err := db.OpenGormRepository(path, false)
+	assert.ErrorIs(t, err, db.ErrSchemaBehind, "une base non migrée est refusée")
+
+	migrated := &db.GormWordRepository{}
+	assert.NoError(t, migrated.InitializeDB(path))
+	assert.NoError(t, migrated.AddWordToDB(context.Background(), interfaces.Word{Word: "chat", Lang: "fr", Definition: "un animal"}))
+	migrated.CloseDB()
+
+	repo, err := db.OpenGormRepository(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.CloseDB()
+	report, err := lint.Run(context.Background(), repo, lint.Options{MinWordLength: 2, MinDefinitionLength: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Entries)
+	assert.Error(t, repo.AddWordToDB(context.Background(), interfaces.Word{Word: "chien", Lang: "fr", Definition: "un animal"}), "la base est en lecture seule")
+}
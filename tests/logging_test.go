@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"tp2/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "app_2000-01-01.log")
+	assert.NoError(t, os.WriteFile(oldFile, []byte("ancien\n"), 0666))
+
+	rf, err := logging.NewRotatingFile(dir, 64, 24*time.Hour, true)
+	assert.NoError(t, err)
+
+	line := `{"level":"WARN","msg":"test","path":"/api/words/add","user":"nabil"}` + "\n"
+	for i := 0; i < 3; i++ {
+		_, err := rf.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, rf.Close())
+
+	files, err := logging.ListFiles(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 3, "deux fichiers compressés et le fichier courant")
+	assert.NoFileExists(t, oldFile, "le fichier au-delà de la rétention doit être supprimé")
+	assert.True(t, strings.HasSuffix(files[0], ".1.log.gz"))
+	assert.True(t, strings.HasSuffix(files[2], time.Now().Format("2006-01-02")+".log"))
+
+	var out bytes.Buffer
+	assert.NoError(t, logging.Tail(dir, logging.Filter{Route: "/api/words", User: "nabil"}, 0, &out))
+	assert.Equal(t, 3, strings.Count(out.String(), "\n"))
+
+	out.Reset()
+	assert.NoError(t, logging.Tail(dir, logging.Filter{User: "autre"}, 0, &out))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"tp2/db"
+	"tp2/interfaces"
+	"tp2/repositorytest"
+)
+
+// Chaque pilote de stockage passe la même suite de tests.
+var repositoryDrivers = map[string]string{
+	db.DriverSQLite: "dictionary.sqlite",
+	db.DriverMemory: "",
+	db.DriverJSON:   "dictionary.json",
+	db.DriverBolt:   "dictionary.bolt",
+}
+
+func openRepository(t *testing.T, driver, path string) interfaces.WordRepository {
+	t.Helper()
+	repo, err := db.NewWordRepository(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InitializeDB(path); err != nil {
+		t.Fatalf("Erreur lors de l'initialisation du dépôt %s : %v", driver, err)
+	}
+	return repo
+}
+
+func TestRepositoryDrivers(t *testing.T) {
+	for driver, file := range repositoryDrivers {
+		driver, file := driver, file
+		t.Run(driver, func(t *testing.T) {
+			repositorytest.Run(t, func(t *testing.T) interfaces.WordRepository {
+				path := file
+				if path != "" {
+					path = filepath.Join(t.TempDir(), file)
+				}
+				return openRepository(t, driver, path)
+			})
+		})
+	}
+}
+
+func TestFileRepositoriesPersist(t *testing.T) {
+	for _, driver := range []string{db.DriverJSON, db.DriverBolt} {
+		t.Run(driver, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), repositoryDrivers[driver])
+			ctx := context.Background()
+
+			repo := openRepository(t, driver, path)
+			if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "symfony", Lang: "fr", Definition: "framework"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "angular", Lang: "fr", Definition: "framework"}); err != nil {
+				t.Fatal(err)
+			}
+			repo.CloseDB()
+
+			repo = openRepository(t, driver, path)
+			defer repo.CloseDB()
+			words, err := repo.ListWordsFromDB(ctx)
+			if err != nil || len(words) != 2 || words[0].Word != "symfony" {
+				t.Errorf("Mots rechargés inattendus : %+v, %v", words, err)
+			}
+			if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "react", Lang: "fr", Definition: "bibliothèque"}); err != nil {
+				t.Fatal(err)
+			}
+			words, _ = repo.ListWordsFromDB(ctx)
+			if words[len(words)-1].Word != "react" {
+				t.Errorf("L'ordre d'insertion doit être conservé après rechargement : %+v", words)
+			}
+
+			if err := repo.AddWordToDB(ctx, interfaces.Word{Word: "react", Lang: "en", Definition: "library"}); err != nil {
+				t.Fatal(err)
+			}
+			repo.CloseDB()
+			repo = openRepository(t, driver, path)
+			defer repo.CloseDB()
+			if word, err := repo.GetWordFromDB(ctx, "react", "en"); err != nil || word.Definition != "library" || word.Lang != "en" {
+				t.Errorf("Entrée anglaise rechargée inattendue : %+v, %v", word, err)
+			}
+		})
+	}
+}
+
+// Les fichiers JSON écrits avant les clés de recherche rangent les entrées sous
+// le mot tel quel : ils sont reclassés au chargement.
+func TestJSONRepositoryRekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	legacy := `{
+		"words": {
+			"Thé": {"seq": 1, "word": "Thé", "definition": "infusion"},
+			"Café": {"seq": 2, "word": "Café", "definition": "boisson"}
+		},
+		"relations": {
+			"Café\u0000see_also\u0000Thé": {"seq": 3, "word": "Café", "type": "see_also", "target": "Thé"}
+		},
+		"word_tags": {
+			"Thé\u0000boisson": {"seq": 4, "word": "Thé", "tag": "boisson"}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repo := openRepository(t, db.DriverJSON, path)
+	if word, err := repo.GetWordFromDB(ctx, "the", "fr"); err != nil || word.Word != "Thé" {
+		t.Errorf("GetWordFromDB(the) : %+v, %v", word, err)
+	}
+	if relations, err := repo.ListRelations(ctx, "cafe", "fr"); err != nil || len(relations) != 1 || relations[0].Target != "Thé" {
+		t.Errorf("ListRelations(cafe) : %v, %v", relations, err)
+	}
+	if tags, err := repo.WordTags(ctx, "THÉ", "fr"); err != nil || len(tags) != 1 {
+		t.Errorf("WordTags(THÉ) : %v, %v", tags, err)
+	}
+	repo.CloseDB()
+
+	// Deux entrées qui se confondent une fois la casse ignorée sont refusées.
+	collision := `{"words": {"Thé": {"seq": 1, "word": "Thé"}, "thé": {"seq": 2, "word": "thé"}}}`
+	if err := os.WriteFile(path, []byte(collision), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.NewWordRepository(db.DriverJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InitializeDB(path); !errors.Is(err, db.ErrWordKeyCollision) {
+		t.Errorf("ErrWordKeyCollision attendue, obtenu %v", err)
+	}
+}
@@ -0,0 +1,396 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tp2/analysis"
+	"tp2/db"
+	"tp2/dictionary"
+	"tp2/interfaces"
+	"tp2/search"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	for lang, stems := range map[string]map[string]string{
+		"fr": {
+			"langages": "langag", "langage": "langag", "frameworks": "framework",
+			"chevaux": "cheval", "nationale": "national", "nationaux": "national",
+			"continuellement": "continuel", "mangeaient": "mang", "manger": "mang",
+		},
+		"en": {
+			"running": "run", "languages": "languag", "language": "languag",
+			"generously": "generous", "connection": "connect", "libraries": "librari",
+		},
+	} {
+		for word, stem := range stems {
+			assert.Equal(t, stem, analysis.Stem(lang, word), "%s (%s)", word, lang)
+		}
+	}
+	assert.Equal(t, "frameworks", analysis.Stem("de", "frameworks"), "langue sans raciniseur")
+	// Le retrait du s final peut raccourcir le mot avant le début de RV.
+	assert.Equal(t, "ee", analysis.Stem("fr", "ees"))
+
+	// Les formes irrégulières passent par la table des lemmes.
+	assert.Equal(t, analysis.Term("fr", "œil"), analysis.Term("fr", "Yeux"))
+	assert.Equal(t, analysis.Term("en", "child"), analysis.Term("en", "children"))
+	assert.Equal(t, []string{"arc", "ciel"}, analysis.Terms("fr", "L'arc-en-ciel"))
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	repo := &db.MemoryWordRepository{}
+	d := dictionary.New("dictionary.csv", repo)
+	assert.NoError(t, d.AddAsync(ctx, "framework", "fr", "ensemble de bibliothèques"))
+	assert.NoError(t, d.AddAsync(ctx, "langage", "fr", "système de signes"))
+	assert.NoError(t, d.AddAsync(ctx, "go", "fr", "langage compilé"))
+	assert.NoError(t, d.AddAsync(ctx, "language", "en", "a system of signs"))
+
+	hits, err := d.Search(ctx, "frameworks", "fr", "", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "framework", hits[0].Word)
+	}
+
+	// Le mot passe avant les entrées qui le citent dans leur définition.
+	hits, err = d.Search(ctx, "Langages", "", "", 0)
+	assert.NoError(t, err)
+	var words []string
+	for _, hit := range hits {
+		words = append(words, hit.Word+"/"+hit.Lang)
+	}
+	assert.Equal(t, []string{"langage/fr", "go/fr"}, words)
+	hits, err = d.Search(ctx, "languages", "", "", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "en", hits[0].Lang, "chaque langue analyse la requête avec son raciniseur")
+	}
+
+	suggestions, err := d.Suggest(ctx, "langages", "fr", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.EntryRef{{Word: "langage", Lang: "fr"}}, suggestions)
+	suggestions, err = d.Suggest(ctx, "fram", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.EntryRef{{Word: "framework", Lang: "fr"}}, suggestions)
+
+	// La recherche voit aussi les modifications faites sans passer par le dictionnaire.
+	assert.NoError(t, repo.AddWordToDB(ctx, interfaces.Word{Word: "gopher", Lang: "fr", Definition: "mascotte du langage go"}))
+	hits, err = d.Search(ctx, "mascotte", "fr", "", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "gopher", hits[0].Word)
+	}
+
+	assert.NoError(t, d.EditAsync(ctx, "go", "fr", "langue des gophers"))
+	assert.NoError(t, d.RemoveAsync(ctx, "framework", "fr"))
+	assert.NoError(t, d.AddAsync(ctx, "frameworks", "fr", "pluriel conservé"))
+	hits, err = d.Search(ctx, "framework", "fr", "", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "frameworks", hits[0].Word)
+	}
+	suggestions, err = d.Suggest(ctx, "fram", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.EntryRef{{Word: "frameworks", Lang: "fr"}}, suggestions)
+	hits, err = d.Search(ctx, "compilé", "fr", "", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestSearchHandlers(t *testing.T) {
+	token := loginAndGetToken(t)
+	ctx := context.Background()
+	d := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	assert.NoError(t, d.AddAsync(ctx, "langage", "fr", "système de signes"))
+
+	send := func(handler http.HandlerFunc, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(apiServer.ApiSearchHandler(d), "/api/words/search?q=langages&lang=fr")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var results struct {
+		Query   string `json:"query"`
+		Results []struct {
+			Word string `json:"word"`
+		} `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+	assert.Equal(t, "langages", results.Query)
+	if assert.Len(t, results.Results, 1) {
+		assert.Equal(t, "langage", results.Results[0].Word)
+	}
+
+	rr = send(apiServer.ApiSuggestHandler(d), "/api/words/suggest?q=lang")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"query": "lang", "suggestions": [{"word": "langage", "lang": "fr"}]}`, rr.Body.String())
+
+	// tag restreint la recherche aux entrées qui portent l'étiquette.
+	assert.NoError(t, d.AddAsync(ctx, "go", "fr", "langage compilé"))
+	assert.NoError(t, d.TagWord(ctx, "go", "fr", "informatique"))
+	rr = send(apiServer.ApiSearchHandler(d), "/api/words/search?q=langage&tag=informatique")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+	if assert.Len(t, results.Results, 1) {
+		assert.Equal(t, "go", results.Results[0].Word)
+	}
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiSearchHandler(d), "/api/words/search?q=langage&tag=a%2Fb").Code)
+
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiSearchHandler(d), "/api/words/search").Code)
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiSearchHandler(d), "/api/words/search?q=a&limit=0").Code)
+
+	// Un mot introuvable est accompagné des autres formes du même lemme.
+	rr = send(apiServer.ApiWordHandler(d), "/api/words/langages")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	var notFound struct {
+		Error       string                `json:"error"`
+		Suggestions []interfaces.EntryRef `json:"suggestions"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &notFound))
+	assert.NotEmpty(t, notFound.Error)
+	assert.Equal(t, []interfaces.EntryRef{{Word: "langage", Lang: "fr"}}, notFound.Suggestions)
+}
+
+func TestPhoneticKeys(t *testing.T) {
+	assert.Equal(t, "R163", analysis.Soundex("Robert"))
+	assert.Equal(t, analysis.Soundex("Robert"), analysis.Soundex("Rupert"))
+	assert.Equal(t, "A261", analysis.Soundex("Ashcraft"), "h ne sépare pas deux consonnes de même chiffre")
+
+	for word, want := range map[string][2]string{
+		"Smith":   {"SM0", "XMT"},
+		"Schmidt": {"XMT", "SMT"},
+		"knight":  {"NT", ""},
+		"cough":   {"KF", ""},
+		"Xavier":  {"SF", "SFR"},
+	} {
+		primary, secondary := analysis.DoubleMetaphone(word)
+		assert.Equal(t, want, [2]string{primary, secondary}, word)
+	}
+
+	for _, pair := range [][2]string{{"photographie", "fotografie"}, {"maison", "mézon"}, {"bateau", "bato"}, {"pain", "pin"}} {
+		assert.Equal(t, analysis.Phonex(pair[0]), analysis.Phonex(pair[1]), pair[0])
+	}
+	assert.NotEqual(t, analysis.Phonex("maison"), analysis.Phonex("raison"))
+}
+
+func TestSoundsLikeHandler(t *testing.T) {
+	token := loginAndGetToken(t)
+	ctx := context.Background()
+	d := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	assert.NoError(t, d.AddAsync(ctx, "photographie", "fr", "image obtenue par la lumière"))
+	assert.NoError(t, d.AddAsync(ctx, "photon", "fr", "particule de lumière"))
+	assert.NoError(t, d.AddAsync(ctx, "night", "en", "the dark hours"))
+
+	send := func(handler http.HandlerFunc, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(apiServer.ApiSoundsLikeHandler(d), "/api/words/sounds-like?q=knite")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"query": "knite", "results": [{"word": "night", "lang": "en", "definition": "the dark hours"}]}`, rr.Body.String())
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiSoundsLikeHandler(d), "/api/words/sounds-like?lang=fr").Code)
+
+	// Le mot introuvable propose d'abord les mots qui commencent pareil, puis ceux qui se prononcent pareil.
+	rr = send(apiServer.ApiWordHandler(d), "/api/words/fotografie?lang=fr")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"suggestions":[{"word":"photographie","lang":"fr"}]`)
+	rr = send(apiServer.ApiWordHandler(d), "/api/words/photografie")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"suggestions":[{"word":"photographie","lang":"fr"}]`)
+	rr = send(apiServer.ApiWordHandler(d), "/api/words/phot")
+	assert.Contains(t, rr.Body.String(), `"suggestions":[{"word":"photon","lang":"fr"},{"word":"photographie","lang":"fr"}]`)
+}
+
+func TestWordGameHandlers(t *testing.T) {
+	token := loginAndGetToken(t)
+	ctx := context.Background()
+	d := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	for _, word := range []string{"niche", "chien", "symphonie", "sympa"} {
+		assert.NoError(t, d.AddAsync(ctx, word, "fr", "définition"))
+	}
+
+	send := func(handler http.HandlerFunc, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(apiServer.ApiMatchHandler(d), "/api/words/match?q=s%3Fmp*")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"query": "s?mp*", "results": [
+		{"word": "symphonie", "lang": "fr", "definition": "définition"},
+		{"word": "sympa", "lang": "fr", "definition": "définition"}]}`, rr.Body.String())
+
+	rr = send(apiServer.ApiAnagramsHandler(d), "/api/words/anagrams?q=CHINE&limit=1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"query": "CHINE", "results": [{"word": "niche", "lang": "fr", "definition": "définition"}]}`, rr.Body.String())
+
+	rr = send(apiServer.ApiContainingHandler(d), "/api/words/containing?q=yh&lang=fr")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"results":[{"word":"symphonie"`)
+	rr = send(apiServer.ApiContainingHandler(d), "/api/words/containing?q=zz")
+	assert.JSONEq(t, `{"query": "zz", "results": []}`, rr.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiMatchHandler(d), "/api/words/match?q=a%5Bb%5D").Code)
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiAnagramsHandler(d), "/api/words/anagrams?q=42").Code)
+	assert.Equal(t, http.StatusBadRequest, send(apiServer.ApiContainingHandler(d), "/api/words/containing").Code)
+}
+
+// wordGameWords renvoie n mots distincts, toujours les mêmes, tirés avec à
+// peu près la fréquence des lettres du français.
+func wordGameWords(n int) []string {
+	const letters = "eeeeeeeeeeeeeeeaaaaaaaaiiiiiiisssssssnnnnnnnrrrrrrrttttttoooooouuuuuulllllddddcccmmmppbfghjkqvwxyz"
+	random := rand.New(rand.NewSource(1))
+	seen := make(map[string]bool, n)
+	words := make([]string, 0, n)
+	for len(words) < n {
+		var b strings.Builder
+		for i := 3 + random.Intn(10); i > 0; i-- {
+			b.WriteByte(letters[random.Intn(len(letters))])
+		}
+		if word := b.String(); !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// loadSQLiteWords crée une base SQLite qui contient words. Ils sont insérés
+// avant la migration 7, comme dans une ancienne base, pour que les migrations
+// calculent leurs clés, leurs termes et leurs lettres par lots.
+func loadSQLiteWords(b *testing.B, words []string) interfaces.WordRepository {
+	path := filepath.Join(b.TempDir(), "words.db")
+	ctx := context.Background()
+
+	gormDB, err := db.OpenSQLite(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 6); err != nil {
+		b.Fatal(err)
+	}
+	const batch = 500
+	for start := 0; start < len(words); start += batch {
+		chunk := words[start:min(start+batch, len(words))]
+		args := make([]any, len(chunk))
+		for i, word := range chunk {
+			args[i] = word
+		}
+		values := strings.TrimSuffix(strings.Repeat("(?, 'fr', 'définition'), ", len(chunk)), ", ")
+		if err := gormDB.Exec("INSERT INTO words (word, lang, definition) VALUES "+values, args...).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		b.Fatal(err)
+	}
+	if sqlDB, err := gormDB.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	repo := &db.GormWordRepository{}
+	if err := repo.InitializeDB(path); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(repo.CloseDB)
+	return repo
+}
+
+// BenchmarkWordGames mesure les recherches pour les jeux de lettres sur une
+// liste de 100 000 mots, avec la limite par défaut de l'API.
+func BenchmarkWordGames(b *testing.B) {
+	words := wordGameWords(100000)
+	ctx := context.Background()
+
+	memory := &db.MemoryWordRepository{}
+	ops := make([]interfaces.BatchOperation, len(words))
+	for i, word := range words {
+		ops[i] = interfaces.BatchOperation{Op: interfaces.BatchAdd, Word: word, Lang: "fr", Definition: "définition"}
+	}
+	if _, err := memory.ApplyBatch(ctx, ops, true); err != nil {
+		b.Fatal(err)
+	}
+	repos := []struct {
+		driver string
+		repo   interfaces.WordRepository
+	}{
+		{db.DriverMemory, memory},
+		{db.DriverSQLite, loadSQLiteWords(b, words)},
+	}
+
+	for _, r := range repos {
+		for _, q := range []struct {
+			name    string
+			find    func(ctx context.Context, q, lang string, limit int) ([]interfaces.Word, error)
+			pattern string
+		}{
+			{"MatchWords", r.repo.MatchWords, "?a?e*"},
+			{"MatchWords", r.repo.MatchWords, "????????"},
+			{"MatchWords", r.repo.MatchWords, "*zq*"},
+			{"ListAnagrams", r.repo.ListAnagrams, words[0]},
+			{"ListWordsContaining", r.repo.ListWordsContaining, "aei"},
+			{"ListWordsContaining", r.repo.ListWordsContaining, "wz"},
+		} {
+			b.Run(r.driver+"/"+q.name+"/"+q.pattern, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := q.find(ctx, q.pattern, "fr", 20); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// Les suggestions par préfixe suivent les ajouts et suppressions de l'index.
+func TestIndexSuggestPrefix(t *testing.T) {
+	idx := search.New([]interfaces.Word{
+		{Word: "Chatte", Lang: "fr", Definition: "femelle du chat"},
+		{Word: "chat", Lang: "fr", Definition: "petit félin"},
+		{Word: "chat", Lang: "en", Definition: "conversation"},
+		{Word: "château", Lang: "fr", Definition: "demeure"},
+		{Word: "chien", Lang: "fr", Definition: "canidé"},
+		{Word: "chat", Lang: "fr", Definition: "félin domestique"}, // remplace la première entrée
+	})
+	headwords := func(refs []interfaces.EntryRef) []string {
+		var words []string
+		for _, ref := range refs {
+			words = append(words, ref.Word+"/"+ref.Lang)
+		}
+		return words
+	}
+
+	assert.Equal(t, []string{"chat/fr", "Chatte/fr", "château/fr"}, headwords(idx.Suggest("fr", "CHA", 0)))
+	assert.Equal(t, []string{"chat/en", "chat/fr", "Chatte/fr", "château/fr"}, headwords(idx.Suggest("", "cha", 0)))
+
+	idx.Delete("chat", "fr")
+	idx.Put(interfaces.Word{Word: "chaton", Lang: "fr", Definition: "petit du chat"})
+	assert.Equal(t, []string{"chaton/fr", "Chatte/fr", "château/fr"}, headwords(idx.Suggest("fr", "chat", 0)))
+	idx.Delete("chat", "en")
+	assert.Empty(t, idx.Suggest("en", "c", 0))
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tp2/config"
+	"tp2/db"
+	"tp2/dictionary"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testValidator(t *testing.T) *dictionary.Validator {
+	rules := config.Default().Validation
+	rules.Charsets = map[string][]string{"fr": {"Latin", "Zs", "-'"}}
+	rules.ForbiddenWords = map[string][]string{"*": {"test"}}
+	rules.RequiredFields = map[string][]string{"*": {"word", "definition"}, "interjection": {"word"}}
+	v, err := dictionary.NewValidator(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// fieldCodes renvoie le code de chaque défaut, préfixé par son champ.
+func fieldCodes(err error) []string {
+	var invalid *dictionary.ValidationError
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+	codes := make([]string, len(invalid.Fields))
+	for i, f := range invalid.Fields {
+		codes[i] = f.Field + ":" + f.Code
+	}
+	return codes
+}
+
+func TestValidator(t *testing.T) {
+	v := testValidator(t)
+
+	assert.NoError(t, v.Validate(dictionary.Entry{Word: "arc-en-ciel", Lang: "fr", Definition: "phénomène optique"}))
+	assert.NoError(t, v.Validate(dictionary.Entry{Word: "hélas", Lang: "fr", PartOfSpeech: "interjection"}), "définition facultative pour une interjection")
+	assert.NoError(t, v.Validate(dictionary.Entry{Word: "日本語", Lang: "ja", Definition: "langue japonaise"}), "pas de jeu de caractères en ja")
+
+	assert.Equal(t, []string{"word:required", "definition:required"}, fieldCodes(v.Validate(dictionary.Entry{Lang: "fr"})))
+	assert.Equal(t, []string{"word:length", "definition:length"}, fieldCodes(v.Validate(dictionary.Entry{Word: "x", Lang: "fr", Definition: "abc"})))
+	assert.Equal(t, []string{"word:charset"}, fieldCodes(v.Validate(dictionary.Entry{Word: "go2", Lang: "fr", Definition: "langage"})))
+	assert.Equal(t, []string{"word:forbidden"}, fieldCodes(v.Validate(dictionary.Entry{Word: "TÉST", Lang: "en", Definition: "essai raté"})))
+	assert.Equal(t, []string{"part_of_speech:unknown"}, fieldCodes(v.Validate(dictionary.Entry{Word: "vite", Lang: "fr", Definition: "rapidement", PartOfSpeech: "adverbe"})))
+
+	// Une nouvelle définition ne remet pas en cause le mot existant.
+	assert.NoError(t, v.ValidateDefinition(dictionary.Entry{Word: "test", Lang: "fr", Definition: "essai"}))
+
+	err := v.Validate(dictionary.Entry{Word: "x", Lang: "fr"})
+	assert.Equal(t, "La longueur du mot doit être entre 2 et 30 caractères ; La définition est obligatoire", err.Error())
+
+	rules := config.Default().Validation
+	rules.Charsets = map[string][]string{"fr": {"Latn"}}
+	_, err = dictionary.NewValidator(rules)
+	assert.ErrorContains(t, err, "Latn")
+	rules = config.Default().Validation
+	rules.RequiredFields = map[string][]string{"nom": {"genre"}}
+	_, err = dictionary.NewValidator(rules)
+	assert.ErrorContains(t, err, "genre")
+}
+
+func TestValidationEntryPoints(t *testing.T) {
+	ctx := context.Background()
+	myDictionary := dictionary.New("dictionary.csv", &db.MemoryWordRepository{})
+	myDictionary.SetValidator(testValidator(t))
+
+	// La console passe par AddAsync : un mot vide est refusé comme dans l'API.
+	assert.Equal(t, []string{"word:required", "definition:required"}, fieldCodes(myDictionary.AddAsync(ctx, "", "fr", "")))
+	words, err := myDictionary.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, words)
+
+	token := loginAndGetToken(t)
+	send := func(handler http.HandlerFunc, method, url, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Accept-Language", "en")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(apiServer.ApiAddWordHandler(myDictionary), "POST", "/api/words/add", `{"word": "go2", "lang": "fr", "definition": "abc"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var report struct {
+		Error  string
+		Fields []struct{ Field, Code, Message string }
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, "Validation error: The word contains the character \"2\", which is not allowed in fr ; The definition must be between 5 and 255 characters long", report.Error)
+	assert.Len(t, report.Fields, 2)
+	assert.Equal(t, "word", report.Fields[0].Field)
+	assert.Equal(t, "charset", report.Fields[0].Code)
+
+	rr = send(apiServer.ApiAddWordHandler(myDictionary), "POST", "/api/words/add", `{"word": "hélas", "part_of_speech": "interjection"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	rr = send(apiServer.ApiDefineWordHandler(myDictionary), "PUT", "/api/words/define/hélas", `""`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"required"`)
+
+	rr = send(apiServer.ApiBatchHandler(myDictionary), "POST", "/api/words/batch?lang=fr", `{"operations": [
+		{"op": "add", "word": "test", "definition": "mot interdit"},
+		{"op": "add", "word": "essai", "definition": "tentative"}]}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var batch struct {
+		Results []struct {
+			Status string
+			Fields []struct{ Field, Code string }
+		}
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &batch))
+	assert.Equal(t, "error", batch.Results[0].Status)
+	assert.Equal(t, "forbidden", batch.Results[0].Fields[0].Code)
+	assert.Equal(t, "ok", batch.Results[1].Status)
+
+	_, err = myDictionary.Detail(ctx, "essai", "fr")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+	"tp2/db"
+	"tp2/dictionary"
+	"tp2/interfaces"
+	"tp2/webhooks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	wordRepository := &db.GormWordRepository{}
+	if err := wordRepository.InitializeDB(filepath.Join(t.TempDir(), "webhooks.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer wordRepository.CloseDB()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan dictionary.Event, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhooks.HeaderSignature) != webhooks.Sign("secret", r.Header.Get(webhooks.HeaderTimestamp), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event dictionary.Event
+		json.Unmarshal(body, &event)
+		received <- event
+	}))
+	defer receiver.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	ok, err := wordRepository.CreateWebhook(ctx, interfaces.Webhook{URL: receiver.URL, Secret: "secret", EventTypes: []string{dictionary.EventWordAdded}})
+	assert.NoError(t, err)
+	dead, err := wordRepository.CreateWebhook(ctx, interfaces.Webhook{URL: failing.URL, Secret: "autre"})
+	assert.NoError(t, err)
+
+	myDictionary := dictionary.New("dictionary.csv", wordRepository)
+	dispatcher := webhooks.NewDispatcher(wordRepository, webhooks.Options{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		Timeout:        time.Second,
+		Concurrency:    2,
+		PollInterval:   20 * time.Millisecond,
+	})
+	dispatcher.Start(ctx, myDictionary.Events())
+
+	assert.NoError(t, myDictionary.AddAsync(ctx, "crochet", "fr", "point d'accroche"))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "crochet", event.Word)
+		assert.Equal(t, dictionary.EventWordAdded, event.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("le webhook n'a pas été livré")
+	}
+
+	// Après 3 échecs, la livraison est abandonnée et apparaît dans les lettres mortes.
+	assert.Eventually(t, func() bool {
+		letters, err := wordRepository.ListDeliveries(ctx, interfaces.DeliveryFilter{WebhookID: dead.ID, Status: interfaces.DeliveryDead})
+		return err == nil && len(letters) == 1 && letters[0].Attempt == 3
+	}, 5*time.Second, 20*time.Millisecond)
+
+	attempts, err := wordRepository.ListDeliveries(ctx, interfaces.DeliveryFilter{WebhookID: dead.ID})
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 3)
+	assert.Equal(t, http.StatusInternalServerError, attempts[0].StatusCode)
+
+	assert.Eventually(t, func() bool {
+		delivered, err := wordRepository.ListDeliveries(ctx, interfaces.DeliveryFilter{WebhookID: ok.ID, Status: interfaces.DeliverySucceeded})
+		return err == nil && len(delivered) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+// Les livraisons et leurs tentatives sont conservées en base : un redémarrage
+// reprend la livraison là où elle s'était arrêtée, avec le même X-Dico-Delivery.
+// La signature couvre l'horodatage : un envoi rejoué avec un autre
+// X-Dico-Timestamp ne la vérifie plus.
+func TestWebhookSignatureCoversTimestamp(t *testing.T) {
+	body := []byte(`{"type":"word.added"}`)
+	signature := webhooks.Sign("secret", "1700000000", body)
+
+	assert.Equal(t, signature, webhooks.Sign("secret", "1700000000", body))
+	assert.NotEqual(t, signature, webhooks.Sign("secret", "1700000300", body))
+	assert.NotEqual(t, signature, webhooks.Sign("autre", "1700000000", body))
+	assert.NotEqual(t, signature, webhooks.Sign("secret", "1700000000", []byte(`{"type":"word.removed"}`)))
+}
+
+func TestWebhookOutboxSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.db")
+	open := func() *db.GormWordRepository {
+		repo := &db.GormWordRepository{}
+		if err := repo.InitializeDB(path); err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	}
+	options := webhooks.Options{
+		MaxAttempts:    3,
+		InitialBackoff: 300 * time.Millisecond,
+		Timeout:        time.Second,
+		PollInterval:   20 * time.Millisecond,
+	}
+
+	deliveryIDs := make(chan string, 2)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveryIDs <- r.Header.Get(webhooks.HeaderDelivery)
+		if len(deliveryIDs) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	repo := open()
+	webhook, err := repo.CreateWebhook(context.Background(), interfaces.Webhook{URL: receiver.URL, Secret: "secret"})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	myDictionary := dictionary.New("dictionary.csv", repo)
+	webhooks.NewDispatcher(repo, options).Start(ctx, myDictionary.Events())
+	assert.NoError(t, myDictionary.AddAsync(ctx, "crochet", "fr", "point d'accroche"))
+	assert.Eventually(t, func() bool {
+		failed, err := repo.ListDeliveries(context.Background(), interfaces.DeliveryFilter{WebhookID: webhook.ID, Status: interfaces.DeliveryFailed})
+		return err == nil && len(failed) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	repo.CloseDB()
+
+	repo = open()
+	defer repo.CloseDB()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	webhooks.NewDispatcher(repo, options).Start(ctx, dictionary.New("dictionary.csv", repo).Events())
+	assert.Eventually(t, func() bool {
+		delivered, err := repo.ListDeliveries(ctx, interfaces.DeliveryFilter{WebhookID: webhook.ID, Status: interfaces.DeliverySucceeded})
+		return err == nil && len(delivered) == 1 && delivered[0].Attempt == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	revisions, err := repo.ListRevisions(ctx, 0, 0)
+	if assert.NoError(t, err) && assert.Len(t, revisions, 1) {
+		want := strconv.FormatUint(revisions[0].ID, 10)
+		assert.Equal(t, want, <-deliveryIDs)
+		assert.Equal(t, want, <-deliveryIDs)
+	}
+	pending, err := repo.ClaimDeliveries(ctx, time.Now().Add(time.Hour), time.Minute, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, pending, "une livraison réussie doit quitter la boîte d'envoi")
+}
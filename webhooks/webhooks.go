@@ -0,0 +1,238 @@
+// Package webhooks livre les modifications du dictionnaire aux outils abonnés.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"tp2/dictionary"
+	"tp2/interfaces"
+)
+
+// En-têtes ajoutés à chaque livraison.
+const (
+	HeaderSignature = "X-Dico-Signature"
+	HeaderEvent     = "X-Dico-Event"
+	HeaderDelivery  = "X-Dico-Delivery"
+	HeaderTimestamp = "X-Dico-Timestamp"
+)
+
+// Options règle les tentatives de livraison.
+type Options struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	Concurrency    int
+	PollInterval   time.Duration // Délai entre deux relevés de la boîte d'envoi, 1s par défaut
+}
+
+// Dispatcher vide la boîte d'envoi des webhooks, où chaque événement est
+// enregistré avec la modification qui le produit, et livre les événements avec
+// des tentatives espacées exponentiellement. L'état des tentatives est conservé
+// en base : les livraisons reprennent après un redémarrage.
+type Dispatcher struct {
+	repo   interfaces.WebhookRepository
+	opts   Options
+	client *http.Client
+}
+
+func NewDispatcher(repo interfaces.WebhookRepository, opts Options) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	return &Dispatcher{
+		repo:   repo,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+	}
+}
+
+// Sign renvoie la signature HMAC-SHA256 de timestamp + "." + corps, au format
+// "sha256=<hex>". Le destinataire la recalcule avec le secret du webhook et
+// l'en-tête X-Dico-Timestamp pour authentifier l'envoi ; il doit aussi refuser
+// un horodatage trop ancien, sans quoi un envoi capturé peut être rejoué.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Start vide la boîte d'envoi en arrière-plan jusqu'à l'annulation de ctx.
+// Chaque événement publié sur bus la fait relever sans attendre PollInterval.
+func (d *Dispatcher) Start(ctx context.Context, bus *dictionary.EventBus) {
+	go d.run(ctx, bus)
+}
+
+func (d *Dispatcher) run(ctx context.Context, bus *dictionary.EventBus) {
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+
+	var sub *dictionary.Subscription
+	defer func() {
+		if sub != nil {
+			sub.Close()
+		}
+	}()
+	for {
+		if sub == nil {
+			// Sans abonnement, seul le relevé périodique livre les événements.
+			sub, _, _ = bus.Subscribe(ctx, 0, dictionary.EventFilter{})
+		}
+		var wake <-chan dictionary.Event
+		if sub != nil {
+			wake = sub.C
+		}
+
+		d.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case _, ok := <-wake:
+			if !ok {
+				// Abonnement coupé par retard : on se réabonne au tour suivant.
+				sub = nil
+			}
+		}
+	}
+}
+
+// drain livre les événements dus de la boîte d'envoi, Concurrency à la fois,
+// jusqu'à ce qu'il n'en reste plus.
+func (d *Dispatcher) drain(ctx context.Context) {
+	// Une livraison réservée et non journalisée, après un arrêt du serveur par
+	// exemple, est reprise à l'expiration de la réservation.
+	lease := 2 * d.opts.Timeout
+	if lease <= 0 {
+		lease = time.Minute
+	}
+	for ctx.Err() == nil {
+		pending, err := d.repo.ClaimDeliveries(ctx, time.Now(), lease, d.opts.Concurrency)
+		if err != nil {
+			slog.ErrorContext(ctx, "lecture de la boîte d'envoi des webhooks impossible", "error", err)
+			return
+		}
+		if len(pending) == 0 {
+			return
+		}
+		webhooks, err := d.repo.ListWebhooks(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "lecture des webhooks impossible", "error", err)
+			return
+		}
+		byID := make(map[uint]interfaces.Webhook, len(webhooks))
+		for _, webhook := range webhooks {
+			byID[webhook.ID] = webhook
+		}
+
+		var wg sync.WaitGroup
+		for _, p := range pending {
+			webhook, ok := byID[p.WebhookID]
+			if !ok {
+				// Webhook supprimé depuis la réservation : ses livraisons sont parties avec lui.
+				continue
+			}
+			wg.Add(1)
+			go func(p interfaces.PendingDelivery) {
+				defer wg.Done()
+				d.deliver(ctx, webhook, p)
+			}(p)
+		}
+		wg.Wait()
+	}
+}
+
+// deliver fait une tentative de livraison et la journalise. En cas d'échec,
+// la livraison est replanifiée, ou abandonnée (lettre morte) à la dernière tentative.
+func (d *Dispatcher) deliver(ctx context.Context, webhook interfaces.Webhook, pending interfaces.PendingDelivery) {
+	attempt := pending.Attempts + 1
+	start := time.Now()
+	statusCode, err := d.send(ctx, webhook, pending)
+	if ctx.Err() != nil {
+		// Arrêt en cours : la tentative sera refaite au prochain démarrage.
+		return
+	}
+
+	delivery := interfaces.WebhookDelivery{
+		WebhookID:  webhook.ID,
+		EventID:    pending.EventID,
+		EventType:  pending.EventType,
+		Payload:    pending.Payload,
+		Attempt:    attempt,
+		Status:     interfaces.DeliverySucceeded,
+		StatusCode: statusCode,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	var retryAt time.Time
+	if err != nil {
+		delivery.Status = interfaces.DeliveryFailed
+		delivery.Error = err.Error()
+		if attempt >= d.opts.MaxAttempts {
+			delivery.Status = interfaces.DeliveryDead
+			slog.WarnContext(ctx, "livraison de webhook abandonnée", "webhook_id", webhook.ID, "event_id", pending.EventID, "attempts", attempt, "error", err)
+		} else {
+			retryAt = time.Now().Add(d.backoff(attempt))
+		}
+	}
+	if recordErr := d.repo.RecordDelivery(context.WithoutCancel(ctx), pending, delivery, retryAt); recordErr != nil {
+		slog.ErrorContext(ctx, "journalisation de la livraison impossible", "webhook_id", webhook.ID, "error", recordErr)
+	}
+}
+
+// backoff renvoie le délai avant la tentative qui suit la tentative attempt :
+// InitialBackoff, doublé à chaque échec et plafonné à MaxBackoff.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.opts.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if d.opts.MaxBackoff > 0 && backoff > d.opts.MaxBackoff {
+			return d.opts.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// send envoie l'événement. X-Dico-Delivery porte l'identifiant de l'événement,
+// le même à chaque tentative et après un redémarrage.
+func (d *Dispatcher) send(ctx context.Context, webhook interfaces.Webhook, pending interfaces.PendingDelivery) (int, error) {
+	payload := []byte(pending.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, pending.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(pending.EventID, 10))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("réponse %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}